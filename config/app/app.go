@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/IBM/ibmcloud-cos-cli/config/commands"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/functions"
 	"github.com/IBM/ibmcloud-cos-cli/version"
 	"github.com/urfave/cli"
 )
@@ -74,6 +76,8 @@ func NewApp(name string) *cli.App {
 		commands.CommandObjectDelete,
 		commands.CommandObjectsDelete,
 		commands.CommandObjectCopy,
+		commands.CommandObjectMove,
+		commands.CommandObjectsCopy,
 		commands.CommandObjects,
 		commands.CommandObjectTaggingDelete,
 		commands.CommandObjectTaggingGet,
@@ -84,6 +88,7 @@ func NewApp(name string) *cli.App {
 		commands.CommandMPUComplete,
 		commands.CommandMPUCreate,
 		commands.CommandMPUs,
+		commands.CommandMPUsCleanup,
 		// Parts
 		commands.CommandPartUpload,
 		commands.CommandPartUploadCopy,
@@ -96,6 +101,9 @@ func NewApp(name string) *cli.App {
 		commands.CommandConfig,
 		commands.CommandDownload,
 		commands.CommandUpload,
+		commands.CommandSync,
+		commands.CommandBatch,
+		commands.CommandBench,
 		commands.CommandAsperaDownload,
 		commands.CommandAsperaUpload,
 		commands.CommandEndpoints,
@@ -129,6 +137,9 @@ func NewApp(name string) *cli.App {
 		commands.CommandListParts,
 	}
 
+	// Adds the global flags to the commands sending requests
+	setGlobalFlags(app.Commands)
+
 	// Runs to set Usage Text for the flags of all commands
 	setUsageText(app.Commands)
 
@@ -136,6 +147,28 @@ func NewApp(name string) *cli.App {
 	return app
 }
 
+// Add the global flags to the commands sending requests to COS, the ones with a region,
+// the configuration commands are left out
+func setGlobalFlags(cmds cli.Commands) {
+	for idx := range cmds {
+		if cmds[idx].Name == commands.Config {
+			continue
+		}
+		for _, flag := range cmds[idx].Flags {
+			if flag == cli.Flag(flags.FlagRegion) {
+				cmds[idx].Flags = append(append([]cli.Flag{}, cmds[idx].Flags...), flags.GlobalFlags...)
+				cmds[idx].Before = functions.ApplyRetryFlags
+				break
+			}
+		}
+		// the subcommands are shared by every application
+		if len(cmds[idx].Subcommands) > 0 {
+			cmds[idx].Subcommands = append(cli.Commands{}, cmds[idx].Subcommands...)
+			setGlobalFlags(cmds[idx].Subcommands)
+		}
+	}
+}
+
 // Set the usage text from the flags for all commands
 func setUsageText(commands cli.Commands) {
 	// Iterate through commands and establish usage for each
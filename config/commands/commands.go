@@ -9,13 +9,19 @@ import (
 )
 
 var subcommands = map[string]string{
-	"auth":         T("Switch between HMAC and IAM authentication"),
-	"crn":          T("Store Service Instance ID / CRN in the config"),
-	"ddl":          T("Store Default Download Location in the config"),
-	"endpoint-url": T("Set custom Service Endpoint for all operations"),
-	"hmac":         T("Store HMAC credentials in the config"),
-	"region":       T("Store Default Region in the config"),
-	"url-style":    T("Switch between VHost and Path URL style"),
+	"auth":               T("Switch between HMAC and IAM authentication"),
+	"content-types":      T("Store the Content-Type of file extensions in the config, such as .md=text/markdown,.avro=application/avro"),
+	"crn":                T("Store Service Instance ID / CRN in the config"),
+	"ddl":                T("Store Default Download Location in the config"),
+	"endpoint-url":       T("Set custom Service Endpoint for all operations"),
+	"hmac":               T("Store HMAC credentials in the config"),
+	"max-bandwidth":      T("Store the default maximum bandwidth of transfers in the config"),
+	"max-retries":        T("Store the maximum number of retries of a failed request in the config"),
+	"region":             T("Store Default Region in the config"),
+	"retry-base-delay":   T("Store the base delay of the exponential backoff between retries in the config, such as 100ms"),
+	"retry-max-delay":    T("Store the maximum delay between retries in the config, such as 20s"),
+	"retry-status-codes": T("Store the HTTP status codes retried in addition to throttling and server errors in the config, such as 500,503"),
+	"url-style":          T("Switch between VHost and Path URL style"),
 }
 
 var (
@@ -48,6 +54,8 @@ var (
 			flags.FlagBucket,
 			flags.FlagRegion,
 			flags.FlagForce,
+			flags.FlagEmptyBucket,
+			flags.FlagConfirmBucket,
 			flags.FlagOutput,
 			flags.FlagJSON,
 		},
@@ -402,6 +410,7 @@ var (
 			flags.FlagBucket,
 			flags.FlagKey,
 			flags.FlagForce,
+			flags.FlagResumeDownload,
 			flags.FlagIfMatch,
 			flags.FlagIfModifiedSince,
 			flags.FlagIfNoneMatch,
@@ -413,6 +422,11 @@ var (
 			flags.FlagResponseContentLanguage,
 			flags.FlagResponseContentType,
 			flags.FlagResponseExpires,
+			flags.FlagMaxBandwidth,
+			flags.FlagDecompress,
+			flags.FlagDecryptionKeyFile,
+			flags.FlagSSECustomerAlgorithm,
+			flags.FlagSSECustomerKey,
 			flags.FlagRegion,
 			flags.FlagVersionId,
 			flags.FlagOutput,
@@ -436,6 +450,8 @@ var (
 			flags.FlagIfNoneMatch,
 			flags.FlagIfUnmodifiedSince,
 			flags.FlagRange,
+			flags.FlagSSECustomerAlgorithm,
+			flags.FlagSSECustomerKey,
 			flags.FlagRegion,
 			flags.FlagVersionId,
 			flags.FlagOutput,
@@ -464,6 +480,13 @@ var (
 			flags.FlagMetadata,
 			flags.FlagTagging,
 			flags.FlagWebsiteRedirectLocation,
+			flags.FlagMaxBandwidth,
+			flags.FlagSkipExisting,
+			flags.FlagIfChanged,
+			flags.FlagCompress,
+			flags.FlagEncryptionKeyFile,
+			flags.FlagSSECustomerAlgorithm,
+			flags.FlagSSECustomerKey,
 			flags.FlagRegion,
 			flags.FlagOutput,
 			flags.FlagJSON,
@@ -499,6 +522,14 @@ var (
 		Flags: []cli.Flag{
 			flags.FlagBucket,
 			flags.FlagDelete,
+			flags.FlagDeleteKey,
+			flags.FlagDeletePrefix,
+			flags.FlagRecursiveDelete,
+			flags.FlagInclude,
+			flags.FlagExclude,
+			flags.FlagDeleteJobs,
+			flags.FlagDryRun,
+			flags.FlagForce,
 			flags.FlagBypassGovernanceRetention,
 			flags.FlagRegion,
 			flags.FlagOutput,
@@ -531,6 +562,13 @@ var (
 			flags.FlagTagging,
 			flags.FlagTaggingDirective,
 			flags.FlagWebsiteRedirectLocation,
+			flags.FlagMultipartThreshold,
+			flags.FlagCopyPartSize,
+			flags.FlagCopyConcurrency,
+			flags.FlagCopySourceSSECustomerAlgorithm,
+			flags.FlagCopySourceSSECustomerKey,
+			flags.FlagSSECustomerAlgorithm,
+			flags.FlagSSECustomerKey,
 			flags.FlagRegion,
 			flags.FlagOutput,
 			flags.FlagJSON,
@@ -538,6 +576,64 @@ var (
 		Action: functions.ObjectCopy,
 	}
 
+	// CommandObjectMove - Move an object within a bucket or to another bucket
+	// command:
+	//	 ibmcloud cos object-move
+	CommandObjectMove = cli.Command{
+		Name:        ObjectMove,
+		Description: T("Move or rename an object, within a bucket or to another bucket"),
+		Flags: []cli.Flag{
+			flags.FlagSourceBucket,
+			flags.FlagSourceKey,
+			flags.FlagVersionId,
+			flags.FlagBucket,
+			flags.FlagKey,
+			flags.FlagMultipartThreshold,
+			flags.FlagCopyPartSize,
+			flags.FlagCopyConcurrency,
+			flags.FlagCopySourceSSECustomerAlgorithm,
+			flags.FlagCopySourceSSECustomerKey,
+			flags.FlagSSECustomerAlgorithm,
+			flags.FlagSSECustomerKey,
+			flags.FlagRegion,
+			flags.FlagOutput,
+			flags.FlagJSON,
+		},
+		Action: functions.ObjectMove,
+	}
+
+	// CommandObjectsCopy - Copy every object under a prefix to another bucket or prefix
+	// command:
+	//	 ibmcloud cos objects-copy
+	CommandObjectsCopy = cli.Command{
+		Name:        ObjectsCopy,
+		Description: T("Copy the objects under a prefix to another bucket or prefix, possibly in another region"),
+		Flags: []cli.Flag{
+			flags.FlagCopySourceBucket,
+			flags.FlagSourcePrefix,
+			flags.FlagSourceRegion,
+			flags.FlagBucket,
+			flags.FlagCopyPrefix,
+			flags.FlagInclude,
+			flags.FlagExclude,
+			flags.FlagKeysFile,
+			flags.FlagFailuresFile,
+			flags.FlagDryRun,
+			flags.FlagJobs,
+			flags.FlagMultipartThreshold,
+			flags.FlagCopyPartSize,
+			flags.FlagCopyConcurrency,
+			flags.FlagCopySourceSSECustomerAlgorithm,
+			flags.FlagCopySourceSSECustomerKey,
+			flags.FlagSSECustomerAlgorithm,
+			flags.FlagSSECustomerKey,
+			flags.FlagRegion,
+			flags.FlagOutput,
+			flags.FlagJSON,
+		},
+		Action: functions.ObjectsCopy,
+	}
+
 	// CommandObjects - List all objects in a bucket (OneCloud version)
 	// command:
 	//	 ibmcloud cos objects
@@ -549,11 +645,13 @@ var (
 			flags.FlagDelimiter,
 			flags.FlagEncodingType,
 			flags.FlagPrefix,
+			flags.FlagInclude,
+			flags.FlagExclude,
 			flags.FlagMarker,
 			flags.FlagPageSize,
 			flags.FlagMaxItems,
 			flags.FlagRegion,
-			flags.FlagOutput,
+			flags.FlagOutputStream,
 			flags.FlagJSON,
 		},
 		Action: functions.ObjectsList,
@@ -618,7 +716,7 @@ var (
 			flags.FlagVersionIdMarker,
 			flags.FlagPageSize,
 			flags.FlagRegion,
-			flags.FlagOutput,
+			flags.FlagOutputStream,
 			flags.FlagJSON,
 		},
 		Action: functions.ObjectVersions,
@@ -639,6 +737,8 @@ var (
 			flags.FlagContentLanguage,
 			flags.FlagContentType,
 			flags.FlagMetadata,
+			flags.FlagSSECustomerAlgorithm,
+			flags.FlagSSECustomerKey,
 			flags.FlagRegion,
 			flags.FlagTagging,
 			flags.FlagWebsiteRedirectLocation,
@@ -699,12 +799,32 @@ var (
 			flags.FlagPageSize,
 			flags.FlagMaxItems,
 			flags.FlagRegion,
-			flags.FlagOutput,
+			flags.FlagOutputStream,
 			flags.FlagJSON,
 		},
 		Action: functions.MultiPartList,
 	}
 
+	// CommandMPUsCleanup - Abort the stale multipart uploads
+	// command:
+	//	 ibmcloud cos multipart-uploads-cleanup
+	CommandMPUsCleanup = cli.Command{
+		Name:        MultipartUploadsCleanup,
+		Description: T("Abort the incomplete multipart uploads initiated before a given age and report the storage reclaimed"),
+		Flags: []cli.Flag{
+			flags.FlagBucket,
+			flags.FlagOlderThan,
+			flags.FlagCleanupPrefix,
+			flags.FlagCleanupJobs,
+			flags.FlagDryRun,
+			flags.FlagForce,
+			flags.FlagRegion,
+			flags.FlagOutput,
+			flags.FlagJSON,
+		},
+		Action: functions.MultipartUploadsCleanup,
+	}
+
 	// CommandPartUpload - Upload a part of an object (OneCloud version)
 	// command:
 	//	 ibmcloud cos part-upload
@@ -719,6 +839,9 @@ var (
 			flags.FlagContentMD5,
 			flags.FlagContentLength,
 			flags.FlagBody,
+			flags.FlagMaxBandwidth,
+			flags.FlagSSECustomerAlgorithm,
+			flags.FlagSSECustomerKey,
 			flags.FlagRegion,
 			flags.FlagOutput,
 			flags.FlagJSON,
@@ -764,7 +887,7 @@ var (
 			flags.FlagPageSize,
 			flags.FlagMaxItems,
 			flags.FlagRegion,
-			flags.FlagOutput,
+			flags.FlagOutputStream,
 			flags.FlagJSON,
 		},
 		Action: functions.PartsList,
@@ -826,8 +949,15 @@ var (
 		Description: T("Download an object using a managed multipart transfer"),
 		Flags: []cli.Flag{
 			flags.FlagBucket,
-			flags.FlagKey,
+			flags.FlagDownloadKey,
+			flags.FlagRecursiveDownload,
+			flags.FlagDownloadPrefix,
+			flags.FlagInclude,
+			flags.FlagExclude,
+			flags.FlagDryRun,
+			flags.FlagJobs,
 			flags.FlagForce,
+			flags.FlagResumeDownload,
 			flags.FlagConcurrency,
 			flags.FlagPartSize,
 			flags.FlagIfMatch,
@@ -841,6 +971,12 @@ var (
 			flags.FlagResponseContentLanguage,
 			flags.FlagResponseContentType,
 			flags.FlagResponseExpires,
+			flags.FlagMaxBandwidth,
+			flags.FlagExtract,
+			flags.FlagDecompress,
+			flags.FlagDecryptionKeyFile,
+			flags.FlagSSECustomerAlgorithm,
+			flags.FlagSSECustomerKey,
 			flags.FlagRegion,
 			flags.FlagOutput,
 			flags.FlagJSON,
@@ -873,6 +1009,10 @@ var (
 			flags.FlagBucket,
 			flags.FlagKey,
 			flags.FlagFile,
+			flags.FlagRecursiveUpload,
+			flags.FlagUploadPrefix,
+			flags.FlagJobs,
+			flags.FlagResumeUpload,
 			flags.FlagConcurrency,
 			flags.FlagMaxUploadParts,
 			flags.FlagPartSize,
@@ -885,6 +1025,14 @@ var (
 			flags.FlagContentMD5,
 			flags.FlagContentType,
 			flags.FlagMetadata,
+			flags.FlagMaxBandwidth,
+			flags.FlagArchive,
+			flags.FlagSkipExisting,
+			flags.FlagIfChanged,
+			flags.FlagCompress,
+			flags.FlagEncryptionKeyFile,
+			flags.FlagSSECustomerAlgorithm,
+			flags.FlagSSECustomerKey,
 			flags.FlagRegion,
 			flags.FlagOutput,
 			flags.FlagJSON,
@@ -892,6 +1040,68 @@ var (
 		Action: functions.Upload,
 	}
 
+	// CommandSync - Synchronize a local directory with a bucket prefix
+	// command:
+	//	 ibmcloud cos sync
+	CommandSync = cli.Command{
+		Name:        Sync,
+		Description: T("Synchronize a local directory with objects in a bucket, transferring only what changed"),
+		Flags: []cli.Flag{
+			flags.FlagBucket,
+			flags.FlagPrefix,
+			flags.FlagDirection,
+			flags.FlagDeleteExtraneous,
+			flags.FlagDryRun,
+			flags.FlagConcurrency,
+			flags.FlagPartSize,
+			flags.FlagLeavePartsOnErrors,
+			flags.FlagMaxUploadParts,
+			flags.FlagMaxBandwidth,
+			flags.FlagRegion,
+			flags.FlagOutput,
+			flags.FlagJSON,
+		},
+		ArgsUsage: "LOCAL_DIRECTORY",
+		Action:    functions.Sync,
+	}
+
+	// CommandBatch - Run the operations listed in a manifest
+	// command:
+	//	 ibmcloud cos batch
+	CommandBatch = cli.Command{
+		Name:        Batch,
+		Description: T("Run the put, copy, delete and tag operations listed in a manifest"),
+		Flags: []cli.Flag{
+			flags.FlagManifest,
+			flags.FlagResults,
+			flags.FlagBatchFailuresFile,
+			flags.FlagBatchJobs,
+			flags.FlagRegion,
+			flags.FlagOutput,
+			flags.FlagJSON,
+		},
+		Action: functions.Batch,
+	}
+
+	// CommandBench - Measure the throughput of transfers
+	// command:
+	//	 ibmcloud cos bench
+	CommandBench = cli.Command{
+		Name:        Bench,
+		Description: T("Measure the throughput and latency of uploads and downloads of synthetic objects for a matrix of part sizes and concurrencies, and recommend the fastest settings. The objects are deleted afterwards."),
+		Flags: []cli.Flag{
+			flags.FlagBucket,
+			flags.FlagBenchSize,
+			flags.FlagBenchObjects,
+			flags.FlagBenchPartSize,
+			flags.FlagBenchConcurrency,
+			flags.FlagRegion,
+			flags.FlagOutput,
+			flags.FlagJSON,
+		},
+		Action: functions.Bench,
+	}
+
 	CommandAsperaUpload = cli.Command{
 		Name:        AsperaUpload,
 		Description: T("Upload files or directories via Aspera"),
@@ -1468,10 +1678,12 @@ var (
 			flags.FlagFetchOwner,
 			flags.FlagMaxItems,
 			flags.FlagPrefix,
+			flags.FlagInclude,
+			flags.FlagExclude,
 			flags.FlagStartAfter,
 			flags.FlagPageSize,
 			flags.FlagRegion,
-			flags.FlagOutput,
+			flags.FlagOutputStream,
 			flags.FlagJSON,
 		},
 		Action: functions.ObjectsListV2,
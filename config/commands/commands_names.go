@@ -4,6 +4,12 @@ const (
 	// AbortMultipartUpload Command
 	AbortMultipartUpload = "abort-multipart-upload"
 
+	// Batch Command
+	Batch = "batch"
+
+	// Bench Command
+	Bench = "bench"
+
 	// Buckets Command
 	Buckets = "buckets"
 
@@ -166,15 +172,24 @@ const (
 	// MultipartUploads Command
 	MultipartUploads = "multipart-uploads"
 
+	// MultipartUploadsCleanup Command
+	MultipartUploadsCleanup = "multipart-uploads-cleanup"
+
 	// ObjectCopy Command
 	ObjectCopy = "object-copy"
 
 	// ObjectDelete Command
 	ObjectDelete = "object-delete"
 
+	// ObjectMove Command
+	ObjectMove = "object-move"
+
 	// ObjectsDelete Command
 	ObjectsDelete = "objects-delete"
 
+	// ObjectsCopy Command
+	ObjectsCopy = "objects-copy"
+
 	// ObjectGet Command
 	ObjectGet = "object-get"
 
@@ -241,6 +256,9 @@ const (
 	// UploadPartCopy Command
 	UploadPartCopy = "upload-part-copy"
 
+	// Sync Command
+	Sync = "sync"
+
 	// Version Command
 	Version = "version"
 
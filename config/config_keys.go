@@ -17,6 +17,12 @@ const (
 	// Download Location constant
 	DownloadLocation = "Download Location"
 
+	// Max Bandwidth constant
+	MaxBandwidth = "Max Bandwidth"
+
+	// Content Types constant, extension to Content-Type mappings
+	ContentTypes = "Content Types"
+
 	// Region constant
 	DefaultRegion = "Default Region"
 
@@ -34,6 +40,12 @@ const (
 	LabelURLStyle  = "URL Style"
 
 	ServiceEndpointURL = "ServiceEndpointURL"
+
+	// Retries of the failed requests
+	MaxRetries       = "Max Retries"
+	RetryBaseDelay   = "Retry Base Delay"
+	RetryMaxDelay    = "Retry Max Delay"
+	RetryStatusCodes = "Retry Status Codes"
 )
 
 // CLI App Context Metadata Keys
@@ -25,6 +25,8 @@ const (
 	CopySourceIfNoneMatch          = "CopySourceIfNoneMatch"
 	CopySourceIfUnmodifiedSince    = "CopySourceIfUnmodifiedSince"
 	CopySourceRange                = "CopySourceRange"
+	CopySourceSSECustomerAlgorithm = "CopySourceSSECustomerAlgorithm"
+	CopySourceSSECustomerKey       = "CopySourceSSECustomerKey"
 	CORSConfiguration              = "CORSConfiguration"
 	Delete                         = "Delete"
 	Delimiter                      = "Delimiter"
@@ -62,6 +64,8 @@ const (
 	ResponseContentLanguage        = "ResponseContentLanguage"
 	ResponseContentType            = "ResponseContentType"
 	ResponseExpires                = "ResponseExpires"
+	SSECustomerAlgorithm           = "SSECustomerAlgorithm"
+	SSECustomerKey                 = "SSECustomerKey"
 	Tagging                        = "Tagging"
 	TaggingDirective               = "TaggingDirective"
 	VersionId                      = "VersionId"
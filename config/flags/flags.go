@@ -8,7 +8,7 @@ import (
 var (
 	FlagBody = cli.StringFlag{
 		Name:  Body,
-		Usage: T("Object data location (`FILE_PATH`)."),
+		Usage: T("Object data location (`FILE_PATH`), - reads the data from the standard input."),
 	}
 
 	FlagBucket = cli.StringFlag{
@@ -121,7 +121,7 @@ var (
 
 	FlagFile = cli.StringFlag{
 		Name:   File,
-		Usage:  T("The `PATH` to the file to upload."),
+		Usage:  T("The `PATH` to the file to upload, - reads the data from the standard input."),
 		Hidden: true,
 	}
 
@@ -395,6 +395,11 @@ var (
 		Usage: T("Output `FORMAT` can be only json or text."),
 	}
 
+	FlagOutputStream = cli.StringFlag{
+		Name:  Output,
+		Usage: T("Output `FORMAT` can be json, text or ndjson. With ndjson every entry is written on its own line as soon as its page is listed, followed by the markers to resume a truncated listing from."),
+	}
+
 	FlagContinuationToken = cli.StringFlag{
 		Name:  ContinuationToken,
 		Usage: T("A `Starting Token` to specify where to start paginating. This is the NextContinuationToken from a previously truncated response."),
@@ -424,4 +429,309 @@ var (
 		Name:  Region,
 		Usage: T("Display endpoint url for the `REGION`."),
 	}
+
+	FlagDirection = cli.StringFlag{
+		Name:  Direction,
+		Usage: T("The `DIRECTION` of the synchronization, either upload (local directory to bucket) or download (bucket to local directory). Default value is upload."),
+	}
+
+	FlagDryRun = cli.BoolFlag{
+		Name:  DryRun,
+		Usage: T("Display the operations that would be performed without executing them."),
+	}
+
+	FlagRecursiveUpload = cli.BoolFlag{
+		Name:  Recursive,
+		Usage: T("Upload every file in the directory tree given by --file instead of a single file."),
+	}
+
+	FlagUploadPrefix = cli.StringFlag{
+		Name:  Prefix,
+		Usage: T("The `PREFIX` prepended to the key of every file uploaded with --recursive."),
+	}
+
+	FlagRecursiveDelete = cli.BoolFlag{
+		Name:  Recursive,
+		Usage: T("Delete every object under --prefix. The listing is paged and every page is deleted in a batch of up to 1000 keys."),
+	}
+
+	FlagDeletePrefix = cli.StringFlag{
+		Name:  Prefix,
+		Usage: T("Delete the objects whose keys begin with the specified `PREFIX`, with --recursive."),
+	}
+
+	FlagDeleteJobs = cli.StringFlag{
+		Name:  Jobs,
+		Usage: T("The `NUMBER` of batches of keys deleted in parallel. Default value is 4."),
+	}
+
+	FlagEmptyBucket = cli.BoolFlag{
+		Name:  Empty,
+		Usage: T("Delete a bucket which is not empty: every object, version and delete marker is deleted and every incomplete multipart upload is aborted, after the name of the bucket is typed to confirm it. Emptying has its own flag rather than --force, which only skips the confirmation prompt of scripts deleting empty buckets."),
+	}
+
+	FlagConfirmBucket = cli.StringFlag{
+		Name:  ConfirmBucket,
+		Usage: T("The `NAME` of the bucket emptied with --empty, which confirms it without prompting for the name."),
+	}
+
+	FlagOlderThan = cli.StringFlag{
+		Name:  OlderThan,
+		Usage: T("Abort the multipart uploads initiated more than `AGE` ago, in days (7d), hours (12h) or minutes (30m)."),
+	}
+
+	FlagCleanupPrefix = cli.StringFlag{
+		Name:  Prefix,
+		Usage: T("Abort only the multipart uploads of the keys that begin with the specified `PREFIX`."),
+	}
+
+	FlagCleanupJobs = cli.StringFlag{
+		Name:  Jobs,
+		Usage: T("The `NUMBER` of multipart uploads aborted in parallel. Default value is 4."),
+	}
+
+	FlagRecursiveDownload = cli.BoolFlag{
+		Name:  Recursive,
+		Usage: T("Download every object under --prefix into the OUTFILE directory, recreating the key hierarchy."),
+	}
+
+	FlagDownloadKey = cli.StringFlag{
+		Name:   Key,
+		Usage:  T("The `KEY` (OBJECT_NAME) of the object. A key with *, ? or [...] wildcards downloads every matching object into the OUTFILE directory, unless an object has this exact key, \\ escapes a wildcard character."),
+		Hidden: true,
+	}
+
+	FlagDeleteKey = cli.StringFlag{
+		Name:  Key,
+		Usage: T("Delete the objects whose key matches the `PATTERN`, with *, ? or [...] wildcards, instead of the objects listed in --delete."),
+	}
+
+	FlagDownloadPrefix = cli.StringFlag{
+		Name:  Prefix,
+		Usage: T("Download the objects whose key begins with `PREFIX` when used with --recursive."),
+	}
+
+	FlagJobs = cli.StringFlag{
+		Name:  Jobs,
+		Usage: T("The `NUMBER` of objects transferred in parallel. Default value is 4."),
+	}
+
+	FlagResumeUpload = cli.BoolFlag{
+		Name:  Resume,
+		Usage: T("Record the upload ID and the completed parts in a journal next to the file, and continue an interrupted upload of the same file from its journal."),
+	}
+
+	FlagResumeDownload = cli.BoolFlag{
+		Name:  Resume,
+		Usage: T("Keep the partial file and the object ETag next to it when the download fails, and continue an interrupted download of the same object from where it stopped."),
+	}
+
+	FlagDeleteExtraneous = cli.BoolFlag{
+		Name:  Delete,
+		Usage: T("Delete objects or files in the destination that do not exist in the source."),
+	}
+
+	FlagMaxBandwidth = cli.StringFlag{
+		Name:  MaxBandwidth,
+		Usage: T("Maximum `RATE` of the transfer, for example 50MB/s, shared by all its concurrent parts. Defaults to the max-bandwidth configuration, unlimited when not set."),
+	}
+
+	FlagMultipartThreshold = cli.StringFlag{
+		Name:  MultipartThreshold,
+		Usage: T("Source objects larger than this `SIZE` (in bytes) are copied with a multipart copy. Default value is 5GB."),
+	}
+
+	FlagCopyPartSize = cli.StringFlag{
+		Name:  PartSize,
+		Usage: T("The `SIZE` (in bytes) of the parts of a multipart copy. The minimum allowed part size is 5MB. Default value is 100MB."),
+	}
+
+	FlagSourceBucket = cli.StringFlag{
+		Name:  SourceBucket,
+		Usage: T("The `NAME` of the bucket the object is moved from. Defaults to the destination bucket."),
+	}
+
+	FlagSourceKey = cli.StringFlag{
+		Name:  SourceKey,
+		Usage: T("The `KEY` of the object to move."),
+	}
+
+	FlagCopySourceBucket = cli.StringFlag{
+		Name:  SourceBucket,
+		Usage: T("The `NAME` of the bucket the objects are copied from."),
+	}
+
+	FlagSourcePrefix = cli.StringFlag{
+		Name:  SourcePrefix,
+		Usage: T("Copy the objects whose keys begin with the specified `PREFIX`."),
+	}
+
+	FlagCopyPrefix = cli.StringFlag{
+		Name:  Prefix,
+		Usage: T("The `PREFIX` that replaces --source-prefix in the keys of the copies."),
+	}
+
+	FlagSourceRegion = cli.StringFlag{
+		Name:  SourceRegion,
+		Usage: T("The `REGION` of the source bucket, when it differs from the region of the destination bucket. The objects are then streamed from one region to the other."),
+	}
+
+	FlagInclude = cli.StringSliceFlag{
+		Name:  Include,
+		Usage: T("Only process the keys matching the glob `PATTERN`, relative to the prefix. * and ? do not match /, ** matches anything, a pattern without / matches the last part of the key. Can be repeated."),
+	}
+
+	FlagExclude = cli.StringSliceFlag{
+		Name:  Exclude,
+		Usage: T("Skip the keys matching the glob `PATTERN`, relative to the prefix. Can be repeated."),
+	}
+
+	FlagFailuresFile = cli.StringFlag{
+		Name:  FailuresFile,
+		Usage: T("Write the keys that failed, one per line, to the file at `PATH`. Pass the file to --keys-file to retry them."),
+	}
+
+	FlagKeysFile = cli.StringFlag{
+		Name:  KeysFile,
+		Usage: T("Only process the keys listed, one per line, in the file at `PATH`, such as a --failures-file."),
+	}
+
+	FlagManifest = cli.StringFlag{
+		Name:  Manifest,
+		Usage: T("The file at `PATH` listing the operations to run, one JSON object per line. The \"op\" field is one of put, copy, delete or tag, the other fields are the flags of the matching command, such as {\"op\": \"delete\", \"bucket\": \"b\", \"key\": \"k\"}."),
+	}
+
+	FlagResults = cli.StringFlag{
+		Name:  Results,
+		Usage: T("Write the status of every line of the manifest to the file at `PATH`. Defaults to the manifest path with the .results.jsonl extension."),
+	}
+
+	FlagBatchFailuresFile = cli.StringFlag{
+		Name:  FailuresFile,
+		Usage: T("Write the manifest lines that failed to the file at `PATH`, to run them again with --manifest."),
+	}
+
+	FlagBatchJobs = cli.StringFlag{
+		Name:  Jobs,
+		Usage: T("The `NUMBER` of operations run in parallel. Default value is 4."),
+	}
+
+	FlagCopyConcurrency = cli.StringFlag{
+		Name:  Concurrency,
+		Usage: T("The `NUMBER` of parts of a multipart copy copied in parallel. Default value is 5."),
+	}
+
+	FlagEncryptionKeyFile = cli.StringFlag{
+		Name:  EncryptionKeyFile,
+		Usage: T("Encrypt the object on the client with a new AES-256-GCM data key, wrapped by the 256-bit master key in the file at `PATH` (raw, hex or base64 encoded) and stored in the object metadata."),
+	}
+
+	FlagDecryptionKeyFile = cli.StringFlag{
+		Name:  EncryptionKeyFile,
+		Usage: T("Decrypt an object encrypted on the client with the master key in the file at `PATH`. Objects that are not encrypted are downloaded as they are."),
+	}
+
+	FlagSSECustomerAlgorithm = cli.StringFlag{
+		Name:  SSECustomerAlgorithm,
+		Usage: T("The `ALGORITHM` the object is encrypted with using the customer-provided key. Default value is AES256."),
+	}
+
+	FlagSSECustomerKey = cli.StringFlag{
+		Name:  SSECustomerKey,
+		Usage: T("The 256-bit customer-provided `KEY` the object is encrypted with on the server, or file://PATH to read it from a file. The key is not stored, the same key must be given to read the object."),
+	}
+
+	FlagCopySourceSSECustomerAlgorithm = cli.StringFlag{
+		Name:  CopySourceSSECustomerAlgorithm,
+		Usage: T("The `ALGORITHM` the source object is encrypted with using the customer-provided key. Default value is AES256."),
+	}
+
+	FlagCopySourceSSECustomerKey = cli.StringFlag{
+		Name:  CopySourceSSECustomerKey,
+		Usage: T("The customer-provided `KEY` the source object is encrypted with, or file://PATH to read it from a file."),
+	}
+
+	FlagCompress = cli.StringFlag{
+		Name:  Compress,
+		Usage: T("Compress the object on the fly with `ALGORITHM`, gzip or zstd. The Content-Encoding of the object is set to the algorithm and its original size is recorded in its metadata."),
+	}
+
+	FlagDecompress = cli.BoolFlag{
+		Name:  Decompress,
+		Usage: T("Restore the original bytes of an object compressed with gzip or zstd, according to its Content-Encoding."),
+	}
+
+	FlagArchive = cli.StringFlag{
+		Name:  Archive,
+		Usage: T("Upload the directory given by --file as a single archive in `FORMAT`, tar or tar.gz, streamed into the object while the directory is read. Paths and modes are preserved."),
+	}
+
+	FlagExtract = cli.StringFlag{
+		Name:  Extract,
+		Usage: T("Extract the tar or tar.gz archive of the object into the `DIRECTORY` while it is downloaded. Paths and modes are preserved."),
+	}
+
+	FlagSkipExisting = cli.BoolFlag{
+		Name:  SkipExisting,
+		Usage: T("Skip the upload when the key already exists in the bucket."),
+	}
+
+	FlagIfChanged = cli.BoolFlag{
+		Name:  IfChanged,
+		Usage: T("Skip the upload when the object has the same size and MD5 as the file. The ETag of an object uploaded in parts is computed locally with the same part size."),
+	}
+
+	FlagMaxRetries = cli.StringFlag{
+		Name:  MaxRetries,
+		Usage: T("Maximum `NUMBER` of retries of a failed request. Defaults to the max-retries configuration, 3 when not set."),
+	}
+
+	FlagRetryBaseDelay = cli.StringFlag{
+		Name:  RetryBaseDelay,
+		Usage: T("Base `DURATION` of the exponential backoff between the retries, for example 100ms. Defaults to the retry-base-delay configuration, 30ms when not set."),
+	}
+
+	FlagRetryMaxDelay = cli.StringFlag{
+		Name:  RetryMaxDelay,
+		Usage: T("Maximum `DURATION` of the backoff between the retries, for example 20s. Defaults to the retry-max-delay configuration, 5m when not set."),
+	}
+
+	FlagRetryStatusCodes = cli.StringFlag{
+		Name:  RetryStatusCodes,
+		Usage: T("Comma separated HTTP status `CODES` of the responses retried in addition to the throttling and server errors, for example 500,503. Defaults to the retry-status-codes configuration."),
+	}
+
+	FlagDebug = cli.BoolFlag{
+		Name:  Debug,
+		Usage: T("Report debug information, such as the retries of the requests, in the error output."),
+	}
+
+	FlagBenchSize = cli.StringFlag{
+		Name:  Size,
+		Usage: T("The `SIZE` of the synthetic objects, for example 1GB. Default value is 64MB."),
+	}
+
+	FlagBenchObjects = cli.StringFlag{
+		Name:  Objects,
+		Usage: T("The `NUMBER` of objects uploaded and downloaded for every setting. Default value is 3."),
+	}
+
+	FlagBenchPartSize = cli.StringFlag{
+		Name:  PartSize,
+		Usage: T("Comma separated part `SIZES` to measure, for example 8MB,64MB. The minimum allowed part size is 5MB. Default value is 5MB,16MB,64MB."),
+	}
+
+	FlagBenchConcurrency = cli.StringFlag{
+		Name:  Concurrency,
+		Usage: T("Comma separated `NUMBERS` of parts transferred in parallel to measure, for example 4,16. Default value is 1,5,10."),
+	}
 )
+
+// GlobalFlags are accepted by every command sending requests to COS
+var GlobalFlags = []cli.Flag{
+	FlagMaxRetries,
+	FlagRetryBaseDelay,
+	FlagRetryMaxDelay,
+	FlagRetryStatusCodes,
+	FlagDebug,
+}
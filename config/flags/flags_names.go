@@ -7,6 +7,7 @@ const (
 	CacheControl                   = "cache-control"
 	Class                          = "class"
 	Concurrency                    = "concurrency"
+	ConfirmBucket                  = "confirm-bucket"
 	ContentDisposition             = "content-disposition"
 	ContentEncoding                = "content-encoding"
 	ContentLanguage                = "content-language"
@@ -24,6 +25,7 @@ const (
 	Delete                         = "delete"
 	Delimiter                      = "delimiter"
 	DDL                            = "ddl"
+	Empty                          = "empty"
 	EncodingType                   = "encoding-type"
 	File                           = "file"
 	Force                          = "force"
@@ -82,4 +84,40 @@ const (
 	StartAfter                     = "start-after"
 	ContinuationToken              = "starting-token"
 	LifecycleConfiguration         = "lifecycle-configuration"
+	Direction                      = "direction"
+	DryRun                         = "dry-run"
+	Recursive                      = "recursive"
+	Jobs                           = "jobs"
+	Resume                         = "resume"
+	MaxBandwidth                   = "max-bandwidth"
+	MultipartThreshold             = "multipart-threshold"
+	SourceBucket                   = "source-bucket"
+	SourceKey                      = "source-key"
+	SourcePrefix                   = "source-prefix"
+	SourceRegion                   = "source-region"
+	Include                        = "include"
+	Exclude                        = "exclude"
+	FailuresFile                   = "failures-file"
+	KeysFile                       = "keys-file"
+	Manifest                       = "manifest"
+	Results                        = "results"
+	EncryptionKeyFile              = "encryption-key-file"
+	SSECustomerAlgorithm           = "sse-customer-algorithm"
+	SSECustomerKey                 = "sse-customer-key"
+	CopySourceSSECustomerAlgorithm = "copy-source-sse-customer-algorithm"
+	CopySourceSSECustomerKey       = "copy-source-sse-customer-key"
+	Compress                       = "compress"
+	Decompress                     = "decompress"
+	Archive                        = "archive"
+	Extract                        = "extract"
+	SkipExisting                   = "skip-existing"
+	IfChanged                      = "if-changed"
+	MaxRetries                     = "max-retries"
+	RetryBaseDelay                 = "retry-base-delay"
+	RetryMaxDelay                  = "retry-max-delay"
+	RetryStatusCodes               = "retry-status-codes"
+	Debug                          = "debug"
+	Size                           = "size"
+	Objects                        = "objects"
+	OlderThan                      = "older-than"
 )
@@ -3,6 +3,7 @@
 package providers
 
 import (
+	"io"
 	gohttp "net/http"
 	"os"
 	"path/filepath"
@@ -69,6 +70,7 @@ func NewSession(config *aws.Config) (sess *session.Session, err error) {
 	sess, err = session.NewSession(config)
 	if err == nil {
 		sess.Handlers.Build.PushBackNamed(utils.CLIVersionUserAgentHandler)
+		sess.Handlers.Send.PushFrontNamed(utils.IdentityEncodingHandler)
 	}
 	return
 }
@@ -104,6 +106,14 @@ func NewConfig(ctx plugin.PluginContext, resolver endpoints.Resolver, baseConfig
 	}
 	conf.WithS3ForcePathStyle(forcePathStyle)
 
+	retryer, err := utils.NewRetryer(ctx.PluginConfig())
+	if err != nil {
+		return nil, err
+	}
+	// the retries are reported in the trace
+	retryer.Logger = conf.Logger
+	conf.Retryer = retryer
+
 	conf.WithEndpointResolver(resolver)
 
 	return conf, nil
@@ -126,6 +136,10 @@ func (_ *FileOperationsImpl) WriteCloserOpen(location string) (utils.WriteCloser
 	return os.Create(location)
 }
 
+func (_ *FileOperationsImpl) WriteCloserOpenExisting(location string) (utils.WriteCloser, error) {
+	return os.OpenFile(location, os.O_WRONLY|os.O_CREATE, 0666)
+}
+
 func (_ *FileOperationsImpl) GetFileInfo(location string) (os.FileInfo, error) {
 	return os.Stat(location)
 }
@@ -134,6 +148,26 @@ func (_ *FileOperationsImpl) Remove(location string) error {
 	return os.Remove(location)
 }
 
+func (_ *FileOperationsImpl) Walk(root string, walkFn filepath.WalkFunc) error {
+	return filepath.Walk(root, walkFn)
+}
+
+func (_ *FileOperationsImpl) MkdirAll(location string) error {
+	return os.MkdirAll(location, 0755)
+}
+
+func (_ *FileOperationsImpl) Chmod(location string, mode os.FileMode) error {
+	return os.Chmod(location, mode)
+}
+
+func (_ *FileOperationsImpl) Stdin() io.Reader {
+	return os.Stdin
+}
+
+func (_ *FileOperationsImpl) Stdout() io.Writer {
+	return os.Stdout
+}
+
 func (_ *FileOperationsImpl) GetTotalBytes(location string) (int64, error) {
 	var size int64
 	err := filepath.Walk(location, func(_ string, info os.FileInfo, err error) error {
@@ -173,7 +207,8 @@ func GetBaseConfig(ctx plugin.PluginContext) *BaseConfig {
 	}
 
 	if ctx.Trace() == "true" {
-		trace.Logger = trace.NewLogger(ctx.Trace())
+		// the customer-provided keys sent in the headers are hidden from the trace
+		trace.Logger = utils.NewRedactingLogger(trace.NewLogger(ctx.Trace()))
 		conf.LogLevel = aws.LogLevel(aws.LogDebug)
 		conf.Logger = new(loggerWrap)
 
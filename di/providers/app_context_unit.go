@@ -3,6 +3,8 @@
 package providers
 
 import (
+	"sync"
+
 	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/bluemix/terminal"
 	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
 	terminalHelpers "github.com/IBM-Cloud/ibm-cloud-cli-sdk/testhelpers/terminal"
@@ -11,6 +13,7 @@ import (
 	"github.com/IBM/ibm-cos-sdk-go/aws/session"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config"
 	"github.com/IBM/ibmcloud-cos-cli/di/providers/mocks"
 	"github.com/IBM/ibmcloud-cos-cli/utils"
 )
@@ -22,7 +25,7 @@ var (
 	MockUploaderAPI    = new(mocks.Uploader)
 	MockDownloaderAPI  = new(mocks.Downloader)
 	MockAsperaTransfer = new(mocks.AsperaTransfer)
-	MockPluginConfig   = new(mocks.PluginConfig)
+	MockPluginConfig   = newMockPluginConfig()
 
 	MockRegionResolver = &RegionResolverMock{
 		ListKnownRegions: mocks.ListKnownRegions{},
@@ -33,6 +36,10 @@ var (
 
 	ReferenceUploader   = new(s3manager.Uploader)
 	ReferenceDownloader = new(s3manager.Downloader)
+
+	// sessions of the clients created, the commands may create them concurrently
+	clientSessions     []*session.Session
+	clientSessionsLock sync.Mutex
 )
 
 func MocksRESET() {
@@ -41,7 +48,7 @@ func MocksRESET() {
 	MockUploaderAPI = new(mocks.Uploader)
 	MockDownloaderAPI = new(mocks.Downloader)
 	MockAsperaTransfer = new(mocks.AsperaTransfer)
-	MockPluginConfig = new(mocks.PluginConfig)
+	MockPluginConfig = newMockPluginConfig()
 
 	MockRegionResolver = &RegionResolverMock{
 		ListKnownRegions: mocks.ListKnownRegions{},
@@ -52,6 +59,30 @@ func MocksRESET() {
 
 	ReferenceUploader = new(s3manager.Uploader)
 	ReferenceDownloader = new(s3manager.Downloader)
+
+	clientSessionsLock.Lock()
+	clientSessions = nil
+	clientSessionsLock.Unlock()
+}
+
+// ClientSessions returns the sessions of the clients created since the last reset
+func ClientSessions() []*session.Session {
+	clientSessionsLock.Lock()
+	defer clientSessionsLock.Unlock()
+	return append([]*session.Session{}, clientSessions...)
+}
+
+// newMockPluginConfig returns a plugin config mock where the optional settings
+// read by every transfer are not set, tests can still set expectations on any other key
+func newMockPluginConfig() *mocks.PluginConfig {
+	pluginConfig := new(mocks.PluginConfig)
+	pluginConfig.On("Exists", config.MaxBandwidth).Return(false).Maybe()
+	pluginConfig.On("GetStringWithDefault", config.MaxBandwidth, "").Return("", nil).Maybe()
+	pluginConfig.On("GetStringWithDefault", config.ContentTypes, "").Return("", nil).Maybe()
+	for _, key := range []string{config.MaxRetries, config.RetryBaseDelay, config.RetryMaxDelay, config.RetryStatusCodes} {
+		pluginConfig.On("GetStringWithDefault", key, "").Return("", nil).Maybe()
+	}
+	return pluginConfig
 }
 
 type RegionResolverMock struct {
@@ -64,7 +95,10 @@ func NewUI() terminal.UI {
 }
 
 func GetS3APIFn() func(*session.Session) s3iface.S3API {
-	return func(*session.Session) s3iface.S3API {
+	return func(sess *session.Session) s3iface.S3API {
+		clientSessionsLock.Lock()
+		clientSessions = append(clientSessions, sess)
+		clientSessionsLock.Unlock()
 		return MockS3API
 	}
 }
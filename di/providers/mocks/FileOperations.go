@@ -3,7 +3,9 @@
 package mocks
 
 import (
+	io "io"
 	fs "io/fs"
+	filepath "path/filepath"
 
 	utils "github.com/IBM/ibmcloud-cos-cli/utils"
 	mock "github.com/stretchr/testify/mock"
@@ -14,6 +16,20 @@ type FileOperations struct {
 	mock.Mock
 }
 
+// Chmod provides a mock function with given fields: location, mode
+func (_m *FileOperations) Chmod(location string, mode fs.FileMode) error {
+	ret := _m.Called(location, mode)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, fs.FileMode) error); ok {
+		r0 = rf(location, mode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetFileInfo provides a mock function with given fields: location
 func (_m *FileOperations) GetFileInfo(location string) (fs.FileInfo, error) {
 	ret := _m.Called(location)
@@ -58,6 +74,20 @@ func (_m *FileOperations) GetTotalBytes(location string) (int64, error) {
 	return r0, r1
 }
 
+// MkdirAll provides a mock function with given fields: location
+func (_m *FileOperations) MkdirAll(location string) error {
+	ret := _m.Called(location)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(location)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ReadSeekerCloserOpen provides a mock function with given fields: location
 func (_m *FileOperations) ReadSeekerCloserOpen(location string) (utils.ReadSeekerCloser, error) {
 	ret := _m.Called(location)
@@ -95,6 +125,20 @@ func (_m *FileOperations) Remove(location string) error {
 	return r0
 }
 
+// Walk provides a mock function with given fields: root, walkFn
+func (_m *FileOperations) Walk(root string, walkFn filepath.WalkFunc) error {
+	ret := _m.Called(root, walkFn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, filepath.WalkFunc) error); ok {
+		r0 = rf(root, walkFn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // WriteCloserOpen provides a mock function with given fields: location
 func (_m *FileOperations) WriteCloserOpen(location string) (utils.WriteCloser, error) {
 	ret := _m.Called(location)
@@ -117,3 +161,58 @@ func (_m *FileOperations) WriteCloserOpen(location string) (utils.WriteCloser, e
 
 	return r0, r1
 }
+
+// WriteCloserOpenExisting provides a mock function with given fields: location
+func (_m *FileOperations) WriteCloserOpenExisting(location string) (utils.WriteCloser, error) {
+	ret := _m.Called(location)
+
+	var r0 utils.WriteCloser
+	if rf, ok := ret.Get(0).(func(string) utils.WriteCloser); ok {
+		r0 = rf(location)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(utils.WriteCloser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(location)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Stdin provides a mock function with given fields:
+func (_m *FileOperations) Stdin() io.Reader {
+	ret := _m.Called()
+
+	var r0 io.Reader
+	if rf, ok := ret.Get(0).(func() io.Reader); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.Reader)
+		}
+	}
+
+	return r0
+}
+
+// Stdout provides a mock function with given fields:
+func (_m *FileOperations) Stdout() io.Writer {
+	ret := _m.Called()
+
+	var r0 io.Writer
+	if rf, ok := ret.Get(0).(func() io.Writer); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.Writer)
+		}
+	}
+
+	return r0
+}
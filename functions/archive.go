@@ -0,0 +1,257 @@
+package functions
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// Formats a directory is archived in
+const (
+	archiveTar   = "tar"
+	archiveTarGz = "tar.gz"
+)
+
+// archiveContentTypes are the Content-Type of the archives
+var archiveContentTypes = map[string]string{
+	archiveTar:   "application/x-tar",
+	archiveTarGz: "application/gzip",
+}
+
+// getArchive returns the format the directory given by --file is archived in,
+// empty when an archive is not asked for. The flags that cannot be combined with an archive are rejected.
+func getArchive(c *cli.Context, conflicting ...string) (string, error) {
+	if !c.IsSet(flags.Archive) {
+		return "", nil
+	}
+	format := strings.ToLower(c.String(flags.Archive))
+	if _, ok := archiveContentTypes[format]; !ok {
+		return "", &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.Archive,
+			IError:     fmt.Errorf("unsupported archive '%s', use %s or %s", format, archiveTar, archiveTarGz),
+		}
+	}
+	for _, flag := range conflicting {
+		if c.IsSet(flag) {
+			return "", &errors.CommandError{
+				CLIContext: c,
+				Cause:      errors.InvalidValue,
+				Flag:       flag,
+				IError:     fmt.Errorf("--%s cannot be used with --%s", flag, flags.Archive),
+			}
+		}
+	}
+	return format, nil
+}
+
+// validateExtract rejects the flags and the arguments that cannot be combined with an extraction,
+// an archive is only read from its beginning into the directory given by --extract
+func validateExtract(c *cli.Context) error {
+	if !c.IsSet(flags.Extract) {
+		return nil
+	}
+	if c.NArg() > 0 {
+		return &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidNArg,
+		}
+	}
+	for _, flag := range []string{flags.Range, flags.Resume} {
+		if c.IsSet(flag) {
+			return &errors.CommandError{
+				CLIContext: c,
+				Cause:      errors.InvalidValue,
+				Flag:       flag,
+				IError:     fmt.Errorf("--%s cannot be used with --%s", flag, flags.Extract),
+			}
+		}
+	}
+	return nil
+}
+
+// newArchiveReader returns a reader of a tar archive of the root directory, written on the fly
+// while it is read. Directories and regular files are archived with their relative path and mode,
+// other entries are skipped. Closing the reader stops the archive.
+func newArchiveReader(cosContext *utils.CosContext, root, format string) io.ReadCloser {
+	reader, writer := io.Pipe()
+	go func() {
+		var w io.WriteCloser = writer
+		if format == archiveTarGz {
+			w = gzip.NewWriter(writer)
+		}
+		err := writeArchive(cosContext, root, w)
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+		writer.CloseWithError(err)
+	}()
+	return reader
+}
+
+// writeArchive walks the root directory and writes its entries to a tar archive
+func writeArchive(cosContext *utils.CosContext, root string, w io.Writer) error {
+	archive := tar.NewWriter(w)
+	err := cosContext.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			header.Name += "/"
+			return archive.WriteHeader(header)
+		}
+		if err = archive.WriteHeader(header); err != nil {
+			return err
+		}
+		file, err := cosContext.ReadSeekerCloserOpen(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		// the file must not shrink while it is archived
+		_, err = io.CopyN(archive, file, header.Size)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// extractingWriter extracts the tar archive written to it into a directory,
+// the archive is read by a goroutine from a pipe
+type extractingWriter struct {
+	pipe *io.PipeWriter
+	done chan error
+}
+
+// newExtractingWriter starts extracting into the root directory,
+// an archive compressed with gzip is recognized by its header
+func newExtractingWriter(cosContext *utils.CosContext, root string) *extractingWriter {
+	reader, writer := io.Pipe()
+	extracting := &extractingWriter{pipe: writer, done: make(chan error, 1)}
+	go func() {
+		err := extractArchive(cosContext, root, reader)
+		if err == nil {
+			// the padding after the end of the archive
+			_, err = io.Copy(ioutil.Discard, reader)
+		}
+		// stops the writes when the archive cannot be extracted
+		reader.CloseWithError(err)
+		extracting.done <- err
+	}()
+	return extracting
+}
+
+func (w *extractingWriter) Write(p []byte) (int, error) {
+	return w.pipe.Write(p)
+}
+
+// Close waits for the end of the extraction, the error of the archive written when it is not valid
+func (w *extractingWriter) Close() error {
+	w.pipe.Close()
+	return <-w.done
+}
+
+// abort stops the extraction when the archive cannot be written in full
+func (w *extractingWriter) abort(err error) {
+	w.pipe.CloseWithError(err)
+	<-w.done
+}
+
+// extractArchive extracts the directories and regular files of a tar archive under the root directory
+// with their mode, entries escaping the root directory and other entries are skipped.
+// The mode of the directories is set last so read-only directories are filled first.
+func extractArchive(cosContext *utils.CosContext, root string, r io.Reader) error {
+	buffered := bufio.NewReader(r)
+	var source io.Reader = buffered
+	if magic, _ := buffered.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		decompressor, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		defer decompressor.Close()
+		source = decompressor
+	}
+	if err := cosContext.MkdirAll(root); err != nil {
+		return err
+	}
+
+	archive := tar.NewReader(source)
+	directories := make([]string, 0)
+	modes := make(map[string]os.FileMode)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		path := localPathForKey(root, "", strings.TrimSuffix(header.Name, "/"))
+		if path == "" {
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = cosContext.MkdirAll(path); err != nil {
+				return err
+			}
+			directories = append(directories, path)
+			modes[path] = header.FileInfo().Mode().Perm()
+		case tar.TypeReg:
+			if err = extractFile(cosContext, path, header, archive); err != nil {
+				return err
+			}
+		}
+	}
+	for i := len(directories) - 1; i >= 0; i-- {
+		if err := cosContext.Chmod(directories[i], modes[directories[i]]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractFile writes the content of a file of the archive to the path, with its mode
+func extractFile(cosContext *utils.CosContext, path string, header *tar.Header, r io.Reader) error {
+	if err := cosContext.MkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	file, err := cosContext.WriteCloserOpen(path)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return cosContext.Chmod(path, header.FileInfo().Mode().Perm())
+}
@@ -0,0 +1,139 @@
+package functions
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	cerrors "github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// sizeUnits maps the units accepted by --max-bandwidth and the sizes of bench to their size in bytes
+var sizeUnits = map[string]float64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"KB":  1 << 10,
+	"KIB": 1 << 10,
+	"M":   1 << 20,
+	"MB":  1 << 20,
+	"MIB": 1 << 20,
+	"G":   1 << 30,
+	"GB":  1 << 30,
+	"GIB": 1 << 30,
+}
+
+// parseBandwidth parses a rate like 50MB/s into bytes per second, zero stands for unlimited
+func parseBandwidth(value string) (int64, error) {
+	rate, err := parseSize(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(value)), "/S"))
+	if err != nil {
+		return 0, errors.New("invalid bandwidth: " + value)
+	}
+	return rate, nil
+}
+
+// parseSize parses a size like 64MB or 1GB into bytes
+func parseSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	index := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if index < 0 {
+		index = len(value)
+	}
+	number, err := strconv.ParseFloat(value[:index], 64)
+	if err != nil {
+		return 0, err
+	}
+	unit, found := sizeUnits[strings.TrimSpace(value[index:])]
+	if !found || number < 0 {
+		return 0, errors.New("invalid size: " + value)
+	}
+	return int64(number * unit), nil
+}
+
+// getBandwidthLimiter returns the limiter for --max-bandwidth, or for the configured default when not set,
+// returns nil when the transfer is unlimited
+func getBandwidthLimiter(c *cli.Context, cosContext *utils.CosContext) (*bandwidthLimiter, error) {
+	var rate int64
+	var err error
+	if c.IsSet(flags.MaxBandwidth) {
+		if rate, err = parseBandwidth(c.String(flags.MaxBandwidth)); err != nil {
+			return nil, &cerrors.CommandError{
+				CLIContext: c,
+				Cause:      cerrors.InvalidValue,
+				Flag:       flags.MaxBandwidth,
+				IError:     err,
+			}
+		}
+	} else {
+		var value string
+		if value, err = cosContext.Config.GetStringWithDefault(config.MaxBandwidth, ""); err != nil {
+			return nil, err
+		}
+		if value != "" {
+			if rate, err = parseBandwidth(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if rate <= 0 {
+		return nil, nil
+	}
+	return newBandwidthLimiter(rate), nil
+}
+
+// newLimitedTracker throttles the transfer of one of the objects of a multi-object command
+// to the limiter shared by all of them. The tracked parts are not larger than the parts
+// of the transfer, so the bytes of parts transferred concurrently are counted as they come.
+func newLimitedTracker(size int64, limiter *bandwidthLimiter) *transferProgress {
+	tracker := newTransferTracker(size, limiter)
+	tracker.setSize(size, s3manager.MinUploadPartSize)
+	return tracker
+}
+
+// bandwidthLimiter is a token bucket shared by all the parts of a transfer,
+// refilled at the maximum rate and holding up to one second of transfer
+type bandwidthLimiter struct {
+	lock   sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newBandwidthLimiter(bytesPerSecond int64) *bandwidthLimiter {
+	return &bandwidthLimiter{
+		rate:   float64(bytesPerSecond),
+		tokens: float64(bytesPerSecond),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until n bytes can be transferred without exceeding the rate.
+// Tokens are reserved before waiting, so concurrent parts are served in turn.
+func (limiter *bandwidthLimiter) wait(n int64) {
+	if limiter == nil || n <= 0 {
+		return
+	}
+	limiter.lock.Lock()
+	now := time.Now()
+	limiter.tokens += now.Sub(limiter.last).Seconds() * limiter.rate
+	if limiter.tokens > limiter.rate {
+		limiter.tokens = limiter.rate
+	}
+	limiter.last = now
+	limiter.tokens -= float64(n)
+	var delay time.Duration
+	if limiter.tokens < 0 {
+		delay = time.Duration(-limiter.tokens / limiter.rate * float64(time.Second))
+	}
+	limiter.lock.Unlock()
+	time.Sleep(delay)
+}
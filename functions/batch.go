@@ -0,0 +1,425 @@
+package functions
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
+	"github.com/IBM/ibmcloud-cos-cli/config/fields"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/render"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// Operations of a batch manifest
+const (
+	batchPut    = "put"
+	batchCopy   = "copy"
+	batchDelete = "delete"
+	batchTag    = "tag"
+)
+
+// batchOperationKey is the manifest field naming the operation of a line,
+// the other fields are the flags of the matching command
+const batchOperationKey = "op"
+
+// batchResultsSuffix replaces the extension of the manifest to name the default results file
+const batchResultsSuffix = ".results.jsonl"
+
+// maxBatchLine is the size of the longest manifest line
+const maxBatchLine = 1024 * 1024
+
+// batchOperation maps the flags of a manifest line to the input of an SDK call,
+// as the matching command does
+type batchOperation struct {
+	newInput  func() interface{}
+	mandatory map[string]string
+	options   map[string]string
+	call      func(client s3iface.S3API, input interface{}) error
+}
+
+// batchOperations are the operations a manifest line can run
+var batchOperations = map[string]*batchOperation{
+	batchPut: {
+		newInput: func() interface{} { return new(s3.PutObjectInput) },
+		mandatory: map[string]string{
+			fields.Bucket: flags.Bucket,
+			fields.Key:    flags.Key,
+		},
+		options: map[string]string{
+			fields.CacheControl:            flags.CacheControl,
+			fields.ContentDisposition:      flags.ContentDisposition,
+			fields.ContentEncoding:         flags.ContentEncoding,
+			fields.ContentLanguage:         flags.ContentLanguage,
+			fields.ContentLength:           flags.ContentLength,
+			fields.ContentMD5:              flags.ContentMD5,
+			fields.ContentType:             flags.ContentType,
+			fields.Metadata:                flags.Metadata,
+			fields.Body:                    flags.Body,
+			fields.Tagging:                 flags.Tagging,
+			fields.WebsiteRedirectLocation: flags.WebsiteRedirectLocation,
+		},
+		call: func(client s3iface.S3API, input interface{}) error {
+			putInput := input.(*s3.PutObjectInput)
+			if closeAble, ok := putInput.Body.(io.Closer); ok {
+				defer closeAble.Close()
+			}
+			_, err := client.PutObject(putInput)
+			return err
+		},
+	},
+	batchCopy: {
+		newInput: func() interface{} { return new(s3.CopyObjectInput) },
+		mandatory: map[string]string{
+			fields.Bucket:     flags.Bucket,
+			fields.Key:        flags.Key,
+			fields.CopySource: flags.CopySource,
+		},
+		options: map[string]string{
+			fields.CacheControl:                flags.CacheControl,
+			fields.ContentDisposition:          flags.ContentDisposition,
+			fields.ContentEncoding:             flags.ContentEncoding,
+			fields.ContentLanguage:             flags.ContentLanguage,
+			fields.ContentType:                 flags.ContentType,
+			fields.CopySourceIfMatch:           flags.CopySourceIfMatch,
+			fields.CopySourceIfModifiedSince:   flags.CopySourceIfModifiedSince,
+			fields.CopySourceIfNoneMatch:       flags.CopySourceIfNoneMatch,
+			fields.CopySourceIfUnmodifiedSince: flags.CopySourceIfUnmodifiedSince,
+			fields.Metadata:                    flags.Metadata,
+			fields.MetadataDirective:           flags.MetadataDirective,
+			fields.Tagging:                     flags.Tagging,
+			fields.TaggingDirective:            flags.TaggingDirective,
+			fields.WebsiteRedirectLocation:     flags.WebsiteRedirectLocation,
+		},
+		call: func(client s3iface.S3API, input interface{}) error {
+			_, err := client.CopyObject(input.(*s3.CopyObjectInput))
+			return err
+		},
+	},
+	batchDelete: {
+		newInput: func() interface{} { return new(s3.DeleteObjectInput) },
+		mandatory: map[string]string{
+			fields.Bucket: flags.Bucket,
+			fields.Key:    flags.Key,
+		},
+		options: map[string]string{
+			fields.VersionId: flags.VersionId,
+		},
+		call: func(client s3iface.S3API, input interface{}) error {
+			_, err := client.DeleteObject(input.(*s3.DeleteObjectInput))
+			return err
+		},
+	},
+	batchTag: {
+		newInput: func() interface{} { return new(s3.PutObjectTaggingInput) },
+		mandatory: map[string]string{
+			fields.Bucket:  flags.Bucket,
+			fields.Key:     flags.Key,
+			fields.Tagging: flags.Tagging,
+		},
+		options: map[string]string{
+			fields.VersionId: flags.VersionId,
+		},
+		call: func(client s3iface.S3API, input interface{}) error {
+			_, err := client.PutObjectTagging(input.(*s3.PutObjectTaggingInput))
+			return err
+		},
+	},
+}
+
+// batchLine is a line of the manifest and its result
+type batchLine struct {
+	number int
+	raw    string
+	result *render.BatchResult
+}
+
+// Batch runs the operations of a manifest, one JSON object per line, with a pool of workers.
+// The result of every line is written to the results file, the lines that failed can be
+// written to a file to run them again.
+// Parameter:
+//
+//	CLI Context Application
+//
+// Returns:
+//
+//	Error = zero or non-zero
+func Batch(c *cli.Context) (err error) {
+	// check the number of arguments
+	if c.NArg() > 0 {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidNArg,
+		}
+		return
+	}
+
+	// Load COS Context
+	var cosContext *utils.CosContext
+	if cosContext, err = GetCosContext(c); err != nil {
+		return
+	}
+
+	if !c.IsSet(flags.Manifest) {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.MissingRequiredFlag,
+			Flag:       flags.Manifest,
+		}
+		return
+	}
+	manifest := c.String(flags.Manifest)
+	results := c.String(flags.Results)
+	if results == "" {
+		results = strings.TrimSuffix(manifest, filepath.Ext(manifest)) + batchResultsSuffix
+	}
+
+	// Number of operations to run in parallel
+	var jobs int
+	if jobs, err = parseJobs(c); err != nil {
+		return
+	}
+
+	// Read the manifest
+	var lines []*batchLine
+	if lines, err = readManifest(cosContext, manifest); err != nil {
+		return
+	}
+
+	// Run the lines, the errors are recorded in their results
+	runner := &batchRunner{c: c, cosContext: cosContext, clients: make(map[string]s3iface.S3API)}
+	queue := make(chan *batchLine)
+	go func() {
+		defer close(queue)
+		for _, line := range lines {
+			queue <- line
+		}
+	}()
+	var wg sync.WaitGroup
+	for worker := 0; worker < jobs; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for line := range queue {
+				if err := runner.run(line); err != nil {
+					line.result.Status = render.BatchFailed
+					line.result.Error = err.Error()
+				} else {
+					line.result.Status = render.BatchSucceeded
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Write the results, and the lines to run again
+	if err = writeBatchResults(cosContext, results, lines); err != nil {
+		return
+	}
+	if c.IsSet(flags.FailuresFile) {
+		if err = writeBatchFailures(cosContext, c.String(flags.FailuresFile), lines); err != nil {
+			return
+		}
+	}
+
+	// Output the summary
+	output := &render.BatchOutput{
+		Manifest: manifest,
+		Results:  results,
+		Total:    len(lines),
+	}
+	for _, line := range lines {
+		if line.result.Status == render.BatchFailed {
+			output.Failed++
+			output.Failures = append(output.Failures, line.result)
+		} else {
+			output.Succeeded++
+		}
+	}
+	if err = cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(nil, output, nil); err != nil {
+		return
+	}
+
+	if output.Failed > 0 {
+		err = awserr.New("BatchIncomplete",
+			fmt.Sprintf("some operations have failed: %d", output.Failed), nil)
+	}
+
+	// Return
+	return
+}
+
+// readManifest reads the non empty lines of the manifest
+func readManifest(cosContext *utils.CosContext, location string) ([]*batchLine, error) {
+	file, err := cosContext.ReadSeekerCloserOpen(location)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	lines := make([]*batchLine, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxBatchLine)
+	for number := 1; scanner.Scan(); number++ {
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+		lines = append(lines, &batchLine{
+			number: number,
+			raw:    raw,
+			result: &render.BatchResult{Line: number},
+		})
+	}
+	return lines, scanner.Err()
+}
+
+// batchRunner runs the lines of a manifest, sharing a client per region
+type batchRunner struct {
+	c          *cli.Context
+	cosContext *utils.CosContext
+	lock       sync.Mutex
+	clients    map[string]s3iface.S3API
+}
+
+// client returns the client of the region, the region of the command when empty
+func (runner *batchRunner) client(region string) (s3iface.S3API, error) {
+	if region == "" {
+		region = runner.c.String(flags.Region)
+	}
+	runner.lock.Lock()
+	defer runner.lock.Unlock()
+	if client, found := runner.clients[region]; found {
+		return client, nil
+	}
+	client, err := runner.cosContext.GetClient(region)
+	if err != nil {
+		return nil, err
+	}
+	runner.clients[region] = client
+	return client, nil
+}
+
+// run parses a line into the flags of its operation, maps them to the input of the operation
+// and calls the service
+func (runner *batchRunner) run(line *batchLine) error {
+	var values map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(line.raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return err
+	}
+	name, _ := values[batchOperationKey].(string)
+	line.result.Operation = name
+	operation, found := batchOperations[name]
+	if !found {
+		return fmt.Errorf("unknown operation '%s'", name)
+	}
+
+	// The fields of the line are the flags of the operation
+	set := flag.NewFlagSet(name, flag.ContinueOnError)
+	allowed := map[string]bool{flags.Region: true}
+	for _, flagName := range operation.mandatory {
+		allowed[flagName] = true
+	}
+	for _, flagName := range operation.options {
+		allowed[flagName] = true
+	}
+	for _, flagName := range sortedFlagNames(values) {
+		if !allowed[flagName] {
+			return fmt.Errorf("unknown field '%s' for operation '%s'", flagName, name)
+		}
+		value, err := flagValue(values[flagName])
+		if err != nil {
+			return err
+		}
+		set.String(flagName, "", "")
+		if err = set.Set(flagName, value); err != nil {
+			return err
+		}
+	}
+	lineContext := cli.NewContext(runner.c.App, set, runner.c)
+	line.result.Bucket = lineContext.String(flags.Bucket)
+	line.result.Key = lineContext.String(flags.Key)
+
+	input := operation.newInput()
+	if err := MapToSDKInput(lineContext, input, operation.mandatory, operation.options); err != nil {
+		return err
+	}
+	client, err := runner.client(lineContext.String(flags.Region))
+	if err != nil {
+		return err
+	}
+	return operation.call(client, input)
+}
+
+// sortedFlagNames returns the fields of a line but the operation, in lexical order
+func sortedFlagNames(values map[string]interface{}) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		if name != batchOperationKey {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// flagValue formats the value of a field as it would be given on the command line,
+// objects and arrays are given as JSON
+func flagValue(value interface{}) (string, error) {
+	switch typed := value.(type) {
+	case string:
+		return typed, nil
+	case json.Number:
+		return typed.String(), nil
+	case bool:
+		return fmt.Sprint(typed), nil
+	}
+	content, err := json.Marshal(value)
+	return string(content), err
+}
+
+// writeBatchResults writes the result of every line, one JSON object per line
+func writeBatchResults(cosContext *utils.CosContext, location string, lines []*batchLine) error {
+	file, err := cosContext.WriteCloserOpen(location)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	for _, line := range lines {
+		if err = encoder.Encode(line.result); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+// writeBatchFailures writes the manifest lines that failed, as they were read
+func writeBatchFailures(cosContext *utils.CosContext, location string, lines []*batchLine) error {
+	file, err := cosContext.WriteCloserOpen(location)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if line.result.Status != render.BatchFailed {
+			continue
+		}
+		if _, err = file.Write([]byte(line.raw + "\n")); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
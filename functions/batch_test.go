@@ -0,0 +1,231 @@
+//+build unit
+
+package functions_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibmcloud-cos-cli/config"
+	"github.com/IBM/ibmcloud-cos-cli/config/commands"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/cos"
+	"github.com/IBM/ibmcloud-cos-cli/di/providers"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+)
+
+func TestBatchRunsManifestAndWritesResults(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	manifest := strings.Join([]string{
+		`{"op": "put", "bucket": "b", "key": "k1", "body": "data.txt", "metadata": {"owner": "me"}}`,
+		`{"op": "copy", "bucket": "b", "key": "k2", "copy-source": "b/k1"}`,
+		``,
+		`{"op": "delete", "bucket": "b", "key": "gone"}`,
+		`{"op": "tag", "bucket": "b", "key": "k1", "tagging": {"TagSet": [{"Key": "team", "Value": "cos"}]}}`,
+		`{"op": "rename", "bucket": "b", "key": "k1"}`,
+	}, "\n")
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "ops.jsonl").
+		Return(utils.WrapString(manifest, nil), nil).
+		Once()
+
+	bodyClosed := false
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "data.txt").
+		Return(utils.WrapString("content", &bodyClosed), nil).
+		Once()
+
+	results := ""
+	providers.MockFileOperations.
+		On("WriteCloserOpen", "ops.results.jsonl").
+		Return(utils.WriteToString(&results, nil), nil).
+		Once()
+	failures := ""
+	providers.MockFileOperations.
+		On("WriteCloserOpen", "retry.jsonl").
+		Return(utils.WriteToString(&failures, nil), nil).
+		Once()
+
+	var putInput *s3.PutObjectInput
+	providers.MockS3API.
+		On("PutObject", mock.MatchedBy(func(input *s3.PutObjectInput) bool {
+			putInput = input
+			return true
+		})).
+		Return(new(s3.PutObjectOutput), nil).
+		Once()
+	providers.MockS3API.
+		On("CopyObject", mock.MatchedBy(func(input *s3.CopyObjectInput) bool {
+			return aws.StringValue(input.CopySource) == "b/k1" && aws.StringValue(input.Key) == "k2"
+		})).
+		Return(new(s3.CopyObjectOutput), nil).
+		Once()
+	providers.MockS3API.
+		On("DeleteObject", mock.Anything).
+		Return(nil, errors.New("AccessDenied")).
+		Once()
+	providers.MockS3API.
+		On("PutObjectTagging", mock.MatchedBy(func(input *s3.PutObjectTaggingInput) bool {
+			return len(input.Tagging.TagSet) == 1 && aws.StringValue(input.Tagging.TagSet[0].Key) == "team"
+		})).
+		Return(new(s3.PutObjectTaggingOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Batch,
+		"--" + flags.Manifest, "ops.jsonl",
+		"--" + flags.FailuresFile, "retry.jsonl",
+		"--" + flags.Jobs, "3",
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero since two lines failed
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertExpectations(t)
+	assert.Equal(t, "k1", aws.StringValue(putInput.Key))
+	assert.Equal(t, "me", aws.StringValue(putInput.Metadata["owner"]))
+	assert.True(t, bodyClosed)
+
+	// results are written in manifest order, numbered after the lines of the file
+	lines := strings.Split(strings.TrimSpace(results), "\n")
+	assert.Len(t, lines, 5)
+	assert.Contains(t, lines[0], `"Line":1,"Operation":"put"`)
+	assert.Contains(t, lines[0], `"Status":"succeeded"`)
+	assert.Contains(t, lines[1], `"Status":"succeeded"`)
+	assert.Contains(t, lines[2], `"Line":4,"Operation":"delete"`)
+	assert.Contains(t, lines[2], `"Status":"failed","Error":"AccessDenied"`)
+	assert.Contains(t, lines[3], `"Status":"succeeded"`)
+	assert.Contains(t, lines[4], `"Line":6,"Operation":"rename"`)
+	assert.Contains(t, lines[4], "unknown operation")
+
+	// the failed lines can be fed back as a manifest
+	assert.Equal(t, `{"op": "delete", "bucket": "b", "key": "gone"}`+"\n"+
+		`{"op": "rename", "bucket": "b", "key": "k1"}`+"\n", failures)
+
+	output := providers.FakeUI.Outputs()
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, output, "Ran 3 of 5 operation(s) from 'ops.jsonl', results in 'ops.results.jsonl'.")
+	assert.Contains(t, output, "AccessDenied")
+	assert.Contains(t, errors, "FAIL")
+}
+
+func TestBatchRejectsUnknownFields(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "ops.jsonl").
+		Return(utils.WrapString(`{"op": "delete", "bucket": "b", "key": "k", "body": "x"}`, nil), nil).
+		Once()
+	results := ""
+	providers.MockFileOperations.
+		On("WriteCloserOpen", "out.jsonl").
+		Return(utils.WriteToString(&results, nil), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Batch,
+		"--" + flags.Manifest, "ops.jsonl",
+		"--" + flags.Results, "out.jsonl",
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero and no call was made
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "DeleteObject", mock.Anything)
+	assert.Contains(t, results, "unknown field 'body' for operation 'delete'")
+}
+
+func TestBatchKeepsRetryFlagsOfTheCommand(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// the bodies of the lines are read from files
+	manifest := strings.Join([]string{
+		`{"op": "put", "bucket": "b", "key": "k1", "body": "data.txt"}`,
+		`{"op": "put", "bucket": "b", "key": "k2", "body": "data.txt"}`,
+		`{"op": "put", "bucket": "b", "key": "k3", "body": "data.txt"}`,
+	}, "\n")
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "ops.jsonl").
+		Return(utils.WrapString(manifest, nil), nil).
+		Once()
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "data.txt").
+		Return(func(string) utils.ReadSeekerCloser { return utils.WrapString("content", nil) }, nil).
+		Times(3)
+	results := ""
+	providers.MockFileOperations.
+		On("WriteCloserOpen", "out.jsonl").
+		Return(utils.WriteToString(&results, nil), nil).
+		Once()
+
+	providers.MockS3API.
+		On("PutObject", mock.AnythingOfType("*s3.PutObjectInput")).
+		Return(new(s3.PutObjectOutput), nil).
+		Times(3)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Batch,
+		"--" + flags.Manifest, "ops.jsonl",
+		"--" + flags.Results, "out.jsonl",
+		"--" + flags.MaxRetries, "7",
+		"--" + flags.Debug,
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero and the lines used the retries of the command
+	assert.Equal(t, (*int)(nil), exitCode)
+	providers.MockS3API.AssertExpectations(t)
+	sessions := providers.ClientSessions()
+	assert.NotEmpty(t, sessions)
+	for _, sess := range sessions {
+		if retryer, ok := sess.Config.Retryer.(*utils.Retryer); assert.True(t, ok) {
+			assert.Equal(t, 7, retryer.NumMaxRetries)
+		}
+	}
+}
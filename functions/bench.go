@@ -0,0 +1,371 @@
+package functions
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/render"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// Defaults of the benchmark matrix
+const (
+	benchDefaultSize         = "64MB"
+	benchDefaultObjects      = 3
+	benchDefaultPartSizes    = "5MB,16MB,64MB"
+	benchDefaultConcurrences = "1,5,10"
+)
+
+// benchKeyPrefix starts the keys of the objects written by a benchmark, followed by its start time
+const benchKeyPrefix = "cos-bench-"
+
+// Bench uploads and downloads synthetic objects with the Uploader and the Downloader
+// for every part size and concurrency of the matrix, reports their throughput and latency
+// and deletes the objects.
+// Parameter:
+//
+//	CLI Context Application
+//
+// Returns:
+//
+//	Error = zero or non-zero
+func Bench(c *cli.Context) (err error) {
+	// check the number of arguments
+	if c.NArg() > 0 {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidNArg,
+		}
+		return
+	}
+
+	// Load COS Context
+	var cosContext *utils.CosContext
+	if cosContext, err = GetCosContext(c); err != nil {
+		return
+	}
+
+	if !c.IsSet(flags.Bucket) {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.MissingRequiredFlag,
+			Flag:       flags.Bucket,
+		}
+		return
+	}
+
+	var plan *benchPlan
+	if plan, err = getBenchPlan(c); err != nil {
+		return
+	}
+
+	var client s3iface.S3API
+	if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+		return
+	}
+
+	output := &render.BenchOutput{
+		Bucket:  c.String(flags.Bucket),
+		Size:    plan.size,
+		Objects: plan.objects,
+	}
+	prefix := benchKeyPrefix + strconv.FormatInt(time.Now().UnixNano(), 10) + "/"
+
+	// the objects of the cell being measured, deleted also when the benchmark fails
+	written := make([]*render.TransferItem, 0, plan.objects)
+	defer func() {
+		if cleanupErr := cleanupBench(client, output.Bucket, written); err == nil {
+			err = cleanupErr
+		}
+	}()
+
+	for _, partSize := range plan.partSizes {
+		for _, concurrency := range plan.concurrences {
+			// the objects of a cell are under its own prefix
+			cell := fmt.Sprintf("%s%d-%d/", prefix, partSize, concurrency)
+			keys := make([]string, plan.objects)
+			for index := range keys {
+				keys[index] = cell + strconv.Itoa(index)
+			}
+
+			var upload *render.BenchResult
+			if upload, err = benchUpload(c, cosContext, output.Bucket, keys, plan.size, partSize, concurrency,
+				&written); err != nil {
+				return
+			}
+			var download *render.BenchResult
+			if download, err = benchDownload(c, cosContext, output.Bucket, keys, plan.size, partSize,
+				concurrency); err != nil {
+				return
+			}
+			output.Results = append(output.Results, upload, download)
+
+			if err = cleanupBench(client, output.Bucket, written); err != nil {
+				return
+			}
+			written = written[:0]
+		}
+	}
+	output.RecommendedUpload = recommendBench(output.Results, render.ActionUpload)
+	output.RecommendedDownload = recommendBench(output.Results, render.ActionDownload)
+
+	// Display either in JSON or text
+	err = cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(nil, output, nil)
+	return
+}
+
+// benchPlan is the matrix of a benchmark
+type benchPlan struct {
+	size         int64
+	objects      int
+	partSizes    []int64
+	concurrences []int
+}
+
+// getBenchPlan reads the size and number of the objects and the matrix of part sizes and concurrencies
+func getBenchPlan(c *cli.Context) (*benchPlan, error) {
+	invalid := func(flag string, err error) error {
+		return &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flag,
+			IError:     err,
+		}
+	}
+	plan := &benchPlan{objects: benchDefaultObjects}
+
+	size := benchDefaultSize
+	if c.IsSet(flags.Size) {
+		size = c.String(flags.Size)
+	}
+	var err error
+	if plan.size, err = parseSize(size); err != nil || plan.size <= 0 {
+		return nil, invalid(flags.Size, fmt.Errorf("invalid size: %s", size))
+	}
+
+	if c.IsSet(flags.Objects) {
+		if plan.objects, err = strconv.Atoi(c.String(flags.Objects)); err != nil || plan.objects <= 0 {
+			return nil, invalid(flags.Objects, fmt.Errorf("invalid number of objects: %s", c.String(flags.Objects)))
+		}
+	}
+
+	partSizes := benchDefaultPartSizes
+	if c.IsSet(flags.PartSize) {
+		partSizes = c.String(flags.PartSize)
+	}
+	for _, value := range strings.Split(partSizes, ",") {
+		partSize, err := parseSize(value)
+		if err != nil || partSize < s3manager.MinUploadPartSize {
+			return nil, invalid(flags.PartSize, fmt.Errorf("invalid part size: %s, the minimum is 5MB", value))
+		}
+		plan.partSizes = append(plan.partSizes, partSize)
+	}
+
+	concurrences := benchDefaultConcurrences
+	if c.IsSet(flags.Concurrency) {
+		concurrences = c.String(flags.Concurrency)
+	}
+	for _, value := range strings.Split(concurrences, ",") {
+		concurrency, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || concurrency <= 0 {
+			return nil, invalid(flags.Concurrency, fmt.Errorf("invalid concurrency: %s", value))
+		}
+		plan.concurrences = append(plan.concurrences, concurrency)
+	}
+	return plan, nil
+}
+
+// benchUpload uploads a synthetic object to every key with the Uploader, one after the other,
+// the keys written are added to the objects to clean up
+func benchUpload(c *cli.Context, cosContext *utils.CosContext, bucket string, keys []string, size, partSize int64,
+	concurrency int, written *[]*render.TransferItem) (*render.BenchResult, error) {
+	uploader, err := cosContext.GetUploader(c.String(flags.Region), func(u *s3manager.Uploader) {
+		u.PartSize = partSize
+		u.Concurrency = concurrency
+	})
+	if err != nil {
+		return nil, err
+	}
+	latencies := make([]time.Duration, 0, len(keys))
+	start := time.Now()
+	for _, key := range keys {
+		began := time.Now()
+		*written = append(*written, &render.TransferItem{Action: render.ActionDelete, Key: key, Size: size})
+		if _, err = uploader.Upload(&s3manager.UploadInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+			Body:   newBenchBody(size),
+		}); err != nil {
+			return nil, err
+		}
+		latencies = append(latencies, time.Since(began))
+	}
+	return newBenchResult(render.ActionUpload, partSize, concurrency, size*int64(len(keys)), time.Since(start),
+		latencies), nil
+}
+
+// benchDownload downloads every key with the Downloader, one after the other, discarding the content
+func benchDownload(c *cli.Context, cosContext *utils.CosContext, bucket string, keys []string, size, partSize int64,
+	concurrency int) (*render.BenchResult, error) {
+	downloader, err := cosContext.GetDownloader(c.String(flags.Region), func(d *s3manager.Downloader) {
+		d.PartSize = partSize
+		d.Concurrency = concurrency
+	})
+	if err != nil {
+		return nil, err
+	}
+	latencies := make([]time.Duration, 0, len(keys))
+	start := time.Now()
+	for _, key := range keys {
+		began := time.Now()
+		n, err := downloader.Download(discardWriterAt{}, &s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if n != size {
+			return nil, fmt.Errorf("downloaded %d bytes of '%s' instead of %d", n, key, size)
+		}
+		latencies = append(latencies, time.Since(began))
+	}
+	return newBenchResult(render.ActionDownload, partSize, concurrency, size*int64(len(keys)), time.Since(start),
+		latencies), nil
+}
+
+// newBenchResult computes the throughput of the bytes transferred in the elapsed time
+// and the percentiles of the latencies of the objects
+func newBenchResult(operation string, partSize int64, concurrency int, bytes int64, elapsed time.Duration,
+	latencies []time.Duration) *render.BenchResult {
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	result := &render.BenchResult{
+		Operation:   operation,
+		PartSize:    partSize,
+		Concurrency: concurrency,
+		LatencyP50:  percentile(latencies, 50).Seconds(),
+		LatencyP90:  percentile(latencies, 90).Seconds(),
+		LatencyP99:  percentile(latencies, 99).Seconds(),
+	}
+	if elapsed > 0 {
+		result.Throughput = int64(float64(bytes) / elapsed.Seconds())
+	}
+	return result
+}
+
+// percentile returns the nearest rank percentile of the sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// recommendBench returns the setting of the highest throughput of the operation,
+// the first one of the matrix on a tie
+func recommendBench(results []*render.BenchResult, operation string) *render.BenchResult {
+	var best *render.BenchResult
+	for _, result := range results {
+		if result.Operation == operation && (best == nil || result.Throughput > best.Throughput) {
+			best = result
+		}
+	}
+	return best
+}
+
+// cleanupBench deletes the objects written by the benchmark,
+// an object which cannot be deleted fails the benchmark
+func cleanupBench(client s3iface.S3API, bucket string, written []*render.TransferItem) error {
+	if len(written) == 0 {
+		return nil
+	}
+	if err := deleteObjectKeys(client, &s3.DeleteObjectsInput{Bucket: aws.String(bucket)}, written); err != nil {
+		return err
+	}
+	for _, item := range written {
+		if item.Error != "" {
+			return fmt.Errorf("cannot delete '%s': %s", item.Key, item.Error)
+		}
+	}
+	return nil
+}
+
+// discardWriterAt discards the content downloaded
+type discardWriterAt struct{}
+
+func (discardWriterAt) WriteAt(p []byte, _ int64) (int, error) {
+	return len(p), nil
+}
+
+// benchPattern is the pseudo-random content repeated in the synthetic objects, not compressible
+var (
+	benchPattern     []byte
+	benchPatternOnce sync.Once
+)
+
+// benchBody is a synthetic object of a size, read without being held in memory.
+// As a ReaderAt the Uploader reads its parts concurrently without buffering them.
+type benchBody struct {
+	size   int64
+	offset int64
+}
+
+func newBenchBody(size int64) *benchBody {
+	benchPatternOnce.Do(func() {
+		benchPattern = make([]byte, 1<<20)
+		rand.New(rand.NewSource(1)).Read(benchPattern)
+	})
+	return &benchBody{size: size}
+}
+
+func (b *benchBody) ReadAt(p []byte, off int64) (n int, err error) {
+	if off >= b.size {
+		return 0, io.EOF
+	}
+	if remaining := b.size - off; int64(len(p)) > remaining {
+		p = p[:remaining]
+		err = io.EOF
+	}
+	for n < len(p) {
+		n += copy(p[n:], benchPattern[(off+int64(n))%int64(len(benchPattern)):])
+	}
+	return n, err
+}
+
+func (b *benchBody) Read(p []byte) (int, error) {
+	n, err := b.ReadAt(p, b.offset)
+	b.offset += int64(n)
+	return n, err
+}
+
+func (b *benchBody) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += b.offset
+	case io.SeekEnd:
+		offset += b.size
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position: %d", offset)
+	}
+	b.offset = offset
+	return offset, nil
+}
@@ -0,0 +1,157 @@
+//+build unit
+
+package functions_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config"
+	"github.com/IBM/ibmcloud-cos-cli/config/commands"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/cos"
+	"github.com/IBM/ibmcloud-cos-cli/di/providers"
+	"github.com/IBM/ibmcloud-cos-cli/render"
+)
+
+func TestBenchMeasuresMatrixAndCleansUp(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "BenchBucket"
+	size := int64(6 << 20)
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	uploaded := make([]string, 0)
+	providers.MockUploaderAPI.
+		On("Upload", mock.MatchedBy(func(input *s3manager.UploadInput) bool {
+			// the synthetic object is read in full
+			n, err := io.Copy(ioutil.Discard, input.Body)
+			uploaded = append(uploaded, aws.StringValue(input.Key))
+			return err == nil && n == size && aws.StringValue(input.Bucket) == targetBucket
+		})).
+		Return(new(s3manager.UploadOutput), nil)
+
+	providers.MockDownloaderAPI.
+		On("Download", mock.Anything, mock.AnythingOfType("*s3.GetObjectInput")).
+		Return(size, nil)
+
+	deleted := make([]string, 0)
+	providers.MockS3API.
+		On("DeleteObjects", mock.MatchedBy(func(input *s3.DeleteObjectsInput) bool {
+			for _, object := range input.Delete.Objects {
+				deleted = append(deleted, aws.StringValue(object.Key))
+			}
+			return true
+		})).
+		Return(new(s3.DeleteObjectsOutput), nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-",
+		commands.Bench,
+		"--" + flags.Bucket, targetBucket,
+		"--" + flags.Size, "6MB",
+		"--" + flags.Objects, "2",
+		"--" + flags.PartSize, "5MB,6MB",
+		"--" + flags.Concurrency, "1,3",
+		"--" + flags.Region, "REG",
+		"--" + flags.Output, "json",
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode)
+	providers.MockUploaderAPI.AssertNumberOfCalls(t, "Upload", 8)
+	providers.MockDownloaderAPI.AssertNumberOfCalls(t, "Download", 8)
+	// every cell is cleaned up after it is measured
+	providers.MockS3API.AssertNumberOfCalls(t, "DeleteObjects", 4)
+	assert.ElementsMatch(t, uploaded, deleted)
+	for _, key := range uploaded {
+		assert.True(t, strings.HasPrefix(key, "cos-bench-"), key)
+	}
+	// the last cell of the matrix
+	assert.Equal(t, int64(6<<20), providers.ReferenceUploader.PartSize)
+	assert.Equal(t, 3, providers.ReferenceUploader.Concurrency)
+	assert.Equal(t, int64(6<<20), providers.ReferenceDownloader.PartSize)
+
+	output := new(render.BenchOutput)
+	assert.NoError(t, json.Unmarshal([]byte(providers.FakeUI.Outputs()), output))
+	assert.Equal(t, size, output.Size)
+	assert.Equal(t, 2, output.Objects)
+	assert.Len(t, output.Results, 8)
+	if assert.NotNil(t, output.RecommendedUpload) && assert.NotNil(t, output.RecommendedDownload) {
+		assert.Equal(t, render.ActionUpload, output.RecommendedUpload.Operation)
+		assert.Equal(t, render.ActionDownload, output.RecommendedDownload.Operation)
+	}
+}
+
+func TestBenchCleansUpWhenDownloadFails(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockUploaderAPI.
+		On("Upload", mock.AnythingOfType("*s3manager.UploadInput")).
+		Return(new(s3manager.UploadOutput), nil)
+
+	providers.MockDownloaderAPI.
+		On("Download", mock.Anything, mock.AnythingOfType("*s3.GetObjectInput")).
+		Return(int64(0), errors.New("connection reset by peer"))
+
+	deleted := 0
+	providers.MockS3API.
+		On("DeleteObjects", mock.MatchedBy(func(input *s3.DeleteObjectsInput) bool {
+			deleted += len(input.Delete.Objects)
+			return true
+		})).
+		Return(new(s3.DeleteObjectsOutput), nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-",
+		commands.Bench,
+		"--" + flags.Bucket, "BenchBucket",
+		"--" + flags.Size, "1MB",
+		"--" + flags.Objects, "3",
+		"--" + flags.PartSize, "5MB",
+		"--" + flags.Concurrency, "2",
+		"--" + flags.Region, "REG",
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, 1, *exitCode)
+	providers.MockDownloaderAPI.AssertNumberOfCalls(t, "Download", 1)
+	assert.Equal(t, 3, deleted)
+	assert.Contains(t, providers.FakeUI.Errors(), "connection reset by peer")
+}
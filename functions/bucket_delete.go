@@ -1,7 +1,11 @@
 package functions
 
 import (
+	"fmt"
+
 	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/bluemix/terminal"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
 	"github.com/IBM/ibmcloud-cos-cli/config/fields"
@@ -12,6 +16,13 @@ import (
 	"github.com/urfave/cli"
 )
 
+// errCodeBucketNotEmpty is the error of the deletion of a bucket which still holds
+// objects, versions, delete markers or incomplete multipart uploads
+const errCodeBucketNotEmpty = "BucketNotEmpty"
+
+// errCodeBucketEmptyIncomplete is the error of a forced delete which could not empty the bucket
+const errCodeBucketEmptyIncomplete = "BucketEmptyIncomplete"
+
 // BucketDelete deletes a bucket from a user's account. Requires the user to provide the region of the bucket, if it's
 // not provided then get DefaultRegion from credentials.json
 // Parameter:
@@ -50,6 +61,27 @@ func BucketDelete(c *cli.Context) (err error) {
 		return
 	}
 
+	// The name confirming the emptying of the bucket has to be the one of the bucket
+	if c.IsSet(flags.ConfirmBucket) {
+		if !c.Bool(flags.Empty) {
+			err = &errors.CommandError{
+				CLIContext: c,
+				Cause:      errors.MissingRequiredFlag,
+				Flag:       flags.Empty,
+			}
+			return
+		}
+		if c.String(flags.ConfirmBucket) != aws.StringValue(input.Bucket) {
+			err = &errors.CommandError{
+				CLIContext: c,
+				Cause:      errors.InvalidValue,
+				Flag:       flags.ConfirmBucket,
+				IError:     fmt.Errorf("--%s is not the name of the bucket", flags.ConfirmBucket),
+			}
+			return
+		}
+	}
+
 	// No force on deleting object, alert users
 	if !c.Bool(flags.Force) {
 		confirmed := false
@@ -71,6 +103,10 @@ func BucketDelete(c *cli.Context) (err error) {
 	// DeleteBucket Op
 	var output *s3.DeleteBucketOutput
 	if output, err = client.DeleteBucket(input); err != nil {
+		// A bucket which is not empty is emptied when asked to, once its name is confirmed
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == errCodeBucketNotEmpty && c.Bool(flags.Empty) {
+			err = emptyAndDeleteBucket(c, cosContext, client, input)
+		}
 		return
 	}
 
@@ -80,3 +116,118 @@ func BucketDelete(c *cli.Context) (err error) {
 	// Return
 	return
 }
+
+// emptyAndDeleteBucket deletes every object version and delete marker of the bucket and aborts
+// every incomplete multipart upload, then deletes the bucket. The versions protected by object lock
+// (retention or legal hold) are not deleted: they are reported and the bucket is kept.
+func emptyAndDeleteBucket(c *cli.Context, cosContext *utils.CosContext, client s3iface.S3API,
+	input *s3.DeleteBucketInput) (err error) {
+	bucket := aws.StringValue(input.Bucket)
+
+	// Emptying a bucket cannot be undone, the name of the bucket must be typed even with --force,
+	// unless it was confirmed with --confirm-bucket
+	if !c.IsSet(flags.ConfirmBucket) {
+		name := ""
+		cosContext.UI.Warn(render.WarningEmptyBucket(bucket))
+		cosContext.UI.Prompt(render.MessageConfirmBucketName(), &terminal.PromptOptions{}).Resolve(&name)
+		if name != bucket {
+			cosContext.UI.Say(render.MessageOperationCanceled())
+			return
+		}
+	}
+
+	output := &render.BucketEmptyOutput{
+		Bucket: bucket,
+		Failed: make([]*render.TransferItem, 0),
+	}
+
+	// Every page of versions and delete markers is deleted in a batch
+	var deleteErr error
+	err = client.ListObjectVersionsPages(&s3.ListObjectVersionsInput{Bucket: input.Bucket},
+		func(page *s3.ListObjectVersionsOutput, _ bool) bool {
+			batch := make([]*render.TransferItem, 0, len(page.Versions)+len(page.DeleteMarkers))
+			for _, version := range page.Versions {
+				batch = append(batch, &render.TransferItem{
+					Action:    render.ActionDelete,
+					Key:       aws.StringValue(version.Key),
+					VersionId: aws.StringValue(version.VersionId),
+					Size:      aws.Int64Value(version.Size),
+				})
+			}
+			versions := len(batch)
+			for _, marker := range page.DeleteMarkers {
+				batch = append(batch, &render.TransferItem{
+					Action:    render.ActionDelete,
+					Key:       aws.StringValue(marker.Key),
+					VersionId: aws.StringValue(marker.VersionId),
+				})
+			}
+			if deleteErr = deleteObjectKeys(client, &s3.DeleteObjectsInput{Bucket: aws.String(bucket)}, batch); deleteErr != nil {
+				return false
+			}
+			for index, item := range batch {
+				switch {
+				case item.Error != "":
+					output.Failed = append(output.Failed, item)
+				case index < versions:
+					output.DeletedVersions++
+				default:
+					output.DeletedMarkers++
+				}
+			}
+			return true
+		})
+	if err == nil {
+		err = deleteErr
+	}
+	if err != nil {
+		return
+	}
+
+	// Abort the incomplete multipart uploads
+	err = client.ListMultipartUploadsPages(&s3.ListMultipartUploadsInput{Bucket: input.Bucket},
+		func(page *s3.ListMultipartUploadsOutput, _ bool) bool {
+			for _, upload := range page.Uploads {
+				item := &render.TransferItem{
+					Action:   render.ActionAbort,
+					Key:      aws.StringValue(upload.Key),
+					UploadId: aws.StringValue(upload.UploadId),
+				}
+				if _, abortErr := client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+					Bucket:   input.Bucket,
+					Key:      upload.Key,
+					UploadId: upload.UploadId,
+				}); abortErr != nil {
+					item.Error = abortErr.Error()
+					output.Failed = append(output.Failed, item)
+					continue
+				}
+				output.AbortedUploads++
+			}
+			return true
+		})
+	if err != nil {
+		return
+	}
+
+	// The bucket is deleted only once it is empty
+	if len(output.Failed) == 0 {
+		if _, err = client.DeleteBucket(input); err != nil {
+			return
+		}
+		output.BucketDeleted = true
+	}
+
+	// Display either in JSON or text
+	if err = cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input, output, nil); err != nil {
+		return
+	}
+
+	if len(output.Failed) > 0 {
+		err = awserr.New(errCodeBucketEmptyIncomplete,
+			fmt.Sprintf("%d object version(s) or upload(s) could not be removed", len(output.Failed)), nil)
+	}
+
+	// Return
+	return
+}
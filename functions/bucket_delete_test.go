@@ -11,9 +11,12 @@ import (
 	"github.com/urfave/cli"
 
 	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3"
 	"github.com/IBM/ibmcloud-cos-cli/config"
 	"github.com/IBM/ibmcloud-cos-cli/config/commands"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
 	"github.com/IBM/ibmcloud-cos-cli/cos"
 	"github.com/IBM/ibmcloud-cos-cli/di/providers"
 )
@@ -156,3 +159,295 @@ func TestBucketDeleteWithoutBucket(t *testing.T) {
 	assert.Contains(t, errors, "FAIL")
 
 }
+
+func TestBucketDeleteEmptyEmptiesVersionedBucket(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TARGETBUCKET"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockS3API.
+		On("DeleteBucket", mock.AnythingOfType("*s3.DeleteBucketInput")).
+		Return(nil, awserr.New("BucketNotEmpty", "The bucket you tried to delete is not empty.", nil)).
+		Once()
+	providers.MockS3API.
+		On("DeleteBucket", mock.AnythingOfType("*s3.DeleteBucketInput")).
+		Return(new(s3.DeleteBucketOutput), nil).
+		Once()
+
+	providers.MockS3API.
+		On("ListObjectVersionsPages", mock.AnythingOfType("*s3.ListObjectVersionsInput"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListObjectVersionsOutput, last bool) bool)
+			pager(&s3.ListObjectVersionsOutput{
+				Versions: []*s3.ObjectVersion{
+					{Key: aws.String("a"), VersionId: aws.String("v1"), Size: aws.Int64(10)},
+					{Key: aws.String("a"), VersionId: aws.String("v2"), Size: aws.Int64(10)},
+				},
+			}, false)
+			pager(&s3.ListObjectVersionsOutput{
+				DeleteMarkers: []*s3.DeleteMarkerEntry{
+					{Key: aws.String("a"), VersionId: aws.String("v3")},
+				},
+			}, true)
+		}).
+		Return(nil)
+
+	deleted := make([]string, 0)
+	providers.MockS3API.
+		On("DeleteObjects", mock.MatchedBy(func(input *s3.DeleteObjectsInput) bool {
+			for _, object := range input.Delete.Objects {
+				deleted = append(deleted, aws.StringValue(object.Key)+"@"+aws.StringValue(object.VersionId))
+			}
+			return aws.StringValue(input.Bucket) == targetBucket
+		})).
+		Return(new(s3.DeleteObjectsOutput), nil)
+
+	providers.MockS3API.
+		On("ListMultipartUploadsPages", mock.AnythingOfType("*s3.ListMultipartUploadsInput"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListMultipartUploadsOutput, last bool) bool)
+			pager(&s3.ListMultipartUploadsOutput{Uploads: []*s3.MultipartUpload{
+				{Key: aws.String("big"), UploadId: aws.String("u1")},
+			}}, true)
+		}).
+		Return(nil)
+
+	providers.MockS3API.
+		On("AbortMultipartUpload", mock.MatchedBy(func(input *s3.AbortMultipartUploadInput) bool {
+			return aws.StringValue(input.Key) == "big" && aws.StringValue(input.UploadId) == "u1"
+		})).
+		Return(new(s3.AbortMultipartUploadOutput), nil).
+		Once()
+
+	providers.FakeUI.Inputs("y", targetBucket)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.BucketDelete,
+		"--" + flags.Bucket, targetBucket,
+		"--" + flags.Region, "REG",
+		"--" + flags.Empty,
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode)
+	providers.MockS3API.AssertNumberOfCalls(t, "DeleteBucket", 2)
+	providers.MockS3API.AssertNumberOfCalls(t, "DeleteObjects", 2)
+	providers.MockS3API.AssertNumberOfCalls(t, "AbortMultipartUpload", 1)
+	assert.Equal(t, []string{"a@v1", "a@v2", "a@v3"}, deleted)
+	assert.Contains(t, providers.FakeUI.Errors(), "is not empty")
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "Deleted 2 object version(s) and 1 delete marker(s), aborted 1 multipart upload(s)")
+	assert.Contains(t, output, "Successfully deleted bucket")
+}
+
+func TestBucketDeleteEmptyConfirmedReportsLockedVersions(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TARGETBUCKET"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockS3API.
+		On("DeleteBucket", mock.AnythingOfType("*s3.DeleteBucketInput")).
+		Return(nil, awserr.New("BucketNotEmpty", "The bucket you tried to delete is not empty.", nil))
+
+	providers.MockS3API.
+		On("ListObjectVersionsPages", mock.AnythingOfType("*s3.ListObjectVersionsInput"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListObjectVersionsOutput, last bool) bool)
+			pager(&s3.ListObjectVersionsOutput{Versions: []*s3.ObjectVersion{
+				{Key: aws.String("free"), VersionId: aws.String("v1"), Size: aws.Int64(10)},
+				{Key: aws.String("locked"), VersionId: aws.String("v2"), Size: aws.Int64(10)},
+			}}, true)
+		}).
+		Return(nil)
+
+	providers.MockS3API.
+		On("DeleteObjects", mock.AnythingOfType("*s3.DeleteObjectsInput")).
+		Return(&s3.DeleteObjectsOutput{Errors: []*s3.Error{{
+			Key:       aws.String("locked"),
+			VersionId: aws.String("v2"),
+			Code:      aws.String("AccessDenied"),
+			Message:   aws.String("Object is protected by a retention period"),
+		}}}, nil)
+
+	providers.MockS3API.
+		On("ListMultipartUploadsPages", mock.AnythingOfType("*s3.ListMultipartUploadsInput"), mock.Anything).
+		Return(nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.BucketDelete,
+		"--" + flags.Bucket, targetBucket,
+		"--" + flags.Region, "REG",
+		"--" + flags.Empty,
+		"--" + flags.ConfirmBucket, targetBucket,
+		"--" + flags.Force,
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, 1, *exitCode)
+	// the bucket is kept since a version could not be deleted
+	providers.MockS3API.AssertNumberOfCalls(t, "DeleteBucket", 1)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "locked")
+	assert.Contains(t, output, "AccessDenied: Object is protected by a retention period")
+	assert.Contains(t, output, "1 item(s) could not be removed")
+	assert.Contains(t, providers.FakeUI.Errors(), "object lock retention or legal hold may protect them")
+}
+
+func TestBucketDeleteEmptyCanceledOnWrongName(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockS3API.
+		On("DeleteBucket", mock.AnythingOfType("*s3.DeleteBucketInput")).
+		Return(nil, awserr.New("BucketNotEmpty", "The bucket you tried to delete is not empty.", nil))
+
+	providers.FakeUI.Inputs("y", "OTHERBUCKET")
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.BucketDelete,
+		"--" + flags.Bucket, "TARGETBUCKET",
+		"--" + flags.Region, "REG",
+		"--" + flags.Empty,
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode)
+	providers.MockS3API.AssertNotCalled(t, "ListObjectVersionsPages", mock.Anything, mock.Anything)
+	providers.MockS3API.AssertNotCalled(t, "DeleteObjects", mock.Anything)
+	assert.Contains(t, providers.FakeUI.Outputs(), "Operation canceled.")
+}
+
+func TestBucketDeleteEmptyForceStillAsksBucketName(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockS3API.
+		On("DeleteBucket", mock.AnythingOfType("*s3.DeleteBucketInput")).
+		Return(nil, awserr.New("BucketNotEmpty", "The bucket you tried to delete is not empty.", nil))
+
+	// --force skips the first prompt only
+	providers.FakeUI.Inputs("OTHERBUCKET")
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.BucketDelete,
+		"--" + flags.Bucket, "TARGETBUCKET",
+		"--" + flags.Region, "REG",
+		"--" + flags.Empty,
+		"--" + flags.Force,
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode)
+	providers.MockS3API.AssertNotCalled(t, "ListObjectVersionsPages", mock.Anything, mock.Anything)
+	providers.MockS3API.AssertNotCalled(t, "DeleteObjects", mock.Anything)
+	assert.Contains(t, providers.FakeUI.Outputs(), "Operation canceled.")
+}
+
+func TestBucketDeleteConfirmBucketOfAnotherBucket(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.BucketDelete,
+		"--" + flags.Bucket, "TARGETBUCKET",
+		"--" + flags.Region, "REG",
+		"--" + flags.Empty,
+		"--" + flags.ConfirmBucket, "OTHERBUCKET",
+		"--" + flags.Force,
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "DeleteBucket", mock.Anything)
+	assert.Contains(t, providers.FakeUI.Errors(), "The value in flag '--"+flags.ConfirmBucket+"' is invalid")
+}
+
+func TestBucketDeleteForceKeepsBucketNotEmpty(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockS3API.
+		On("DeleteBucket", mock.AnythingOfType("*s3.DeleteBucketInput")).
+		Return(nil, awserr.New("BucketNotEmpty", "The bucket you tried to delete is not empty.", nil))
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.BucketDelete,
+		"--" + flags.Bucket, "TARGETBUCKET",
+		"--" + flags.Region, "REG",
+		"--" + flags.Force,
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// without --empty the bucket is only deleted when it is empty
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "ListObjectVersionsPages", mock.Anything, mock.Anything)
+	providers.MockS3API.AssertNotCalled(t, "DeleteObjects", mock.Anything)
+}
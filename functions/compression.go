@@ -0,0 +1,175 @@
+package functions
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/klauspost/compress/zstd"
+	"github.com/urfave/cli"
+)
+
+// Algorithms an object can be compressed with, they are also the Content-Encoding of the object
+const (
+	compressionGzip = "gzip"
+	compressionZstd = "zstd"
+)
+
+// metadataOriginalSize records the size of an object before it was compressed
+const metadataOriginalSize = "original-size"
+
+// getCompression returns the algorithm the body of an upload is compressed with,
+// empty when compression is not asked for. The flags that cannot be combined with compression are rejected.
+func getCompression(c *cli.Context, conflicting ...string) (string, error) {
+	if !c.IsSet(flags.Compress) {
+		return "", nil
+	}
+	algorithm := strings.ToLower(c.String(flags.Compress))
+	if algorithm != compressionGzip && algorithm != compressionZstd {
+		return "", &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.Compress,
+			IError:     fmt.Errorf("unsupported compression '%s', use %s or %s", algorithm, compressionGzip, compressionZstd),
+		}
+	}
+	for _, flag := range conflicting {
+		if c.IsSet(flag) {
+			return "", &errors.CommandError{
+				CLIContext: c,
+				Cause:      errors.InvalidValue,
+				Flag:       flag,
+				IError:     fmt.Errorf("--%s cannot be used with --%s", flag, flags.Compress),
+			}
+		}
+	}
+	return algorithm, nil
+}
+
+// validateDecompress rejects the flags that cannot be combined with decompression,
+// a compressed stream is only read from its beginning
+func validateDecompress(c *cli.Context) error {
+	if !c.Bool(flags.Decompress) {
+		return nil
+	}
+	for _, flag := range []string{flags.Range, flags.Resume} {
+		if c.IsSet(flag) {
+			return &errors.CommandError{
+				CLIContext: c,
+				Cause:      errors.InvalidValue,
+				Flag:       flag,
+				IError:     fmt.Errorf("--%s cannot be used with --%s", flag, flags.Decompress),
+			}
+		}
+	}
+	return nil
+}
+
+// compressBody returns a reader of the body compressed on the fly, and the metadata recording
+// the original size of the body when it can be measured. Closing the reader stops the compression.
+func compressBody(algorithm string, metadata map[string]*string, body io.Reader) (map[string]*string, io.ReadCloser, error) {
+	if seeker, ok := body.(io.Seeker); ok {
+		size, err := aws.SeekerLen(seeker)
+		if err != nil {
+			return nil, nil, err
+		}
+		if metadata == nil {
+			metadata = make(map[string]*string, 1)
+		}
+		metadata[metadataOriginalSize] = aws.String(strconv.FormatInt(size, 10))
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		compressor, err := newCompressor(algorithm, writer)
+		if err == nil {
+			if body != nil {
+				_, err = io.Copy(compressor, body)
+			}
+			if closeErr := compressor.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		writer.CloseWithError(err)
+	}()
+	return metadata, reader, nil
+}
+
+// newCompressor returns a writer compressing to w with the algorithm
+func newCompressor(algorithm string, w io.Writer) (io.WriteCloser, error) {
+	if algorithm == compressionZstd {
+		return zstd.NewWriter(w)
+	}
+	return gzip.NewWriter(w), nil
+}
+
+// compressionOf returns the algorithm of a Content-Encoding this command decompresses,
+// empty when the content is not compressed with one of them
+func compressionOf(contentEncoding *string) string {
+	encodings := strings.Split(aws.StringValue(contentEncoding), ",")
+	switch encoding := strings.ToLower(strings.TrimSpace(encodings[len(encodings)-1])); encoding {
+	case compressionGzip, "x-gzip":
+		return compressionGzip
+	case compressionZstd:
+		return compressionZstd
+	}
+	return ""
+}
+
+// newDecompressor returns a reader decompressing r with the algorithm
+func newDecompressor(algorithm string, r io.Reader) (io.ReadCloser, error) {
+	if algorithm == compressionZstd {
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return gzip.NewReader(r)
+}
+
+// decompressingWriter decompresses the content written to it into the underlying writer,
+// the content is decompressed by a goroutine reading from a pipe
+type decompressingWriter struct {
+	pipe    *io.PipeWriter
+	done    chan error
+	written int64
+}
+
+// newDecompressingWriter starts decompressing into w
+func newDecompressingWriter(w io.Writer, algorithm string) *decompressingWriter {
+	reader, writer := io.Pipe()
+	decompressing := &decompressingWriter{pipe: writer, done: make(chan error, 1)}
+	go func() {
+		decompressor, err := newDecompressor(algorithm, reader)
+		if err == nil {
+			decompressing.written, err = io.Copy(w, decompressor)
+			decompressor.Close()
+		}
+		// stops the writes when the content cannot be decompressed
+		reader.CloseWithError(err)
+		decompressing.done <- err
+	}()
+	return decompressing
+}
+
+func (w *decompressingWriter) Write(p []byte) (int, error) {
+	return w.pipe.Write(p)
+}
+
+// Close waits for the end of the decompression, the error of the content written when it is not valid
+func (w *decompressingWriter) Close() error {
+	w.pipe.Close()
+	return <-w.done
+}
+
+// abort stops the decompression when the content cannot be written in full
+func (w *decompressingWriter) abort(err error) {
+	w.pipe.CloseWithError(err)
+	<-w.done
+}
@@ -54,6 +54,36 @@ var (
 		Display: T("Download Location"),
 	}
 
+	configOptionMaxBandwidth = ConfigOption{
+		Key:     config.MaxBandwidth,
+		Display: T("Max Bandwidth"),
+	}
+
+	configOptionMaxRetries = ConfigOption{
+		Key:     config.MaxRetries,
+		Display: T("Max Retries"),
+	}
+
+	configOptionRetryBaseDelay = ConfigOption{
+		Key:     config.RetryBaseDelay,
+		Display: T("Retry Base Delay"),
+	}
+
+	configOptionRetryMaxDelay = ConfigOption{
+		Key:     config.RetryMaxDelay,
+		Display: T("Retry Max Delay"),
+	}
+
+	configOptionRetryStatusCodes = ConfigOption{
+		Key:     config.RetryStatusCodes,
+		Display: T("Retry Status Codes"),
+	}
+
+	configOptionContentTypes = ConfigOption{
+		Key:     config.ContentTypes,
+		Display: T("Content Types"),
+	}
+
 	configOptionDefaultCRN = ConfigOption{
 		Key:     config.CRN,
 		Display: T("CRN"),
@@ -115,6 +145,15 @@ func ConfigList(c *cli.Context) error {
 		configOptionDefaultRegion,
 		// default download location row
 		configOptionDefaultDownloadLocation,
+		// default max bandwidth row
+		configOptionMaxBandwidth,
+		// retries rows
+		configOptionMaxRetries,
+		configOptionRetryBaseDelay,
+		configOptionRetryMaxDelay,
+		configOptionRetryStatusCodes,
+		// content types row
+		configOptionContentTypes,
 		// default crn row
 		configOptionDefaultCRN,
 		// hmac key row
@@ -253,13 +292,19 @@ func buildTable(ui terminal.UI, pc plugin.PluginConfig, fields []ConfigOption) t
 
 func GetConfigOption(commandName string) (*ConfigOption, error) {
 	options := map[string]*ConfigOption{
-		"auth":         &configOptionAuthenticationMethod,
-		flags.CRN:      &configOptionDefaultCRN,
-		flags.DDL:      &configOptionDefaultDownloadLocation,
-		"endpoint-url": &configOptionServiceEndpoint,
-		"hmac":         &configOptionHMACKey,
-		flags.Region:   &configOptionDefaultRegion,
-		"url-style":    &configOptionURLStyle,
+		"auth":                 &configOptionAuthenticationMethod,
+		"content-types":        &configOptionContentTypes,
+		flags.CRN:              &configOptionDefaultCRN,
+		flags.DDL:              &configOptionDefaultDownloadLocation,
+		"endpoint-url":         &configOptionServiceEndpoint,
+		"hmac":                 &configOptionHMACKey,
+		flags.MaxBandwidth:     &configOptionMaxBandwidth,
+		flags.MaxRetries:       &configOptionMaxRetries,
+		flags.Region:           &configOptionDefaultRegion,
+		flags.RetryBaseDelay:   &configOptionRetryBaseDelay,
+		flags.RetryMaxDelay:    &configOptionRetryMaxDelay,
+		flags.RetryStatusCodes: &configOptionRetryStatusCodes,
+		"url-style":            &configOptionURLStyle,
 	}
 
 	if commandName != "" {
@@ -316,6 +361,26 @@ func ConfigSet(c *cli.Context) (err error) {
 		if v, err = urlStyleToBool(value); err != nil {
 			return errors.New(T("invalid URL Style, use valid style like VHost, Path etc"))
 		}
+	case &configOptionMaxBandwidth:
+		if _, err = parseBandwidth(value); err != nil {
+			return errors.New(T("invalid bandwidth, use a rate like 500KB/s, 50MB/s or 1GB/s"))
+		}
+	case &configOptionContentTypes:
+		if _, err = parseContentTypes(value); err != nil {
+			return errors.New(T("invalid content types, use a list of mappings like .md=text/markdown,.avro=application/avro"))
+		}
+	case &configOptionMaxRetries:
+		if err = new(utils.Retryer).SetMaxRetries(value); err != nil {
+			return errors.New(T("invalid number of retries, use a number like 0, 3 or 10"))
+		}
+	case &configOptionRetryBaseDelay, &configOptionRetryMaxDelay:
+		if _, err = utils.ParseRetryDelay(value); err != nil {
+			return errors.New(T("invalid delay, use a duration like 100ms, 2s or 1m"))
+		}
+	case &configOptionRetryStatusCodes:
+		if _, err = utils.ParseRetryStatusCodes(value); err != nil {
+			return errors.New(T("invalid status codes, use a list of HTTP status codes like 500,503"))
+		}
 	}
 
 	if !done {
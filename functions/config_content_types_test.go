@@ -0,0 +1,74 @@
+//+build unit
+
+package functions_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibmcloud-cos-cli/config"
+	"github.com/IBM/ibmcloud-cos-cli/config/commands"
+	"github.com/IBM/ibmcloud-cos-cli/cos"
+	"github.com/IBM/ibmcloud-cos-cli/di/providers"
+)
+
+func TestContentTypesSet(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.
+		MockPluginConfig.
+		On("Set", config.ContentTypes, ".md=text/markdown,.avro=application/avro").
+		Return(nil)
+
+	providers.
+		MockPluginConfig.
+		On("Set", config.LastUpdated, mock.AnythingOfType("string")).
+		Return(nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Config, commands.Set, "content-types", ".md=text/markdown,.avro=application/avro"}
+
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode)
+	providers.MockPluginConfig.AssertExpectations(t)
+	assert.Contains(t, providers.FakeUI.Outputs(), "OK")
+}
+
+func TestContentTypesSetInvalidMapping(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Config, commands.Set, "content-types", ".md"}
+
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, 1, *exitCode)
+	providers.MockPluginConfig.AssertNotCalled(t, "Set", config.ContentTypes, mock.Anything)
+	assert.Contains(t, providers.FakeUI.Errors(), "invalid content types")
+}
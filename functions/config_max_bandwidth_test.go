@@ -0,0 +1,75 @@
+//+build unit
+
+package functions_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibmcloud-cos-cli/config"
+	"github.com/IBM/ibmcloud-cos-cli/config/commands"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/cos"
+	"github.com/IBM/ibmcloud-cos-cli/di/providers"
+)
+
+func TestMaxBandwidthSet(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.
+		MockPluginConfig.
+		On("Set", config.MaxBandwidth, "50MB/s").
+		Return(nil)
+
+	providers.
+		MockPluginConfig.
+		On("Set", config.LastUpdated, mock.AnythingOfType("string")).
+		Return(nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Config, commands.Set, flags.MaxBandwidth, "50MB/s"}
+
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode)
+	providers.MockPluginConfig.AssertExpectations(t)
+	assert.Contains(t, providers.FakeUI.Outputs(), "OK")
+}
+
+func TestMaxBandwidthSetInvalidRate(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Config, commands.Set, flags.MaxBandwidth, "fast"}
+
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, 1, *exitCode)
+	providers.MockPluginConfig.AssertNotCalled(t, "Set", config.MaxBandwidth, mock.Anything)
+	assert.Contains(t, providers.FakeUI.Errors(), "invalid bandwidth")
+}
@@ -0,0 +1,121 @@
+package functions
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibmcloud-cos-cli/config"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+)
+
+// sniffLength is the number of bytes content sniffing looks at
+const sniffLength = 512
+
+// sniffFallback is the type content sniffing returns when it recognizes nothing,
+// the object is left without a Content-Type rather than given this one
+const sniffFallback = "application/octet-stream"
+
+// contentTypeDetector finds the Content-Type of a file from its extension,
+// looking at the mapping of the config before the system one, or from its first bytes
+type contentTypeDetector struct {
+	mapping map[string]string
+}
+
+// newContentTypeDetector loads the extension mapping of the config
+func newContentTypeDetector(cosContext *utils.CosContext) (*contentTypeDetector, error) {
+	value, err := cosContext.Config.GetStringWithDefault(config.ContentTypes, "")
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := parseContentTypes(value)
+	if err != nil {
+		return nil, err
+	}
+	return &contentTypeDetector{mapping: mapping}, nil
+}
+
+// parseContentTypes parses a list of mappings such as .md=text/markdown,.avro=application/avro
+func parseContentTypes(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		extension := strings.ToLower(strings.TrimSpace(parts[0]))
+		if len(parts) != 2 || strings.Trim(extension, ".") == "" {
+			return nil, fmt.Errorf("invalid content type mapping: '%s'", entry)
+		}
+		contentType := strings.TrimSpace(parts[1])
+		if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			return nil, fmt.Errorf("invalid content type mapping: '%s'", entry)
+		}
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+		mapping[extension] = contentType
+	}
+	return mapping, nil
+}
+
+// byExtension returns the Content-Type of a file name, or an empty string when its extension is unknown
+func (detector *contentTypeDetector) byExtension(name string) string {
+	extension := strings.ToLower(filepath.Ext(name))
+	if extension == "" {
+		return ""
+	}
+	if contentType, found := detector.mapping[extension]; found {
+		return contentType
+	}
+	return mime.TypeByExtension(extension)
+}
+
+// detect returns the Content-Type of the body read from the named file, with its extension first
+// and content sniffing as a fallback. Sniffing reads the first bytes of the body, a body that cannot
+// seek back is replaced by a reader that still returns them.
+func (detector *contentTypeDetector) detect(name string, body io.Reader) (*string, io.Reader, error) {
+	if contentType := detector.byExtension(name); contentType != "" {
+		return aws.String(contentType), body, nil
+	}
+	if body == nil {
+		return nil, body, nil
+	}
+
+	var head []byte
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, body, err
+		}
+		buffer := make([]byte, sniffLength)
+		read, err := io.ReadFull(seeker, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, body, err
+		}
+		if _, err = seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, body, err
+		}
+		head = buffer[:read]
+	} else {
+		buffered := bufio.NewReaderSize(body, sniffLength)
+		peeked, err := buffered.Peek(sniffLength)
+		if err != nil && err != io.EOF {
+			return nil, body, err
+		}
+		head, body = peeked, buffered
+	}
+
+	if len(head) == 0 {
+		return nil, body, nil
+	}
+	if contentType := http.DetectContentType(head); contentType != sniffFallback {
+		return aws.String(contentType), body, nil
+	}
+	return nil, body, nil
+}
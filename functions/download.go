@@ -1,8 +1,15 @@
 package functions
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/bluemix/terminal"
 	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
 	"github.com/IBM/ibmcloud-cos-cli/config/fields"
 	"github.com/IBM/ibmcloud-cos-cli/config/flags"
@@ -22,6 +29,11 @@ import (
 //	Error = zero or non-zero
 func Download(c *cli.Context) (err error) {
 
+	// An archive is extracted into the directory given by --extract
+	if err = validateExtract(c); err != nil {
+		return
+	}
+
 	// check the number of arguments
 	if c.NArg() > 1 {
 		err = &errors.CommandError{
@@ -31,6 +43,21 @@ func Download(c *cli.Context) (err error) {
 		return
 	}
 
+	// Download every object under a prefix, or matching a wildcard key
+	// unless an object has the exact key
+	if c.Bool(flags.Recursive) {
+		return downloadPrefix(c)
+	}
+	if isKeyPattern(c.String(flags.Key)) {
+		var exact bool
+		if exact, err = keyExists(c); err != nil {
+			return
+		}
+		if !exact {
+			return downloadPrefix(c)
+		}
+	}
+
 	// Load COS Context
 	var cosContext *utils.CosContext
 	if cosContext, err = GetCosContext(c); err != nil {
@@ -43,9 +70,9 @@ func Download(c *cli.Context) (err error) {
 	// Download location
 	var dstPath string
 
-	// In case of error removes incomplete downloads
+	// In case of error removes incomplete downloads, unless kept to resume later
 	defer func() {
-		if !keepFile && dstPath != "" {
+		if !keepFile && dstPath != "" && !c.Bool(flags.Resume) {
 			cosContext.Remove(dstPath)
 		}
 	}()
@@ -73,6 +100,8 @@ func Download(c *cli.Context) (err error) {
 		fields.ResponseContentLanguage:    flags.ResponseContentLanguage,
 		fields.ResponseContentType:        flags.ResponseContentType,
 		fields.ResponseExpires:            flags.ResponseExpires,
+		fields.SSECustomerAlgorithm:       flags.SSECustomerAlgorithm,
+		fields.SSECustomerKey:             flags.SSECustomerKey,
 	}
 
 	//
@@ -81,16 +110,62 @@ func Download(c *cli.Context) (err error) {
 		return
 	}
 
+	// A resumed download sets its own range
+	if err = validateResume(c); err != nil {
+		return
+	}
+
+	// A compressed object is only decompressed from its beginning
+	if err = validateDecompress(c); err != nil {
+		return
+	}
+
+	// The object is decrypted on the client when a master key is given
+	var masterKey []byte
+	if masterKey, err = getMasterKey(c, cosContext, flags.Resume); err != nil {
+		return
+	}
+	decode := masterKey != nil || c.Bool(flags.Decompress)
+
+	// The archive of the object is extracted while it is downloaded in order
+	if c.IsSet(flags.Extract) {
+		return downloadExtracted(c, cosContext, input, masterKey)
+	}
+
+	// The object is written in order to the standard output when the destination is -
+	if c.Args().First() == stdStream {
+		if err = validateStdout(c); err != nil {
+			return
+		}
+		if decode {
+			_, err = downloadDecoded(c, cosContext, input, masterKey, cosContext.Stdout(), false)
+			return
+		}
+		return downloadToStdout(c, cosContext, input)
+	}
+
 	//
 	// Validate Download Location
 	var file utils.WriteCloser
 	if dstPath, file, err = getAndValidateDownloadPath(cosContext, c.Args().First(),
-		aws.StringValue(input.Key), c.IsSet(flags.Force)); err != nil || file == nil {
+		aws.StringValue(input.Key), c.IsSet(flags.Force), c.Bool(flags.Resume)); err != nil || file == nil {
 		return
 	}
 
-	// Defer closing file
-	defer file.Close()
+	// Defer closing file, a resumed download may replace it
+	defer func() { file.Close() }()
+
+	// An object encrypted on the client or compressed is decoded while it is written
+	if decode {
+		var output *s3.GetObjectOutput
+		if output, err = downloadDecoded(c, cosContext, input, masterKey, file, true); err != nil {
+			return
+		}
+		keepFile = true
+		err = cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input,
+			&render.DownloadOutput{TotalBytes: aws.Int64Value(output.ContentLength)}, nil)
+		return
+	}
 
 	// Options for Downloader
 	downloadOptions := map[string]string{
@@ -100,10 +175,21 @@ func Download(c *cli.Context) (err error) {
 	// Error holder
 	errorHolder := new(error)
 
+	// Limit the bandwidth of the transfer
+	var limiter *bandwidthLimiter
+	if limiter, err = getBandwidthLimiter(c, cosContext); err != nil {
+		return
+	}
+
+	// Report the progress of the download while the file is written,
+	// the size of the object is known once the first part is received
+	progress := newTransferProgress(c, cosContext, aws.StringValue(input.Key), 0, limiter)
+	defer progress.finish()
+
 	// Build a s3manager download
 	var downloader utils.Downloader
 	if downloader, err = cosContext.GetDownloader(c.String(flags.Region),
-		applyConfigDownloader(c, downloadOptions, errorHolder)); err != nil {
+		applyConfigDownloader(c, downloadOptions, errorHolder), progress.downloaderOption); err != nil {
 		return
 	}
 	// Downloader Error Checking
@@ -111,14 +197,45 @@ func Download(c *cli.Context) (err error) {
 		return *errorHolder
 	}
 
+	// A resumed download continues after the bytes already written to the destination
+	var resumable *resumableDownload
+	if c.Bool(flags.Resume) {
+		var client s3iface.S3API
+		if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+			return
+		}
+		resumable = newResumableDownload(cosContext, client, input, dstPath, file)
+	}
+
 	// Download Op
-	var totalBytes int64
-	if totalBytes, err = downloader.Download(file, input); err != nil {
+	var offset, totalBytes int64
+	for {
+		if resumable != nil {
+			if offset, file, err = resumable.prepare(); err != nil {
+				return
+			}
+		}
+		totalBytes, err = downloader.Download(&progressWriter{WriteCloser: file, tracker: progress}, input)
+		// The object changed since the download started, start it again
+		if resumable != nil && resumable.restart(err) {
+			continue
+		}
+		break
+	}
+	if err != nil {
+		// Keep the progress for the next resumed download
+		if resumable != nil {
+			resumable.interrupt()
+		}
 		return
 	}
+	totalBytes += offset
 
 	// Maintain to keep file
 	keepFile = true
+	if resumable != nil {
+		resumable.finish()
+	}
 
 	// Render DownloadOutput
 	output := &render.DownloadOutput{
@@ -131,6 +248,221 @@ func Download(c *cli.Context) (err error) {
 	return
 }
 
+// keyExists tells whether the bucket holds an object of the exact key, a key with wildcard
+// characters such as a[1].txt is downloaded alone when it exists
+func keyExists(c *cli.Context) (bool, error) {
+	// The missing flags are reported by the download of the matching objects
+	if !c.IsSet(flags.Bucket) {
+		return false, nil
+	}
+	cosContext, err := GetCosContext(c)
+	if err != nil {
+		return false, err
+	}
+	client, err := cosContext.GetClient(c.String(flags.Region))
+	if err != nil {
+		return false, err
+	}
+	_, err = client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(c.String(flags.Bucket)),
+		Key:    aws.String(c.String(flags.Key)),
+	})
+	// an object which cannot be read without its encryption key or permissions still exists
+	if failure, ok := err.(awserr.RequestFailure); ok {
+		return failure.StatusCode() != http.StatusNotFound, nil
+	}
+	return err == nil, err
+}
+
+// downloadPrefix - lists the objects under --prefix, or matching the wildcard --key, and downloads them
+// into the destination directory, recreating the key hierarchy, with several S3 Manager Downloader
+// batch iterators running in parallel. The --include and --exclude patterns select the keys to download,
+// --dry-run only displays them.
+// Failures are collected per object and reported once every object has been processed.
+func downloadPrefix(c *cli.Context) (err error) {
+
+	// Load COS Context
+	var cosContext *utils.CosContext
+	if cosContext, err = GetCosContext(c); err != nil {
+		return
+	}
+
+	// Build ListObjectsV2Input
+	input := new(s3.ListObjectsV2Input)
+
+	// Required parameters for ListObjectsV2Input
+	mandatory := map[string]string{
+		fields.Bucket: flags.Bucket,
+	}
+
+	//
+	// Optional parameters for ListObjectsV2Input
+	options := map[string]string{
+		fields.Prefix: flags.Prefix,
+	}
+
+	//
+	// Check through user inputs for validation
+	if err = MapToSDKInput(c, input, mandatory, options); err != nil {
+		return
+	}
+
+	// Extraction, client side decryption, decompression and resume apply to a single object
+	for _, flag := range []string{flags.Extract, flags.EncryptionKeyFile, flags.Decompress, flags.Resume} {
+		if c.IsSet(flag) {
+			err = &errors.CommandError{
+				CLIContext: c,
+				Cause:      errors.InvalidValue,
+				Flag:       flag,
+				IError:     fmt.Errorf("--%s cannot be used to download several objects", flag),
+			}
+			return
+		}
+	}
+
+	// Keys selected by the patterns, the keys matching a wildcard key are relative to its directory
+	var filter *keyFilter
+	relativeTo := aws.StringValue(input.Prefix)
+	if pattern := c.String(flags.Key); isKeyPattern(pattern) {
+		if c.IsSet(flags.Prefix) {
+			err = &errors.CommandError{
+				CLIContext: c,
+				Cause:      errors.InvalidValue,
+				Flag:       flags.Prefix,
+				IError:     fmt.Errorf("--%s cannot be used with a wildcard --%s", flags.Prefix, flags.Key),
+			}
+			return
+		}
+		var prefix string
+		if filter, prefix, err = newKeyPatternFilter(c); err != nil {
+			return
+		}
+		input.Prefix = aws.String(prefix)
+		relativeTo = globDirectory(pattern)
+	} else if filter, err = newKeyFilter(c); err != nil {
+		return
+	}
+
+	// Number of objects to download in parallel
+	var jobs int
+	if jobs, err = parseJobs(c); err != nil {
+		return
+	}
+
+	// Destination directory, defaults to the configured download location
+	dstDir := c.Args().First()
+	if dstDir == "" {
+		if dstDir, err = cosContext.GetDownloadLocation(); err != nil {
+			return
+		}
+	}
+
+	// Setting client to do the call
+	var client s3iface.S3API
+	if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+		return
+	}
+
+	// List all the pages under the prefix
+	var objects []*s3.Object
+	if objects, err = listObjectsWithPrefix(client, input); err != nil {
+		return
+	}
+
+	// Map the keys to local paths, skipping folder markers
+	objects = filter.filterObjects(objects, relativeTo)
+	items := make([]*render.TransferItem, 0, len(objects))
+	existing := 0
+	for _, object := range objects {
+		path := localPathForKey(dstDir, relativeTo, aws.StringValue(object.Key))
+		if path == "" {
+			continue
+		}
+		if _, errInfo := cosContext.GetFileInfo(path); errInfo == nil {
+			existing++
+		}
+		items = append(items, &render.TransferItem{
+			Action: render.ActionDownload,
+			Key:    aws.StringValue(object.Key),
+			Path:   path,
+			Size:   aws.Int64Value(object.Size),
+		})
+	}
+
+	// Preview the objects that would be downloaded
+	if c.Bool(flags.DryRun) {
+		output := newTransferSummary(render.ActionDownload, aws.StringValue(input.Bucket), items)
+		output.DryRun = true
+		return cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input, output, nil)
+	}
+
+	// Ask for confirmation before overwriting local files
+	if existing > 0 && !c.Bool(flags.Force) {
+		confirmed := false
+		cosContext.UI.Warn(render.WarningOverwriteFiles(existing, dstDir))
+		cosContext.UI.Prompt(render.MessageConfirmationContinue(), &terminal.PromptOptions{}).Resolve(&confirmed)
+		if !confirmed {
+			cosContext.UI.Say(render.MessageOperationCanceled())
+			return
+		}
+	}
+
+	if len(items) > 0 {
+		// Options for Downloader
+		downloadOptions := map[string]string{
+			fields.PartSize:    flags.PartSize,
+			fields.Concurrency: flags.Concurrency,
+		}
+		// Error holder
+		errorHolder := new(error)
+
+		// Build a s3manager download
+		var downloader utils.Downloader
+		if downloader, err = cosContext.GetDownloader(c.String(flags.Region),
+			applyConfigDownloader(c, downloadOptions, errorHolder)); err != nil {
+			return
+		}
+		// Downloader Error Checking
+		if *errorHolder != nil {
+			return *errorHolder
+		}
+
+		// The objects are read with the customer-provided key, when given
+		template := &s3.GetObjectInput{Bucket: input.Bucket}
+		sseOptions := map[string]string{
+			fields.SSECustomerAlgorithm: flags.SSECustomerAlgorithm,
+			fields.SSECustomerKey:       flags.SSECustomerKey,
+		}
+		if err = MapToSDKInput(c, template, map[string]string{}, sseOptions); err != nil {
+			return
+		}
+
+		// The objects share the bandwidth of the download
+		var limiter *bandwidthLimiter
+		if limiter, err = getBandwidthLimiter(c, cosContext); err != nil {
+			return
+		}
+
+		// Batch Download Op, per object errors are recorded in the items
+		if err = downloadItems(downloader, cosContext, limiter, template, items, jobs); err != nil {
+			return
+		}
+	}
+
+	// Output the summary
+	output := newTransferSummary(render.ActionDownload, aws.StringValue(input.Bucket), items)
+	if err = cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input, output, nil); err != nil {
+		return
+	}
+
+	if output.Failed > 0 {
+		err = transferIncompleteError(output.Failed)
+	}
+
+	// Return
+	return
+}
+
 // Build Download from the Config
 func applyConfigDownloader(c *cli.Context, options map[string]string, err *error) func(u *s3manager.Downloader) {
 	return func(u *s3manager.Downloader) {
@@ -138,3 +470,48 @@ func applyConfigDownloader(c *cli.Context, options map[string]string, err *error
 		*err = MapToSDKInput(c, u, mandatory, options)
 	}
 }
+
+// downloadDecoded downloads an object into w, decrypting it when it was encrypted on the client
+// and decompressing it when asked to, with the part size and concurrency of the download. The progress is not reported on the standard output.
+func downloadDecoded(c *cli.Context, cosContext *utils.CosContext, input *s3.GetObjectInput,
+	masterKey []byte, w io.Writer, showProgress bool) (output *s3.GetObjectOutput, err error) {
+	var downloader *s3manager.Downloader
+	if downloader, err = getDownloadParts(c); err != nil {
+		return
+	}
+
+	var client s3iface.S3API
+	if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+		return
+	}
+
+	var limiter *bandwidthLimiter
+	if limiter, err = getBandwidthLimiter(c, cosContext); err != nil {
+		return
+	}
+
+	tracker := newTransferTracker(0, limiter)
+	if showProgress {
+		tracker = newTransferProgress(c, cosContext, aws.StringValue(input.Key), 0, limiter)
+		defer tracker.finish()
+	}
+	return getDecoded(client, input, masterKey, c.Bool(flags.Decompress), w,
+		downloader.PartSize, downloader.Concurrency, tracker)
+}
+
+// downloadExtracted downloads an object in order and extracts its archive into the directory given by --extract,
+// the object is decrypted and decompressed first when asked to
+func downloadExtracted(c *cli.Context, cosContext *utils.CosContext, input *s3.GetObjectInput,
+	masterKey []byte) (err error) {
+	extracting := newExtractingWriter(cosContext, c.String(flags.Extract))
+	var output *s3.GetObjectOutput
+	if output, err = downloadDecoded(c, cosContext, input, masterKey, extracting, true); err != nil {
+		extracting.abort(err)
+		return
+	}
+	if err = extracting.Close(); err != nil {
+		return
+	}
+	return cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input,
+		&render.DownloadOutput{TotalBytes: aws.Int64Value(output.ContentLength)}, nil)
+}
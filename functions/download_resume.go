@@ -0,0 +1,294 @@
+package functions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// downloadStateSuffix is appended to the download location to build the location of the download state
+const downloadStateSuffix = ".cos-download"
+
+// errCodePreconditionFailed is returned by the service when If-Match does not match the object ETag
+const errCodePreconditionFailed = "PreconditionFailed"
+
+// downloadStateInterval is the number of contiguous bytes written between two saves of the download state
+const downloadStateInterval = s3manager.DefaultDownloadPartSize
+
+// downloadState is the local state of a resumable download, kept next to the partial file.
+// The parts are written concurrently, so the partial file may have holes before its last byte:
+// Written is the number of bytes known to be written without a hole from the start of the file.
+type downloadState struct {
+	Bucket    string
+	Key       string
+	VersionId string
+	ETag      string
+	Size      int64
+	Written   int64 `json:",omitempty"`
+}
+
+// sameObject reports whether both states are the download of the same object
+func (state *downloadState) sameObject(other *downloadState) bool {
+	return state.Bucket == other.Bucket && state.Key == other.Key && state.VersionId == other.VersionId &&
+		state.ETag == other.ETag && state.Size == other.Size
+}
+
+// readDownloadState loads the state of a previous download, returns nil if there is none
+func readDownloadState(cosContext *utils.CosContext, location string) *downloadState {
+	file, err := cosContext.ReadSeekerCloserOpen(location)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	state := new(downloadState)
+	if err = json.NewDecoder(file).Decode(state); err != nil {
+		return nil
+	}
+	return state
+}
+
+// writeDownloadState saves the state, replacing the previous content
+func writeDownloadState(cosContext *utils.CosContext, location string, state *downloadState) error {
+	file, err := cosContext.WriteCloserOpen(location)
+	if err != nil {
+		return err
+	}
+	if err = json.NewEncoder(file).Encode(state); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// validateResume rejects the flags that cannot be combined with --resume
+func validateResume(c *cli.Context) error {
+	if c.Bool(flags.Resume) && c.IsSet(flags.Range) {
+		return &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.Range,
+		}
+	}
+	return nil
+}
+
+// resumableDownload continues the download of an object into a partial file with ranged GETs,
+// guarded by If-Match against the ETag recorded when the download started.
+// When the object changed in the meantime the download restarts from an empty file.
+type resumableDownload struct {
+	cosContext *utils.CosContext
+	client     s3iface.S3API
+	input      *s3.GetObjectInput
+	location   string
+	ifMatch    *string
+	file       utils.WriteCloser
+	restarted  bool
+	state      *downloadState
+	writer     *resumeWriter
+}
+
+func newResumableDownload(cosContext *utils.CosContext, client s3iface.S3API, input *s3.GetObjectInput,
+	location string, file utils.WriteCloser) *resumableDownload {
+	return &resumableDownload{
+		cosContext: cosContext,
+		client:     client,
+		input:      input,
+		location:   location,
+		ifMatch:    input.IfMatch,
+		file:       file,
+	}
+}
+
+// prepare reads the current ETag and size of the object, compares them with the saved state,
+// sets the range and the If-Match condition of the input and returns the writer
+// positioned at the first byte not known to be written
+func (download *resumableDownload) prepare() (offset int64, writer utils.WriteCloser, err error) {
+	var head *s3.HeadObjectOutput
+	if head, err = download.client.HeadObject(&s3.HeadObjectInput{
+		Bucket:    download.input.Bucket,
+		Key:       download.input.Key,
+		VersionId: download.input.VersionId,
+		IfMatch:   download.ifMatch,
+		// an object encrypted with a customer-provided key is only read with the key
+		SSECustomerAlgorithm: download.input.SSECustomerAlgorithm,
+		SSECustomerKey:       download.input.SSECustomerKey,
+	}); err != nil {
+		return
+	}
+	current := &downloadState{
+		Bucket:    aws.StringValue(download.input.Bucket),
+		Key:       aws.StringValue(download.input.Key),
+		VersionId: aws.StringValue(download.input.VersionId),
+		ETag:      aws.StringValue(head.ETag),
+		Size:      aws.Int64Value(head.ContentLength),
+	}
+	stateLocation := download.location + downloadStateSuffix
+
+	// Continue after the bytes already written only when they belong to the same object,
+	// the bytes after the first hole are downloaded again
+	var written int64
+	if info, errInfo := download.cosContext.GetFileInfo(download.location); errInfo == nil {
+		written = info.Size()
+	}
+	if saved := readDownloadState(download.cosContext, stateLocation); saved != nil &&
+		saved.sameObject(current) && written <= current.Size {
+		offset = saved.Written
+		if offset > written {
+			offset = written
+		}
+	}
+
+	// The partial content is useless, start again from an empty file
+	if offset == 0 && written > 0 {
+		download.file.Close()
+		if download.file, err = download.cosContext.WriteCloserOpen(download.location); err != nil {
+			return
+		}
+	}
+
+	// A complete file still fetches its last byte, so the download ends as usual
+	if offset > 0 && offset == current.Size {
+		offset--
+	}
+
+	current.Written = offset
+	if err = writeDownloadState(download.cosContext, stateLocation, current); err != nil {
+		return
+	}
+	download.state = current
+
+	download.input.IfMatch = head.ETag
+	download.input.Range = nil
+	if offset > 0 {
+		download.input.Range = aws.String(fmt.Sprintf("bytes=%d-", offset))
+	}
+	download.writer = &resumeWriter{
+		WriteCloser: download.file,
+		writer:      io.NewOffsetWriter(download.file, offset),
+		offset:      offset,
+		written:     offset,
+		saved:       offset,
+		save:        download.save,
+	}
+	writer = download.writer
+	return
+}
+
+// save records the bytes written without a hole, a failed save only costs downloading them again
+func (download *resumableDownload) save(written int64) {
+	download.state.Written = written
+	writeDownloadState(download.cosContext, download.location+downloadStateSuffix, download.state)
+}
+
+// interrupt saves the progress of a download which failed, to be continued by the next one
+func (download *resumableDownload) interrupt() {
+	if download.writer != nil {
+		download.writer.flush()
+	}
+}
+
+// restart reports whether the download has to start again because the object changed
+// after its ETag was read, and discards the saved state. A download restarts only once.
+func (download *resumableDownload) restart(err error) bool {
+	if download.restarted {
+		return false
+	}
+	if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != errCodePreconditionFailed {
+		return false
+	}
+	download.restarted = true
+	download.cosContext.Remove(download.location + downloadStateSuffix)
+	return true
+}
+
+// finish discards the saved state once the download completed
+func (download *resumableDownload) finish() {
+	download.cosContext.Remove(download.location + downloadStateSuffix)
+}
+
+// resumeWriter shifts every write by the offset the download continues from and tracks the bytes
+// written without a hole, which are saved every downloadStateInterval bytes
+type resumeWriter struct {
+	utils.WriteCloser
+	writer *io.OffsetWriter
+	offset int64
+	save   func(written int64)
+
+	lock sync.Mutex
+	// the end of the bytes written without a hole, the part of it last saved
+	written, saved int64
+	// the ranges written after a hole, by start
+	pending map[int64]int64
+}
+
+func (w *resumeWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.track(w.written, int64(n))
+	return n, err
+}
+
+func (w *resumeWriter) WriteAt(p []byte, off int64) (int, error) {
+	n, err := w.writer.WriteAt(p, off)
+	w.track(w.offset+off, int64(n))
+	return n, err
+}
+
+// track records the range written and moves the end of the bytes written without a hole
+func (w *resumeWriter) track(start, n int64) {
+	if n <= 0 {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	end := start + n
+	if start > w.written {
+		if w.pending == nil {
+			w.pending = make(map[int64]int64)
+		}
+		if end > w.pending[start] {
+			w.pending[start] = end
+		}
+		return
+	}
+	if end > w.written {
+		w.written = end
+	}
+	// the ranges written before now follow without a hole
+	for merged := true; merged; {
+		merged = false
+		for start, end := range w.pending {
+			if start <= w.written {
+				if end > w.written {
+					w.written = end
+				}
+				delete(w.pending, start)
+				merged = true
+			}
+		}
+	}
+	if w.written-w.saved >= downloadStateInterval {
+		w.saved = w.written
+		w.save(w.written)
+	}
+}
+
+// flush saves the bytes written without a hole
+func (w *resumeWriter) flush() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.written > w.saved {
+		w.saved = w.written
+		w.save(w.written)
+	}
+}
@@ -0,0 +1,835 @@
+//+build unit
+
+package functions_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config"
+	"github.com/IBM/ibmcloud-cos-cli/config/commands"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/cos"
+	"github.com/IBM/ibmcloud-cos-cli/di/providers"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli"
+)
+
+func TestDownloadRecursiveRecreatesHierarchy(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetPrefix := "logs/2026/"
+	targetDir := "out"
+	now := time.Now()
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	var listCapture *s3.ListObjectsV2Input
+	providers.MockS3API.
+		On("ListObjectsV2Pages", mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+			listCapture = input
+			return true
+		}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListObjectsV2Output, last bool) bool)
+			pager(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+				{Key: aws.String(targetPrefix), Size: aws.Int64(0), LastModified: &now},
+				{Key: aws.String(targetPrefix + "01/a.log"), Size: aws.Int64(10), LastModified: &now},
+			}}, false)
+			pager(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+				{Key: aws.String(targetPrefix + "01/b.log"), Size: aws.Int64(20), LastModified: &now},
+				{Key: aws.String(targetPrefix + "02/c.log"), Size: aws.Int64(30), LastModified: &now},
+			}}, true)
+		}).
+		Return(nil).
+		Once()
+
+	providers.MockFileOperations.On("GetFileInfo", mock.Anything).Return(nil, os.ErrNotExist)
+	providers.MockFileOperations.On("MkdirAll", mock.Anything).Return(nil)
+	providers.MockFileOperations.On("WriteCloserOpen", mock.Anything).Return(utils.WriteToString(nil, nil), nil)
+	providers.MockFileOperations.On("Remove", filepath.Join(targetDir, "01", "b.log")).Return(nil).Once()
+
+	var lock sync.Mutex
+	downloadedKeys := make([]string, 0)
+	providers.MockDownloaderAPI.
+		On("DownloadWithIterator", mock.Anything, mock.Anything).
+		Return(func(_ aws.Context, iterator s3manager.BatchDownloadIterator, _ ...func(*s3manager.Downloader)) error {
+			var errs []s3manager.Error
+			for iterator.Next() {
+				object := iterator.DownloadObject()
+				lock.Lock()
+				downloadedKeys = append(downloadedKeys, aws.StringValue(object.Object.Key))
+				lock.Unlock()
+				object.After()
+				if aws.StringValue(object.Object.Key) == targetPrefix+"01/b.log" {
+					errs = append(errs, s3manager.Error{OrigErr: os.ErrDeadlineExceeded,
+						Bucket: object.Object.Bucket, Key: object.Object.Key})
+				}
+			}
+			if len(errs) > 0 {
+				return s3manager.NewBatchError("BatchedDownloadIncomplete", "some objects have failed to download.", errs)
+			}
+			return nil
+		})
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Download, "--bucket", targetBucket,
+		"--" + flags.Prefix, targetPrefix,
+		"--" + flags.Recursive,
+		"--" + flags.Jobs, "2",
+		"--" + flags.Region, "REG",
+		targetDir,
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, targetPrefix, aws.StringValue(listCapture.Prefix))
+	sort.Strings(downloadedKeys)
+	assert.Equal(t, []string{targetPrefix + "01/a.log", targetPrefix + "01/b.log", targetPrefix + "02/c.log"},
+		downloadedKeys)
+	providers.MockDownloaderAPI.AssertNumberOfCalls(t, "DownloadWithIterator", 2)
+	providers.MockFileOperations.AssertCalled(t, "MkdirAll", filepath.Join(targetDir, "02"))
+	providers.MockFileOperations.AssertCalled(t, "WriteCloserOpen", filepath.Join(targetDir, "02", "c.log"))
+	providers.MockFileOperations.AssertNumberOfCalls(t, "Remove", 1)
+
+	// assert exit code is non-zero since one object failed
+	assert.Equal(t, 1, *exitCode)
+	// capture all output //
+	output := providers.FakeUI.Outputs()
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, output, "Downloaded 2 of 3 object(s) from bucket '"+targetBucket+"' (40 B).")
+	assert.Contains(t, output, os.ErrDeadlineExceeded.Error())
+	assert.Contains(t, errors, "FAIL")
+}
+
+func TestDownloadRecursiveOverwriteCanceled(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetDir := "out"
+	now := time.Now()
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+	providers.FakeUI.Inputs("n")
+
+	providers.MockS3API.
+		On("ListObjectsV2Pages", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListObjectsV2Output, last bool) bool)
+			pager(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+				{Key: aws.String("a.txt"), Size: aws.Int64(10), LastModified: &now},
+			}}, true)
+		}).
+		Return(nil).
+		Once()
+
+	providers.MockFileOperations.
+		On("GetFileInfo", filepath.Join(targetDir, "a.txt")).
+		Return(utils.FakeFileInfo("a.txt", 10, now, false), nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Download, "--bucket", targetBucket,
+		"--" + flags.Recursive,
+		"--" + flags.Region, "REG",
+		targetDir,
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	providers.MockDownloaderAPI.AssertNotCalled(t, "DownloadWithIterator", mock.Anything, mock.Anything)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "Operation canceled.")
+}
+
+func TestDownloadRecursiveRejectsSingleObjectFlags(t *testing.T) {
+	for _, flag := range []string{flags.Decompress, flags.Resume} {
+		t.Run(flag, func(t *testing.T) {
+			defer providers.MocksRESET()
+
+			// --- Arrange ---
+			// disable and capture OS EXIT
+			var exitCode *int
+			cli.OsExiter = func(ec int) {
+				exitCode = &ec
+			}
+
+			providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+			// --- Act ----
+			// set os args
+			os.Args = []string{"-", commands.Download, "--bucket", "TargetBucket",
+				"--" + flags.Recursive,
+				"--" + flag,
+				"--" + flags.Region, "REG",
+				"out",
+			}
+			// call  plugin
+			plugin.Start(new(cos.Plugin))
+
+			// --- Assert ----
+			// assert exit code is non-zero
+			assert.Equal(t, 1, *exitCode)
+			providers.MockS3API.AssertNotCalled(t, "ListObjectsV2Pages", mock.Anything, mock.Anything)
+			providers.MockDownloaderAPI.AssertNotCalled(t, "DownloadWithIterator", mock.Anything, mock.Anything)
+			assert.Contains(t, providers.FakeUI.Errors(), "The value in flag '--"+flag+"' is invalid")
+		})
+	}
+}
+
+func TestDownloadResumeRestartsWhenObjectChanged(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetKey := "TargetKey"
+	targetFile := "out.bin"
+	stateFile := targetFile + ".cos-download"
+	savedState := `{"Bucket":"` + targetBucket + `","Key":"` + targetKey + `","VersionId":"",` +
+		`"ETag":"\"old\"","Size":10}`
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", stateFile).
+		Return(func(string) utils.ReadSeekerCloser { return utils.WrapString(savedState, nil) }, nil)
+	providers.MockFileOperations.
+		On("WriteCloserOpenExisting", targetFile).
+		Return(utils.WriteToString(nil, nil), nil).
+		Once()
+	providers.MockFileOperations.
+		On("GetFileInfo", targetFile).
+		Return(utils.FakeFileInfo(targetFile, 4, time.Now(), false), nil)
+	providers.MockFileOperations.
+		On("WriteCloserOpen", mock.Anything).
+		Return(utils.WriteToString(nil, nil), nil)
+	providers.MockFileOperations.On("Remove", stateFile).Return(nil).Once()
+
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(new(s3.HeadObjectOutput).
+			SetETag(`"new"`).
+			SetContentLength(12), nil).
+		Once()
+
+	var inputCapture *s3.GetObjectInput
+	providers.MockDownloaderAPI.
+		On("Download", mock.Anything, mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+			inputCapture = input
+			return true
+		})).
+		Return(int64(12), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Download, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.Resume,
+		"--" + flags.Region, "REG",
+		targetFile,
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// the partial file belongs to the old object and is truncated
+	providers.MockFileOperations.AssertCalled(t, "WriteCloserOpen", targetFile)
+	assert.Nil(t, inputCapture.Range)
+	assert.Equal(t, `"new"`, aws.StringValue(inputCapture.IfMatch))
+	providers.MockFileOperations.AssertCalled(t, "Remove", stateFile)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "OK")
+}
+
+func TestDownloadResumeAfterPartsWrittenOutOfOrder(t *testing.T) {
+	defer providers.MocksRESET()
+
+	targetBucket := "TargetBucket"
+	targetKey := "TargetKey"
+	targetFile := "out.bin"
+	stateFile := targetFile + ".cos-download"
+
+	// resumeDownload downloads a 10 bytes object with --resume over a partial file and the state
+	// left by the previous download, returns the state it saves and the range it requests
+	resumeDownload := func(savedState string, partialSize int64, write func(w io.WriterAt) int64,
+		failure error) (exitCode *int, state string, requested *string) {
+		providers.MocksRESET()
+		cli.OsExiter = func(ec int) {
+			exitCode = &ec
+		}
+
+		providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+		providers.MockFileOperations.
+			On("ReadSeekerCloserOpen", stateFile).
+			Return(func(string) utils.ReadSeekerCloser { return utils.WrapString(savedState, nil) }, nil)
+		if partialSize > 0 {
+			providers.MockFileOperations.
+				On("GetFileInfo", targetFile).
+				Return(utils.FakeFileInfo(targetFile, partialSize, time.Now(), false), nil)
+		} else {
+			providers.MockFileOperations.On("GetFileInfo", targetFile).Return(nil, os.ErrNotExist)
+		}
+		providers.MockFileOperations.
+			On("WriteCloserOpenExisting", targetFile).
+			Return(utils.WriteToString(nil, nil), nil)
+		providers.MockFileOperations.
+			On("WriteCloserOpen", targetFile).
+			Return(utils.WriteToString(nil, nil), nil)
+		providers.MockFileOperations.
+			On("WriteCloserOpen", stateFile).
+			Return(func(string) utils.WriteCloser { return utils.WriteToString(&state, nil) }, nil)
+		providers.MockFileOperations.On("Remove", stateFile).Return(nil)
+
+		providers.MockS3API.
+			On("HeadObject", mock.Anything).
+			Return(new(s3.HeadObjectOutput).SetETag(`"etag"`).SetContentLength(10), nil)
+
+		providers.MockDownloaderAPI.
+			On("Download", mock.Anything, mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+				requested = input.Range
+				return true
+			})).
+			Return(func(w io.WriterAt, _ *s3.GetObjectInput, _ ...func(*s3manager.Downloader)) int64 {
+				return write(w)
+			}, failure).
+			Once()
+
+		os.Args = []string{"-", commands.Download, "--bucket", targetBucket,
+			"--" + flags.Key, targetKey,
+			"--" + flags.Resume,
+			"--" + flags.Region, "REG",
+			targetFile,
+		}
+		plugin.Start(new(cos.Plugin))
+		return
+	}
+
+	// --- Act ----
+	// the second part lands first, the download is interrupted within the first part
+	exitCode, state, requested := resumeDownload("", 0, func(w io.WriterAt) int64 {
+		w.WriteAt([]byte("56789"), 5)
+		w.WriteAt([]byte("012"), 0)
+		return 0
+	}, fmt.Errorf("connection reset by peer"))
+
+	// --- Assert ----
+	assert.Equal(t, 1, *exitCode)
+	assert.Nil(t, requested)
+	assert.Contains(t, state, `"Written":3`)
+
+	// --- Act ----
+	// the partial file is as long as the object, with a hole after its first 3 bytes
+	exitCode, _, requested = resumeDownload(state, 10, func(w io.WriterAt) int64 {
+		w.WriteAt([]byte("34"), 0)
+		return 7
+	}, nil)
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode)
+	// the download continues at the hole and not after the end of the partial file
+	assert.Equal(t, "bytes=3-", aws.StringValue(requested))
+	providers.MockFileOperations.AssertCalled(t, "Remove", stateFile)
+}
+
+func TestDownloadJsonProgressEvents(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetKey := "TargetKey"
+	targetFile := "out.bin"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.On("GetFileInfo", targetFile).Return(nil, os.ErrNotExist)
+	providers.MockFileOperations.On("WriteCloserOpen", targetFile).Return(utils.WriteToString(nil, nil), nil)
+
+	providers.MockDownloaderAPI.
+		On("Download", mock.Anything, mock.Anything).
+		Return(func(w io.WriterAt, _ *s3.GetObjectInput, _ ...func(*s3manager.Downloader)) int64 {
+			// parts written out of order, the first one twice as on a retry
+			w.WriteAt([]byte("4567"), 4)
+			w.WriteAt([]byte("0123"), 0)
+			w.WriteAt([]byte("0123"), 0)
+			return 8
+		}, nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Download, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.Region, "REG",
+		"--" + flags.Output, "json",
+		targetFile,
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, errors, `{"Event":"start","Key":"TargetKey","Bytes":0,`)
+	assert.Contains(t, errors, `{"Event":"complete","Key":"TargetKey","Bytes":8,`)
+}
+
+func TestDownloadToStandardOutputWritesPartsInOrder(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetKey := "TargetKey"
+	objectContent := "0123456789"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	stdout := new(strings.Builder)
+	providers.MockFileOperations.
+		On("Stdout").
+		Return(stdout)
+
+	// every range is served from the object, later parts answer first
+	var lock sync.Mutex
+	var ranges []string
+	var ifMatches []string
+	providers.MockS3API.
+		On("GetObject", mock.Anything).
+		Return(func(input *s3.GetObjectInput) *s3.GetObjectOutput {
+			var start, end int
+			fmt.Sscanf(aws.StringValue(input.Range), "bytes=%d-%d", &start, &end)
+			if end >= len(objectContent) {
+				end = len(objectContent) - 1
+			}
+			time.Sleep(time.Duration(len(objectContent)-start) * time.Millisecond)
+			lock.Lock()
+			ranges = append(ranges, aws.StringValue(input.Range))
+			ifMatches = append(ifMatches, aws.StringValue(input.IfMatch))
+			lock.Unlock()
+			return new(s3.GetObjectOutput).
+				SetBody(io.NopCloser(strings.NewReader(objectContent[start : end+1]))).
+				SetContentLength(int64(end + 1 - start)).
+				SetContentRange(fmt.Sprintf("bytes %d-%d/%d", start, end, len(objectContent))).
+				SetETag(`"etag"`)
+		}, nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Download, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.PartSize, "3",
+		"--" + flags.Concurrency, "3",
+		"--" + flags.Region, "REG",
+		"-",
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	assert.Equal(t, objectContent, stdout.String())
+	sort.Strings(ranges)
+	assert.Equal(t, []string{"bytes=0-2", "bytes=3-5", "bytes=6-8", "bytes=9-9"}, ranges)
+	// the parts after the first one are fetched from the same object
+	sort.Strings(ifMatches)
+	assert.Equal(t, []string{"", `"etag"`, `"etag"`, `"etag"`}, ifMatches)
+	providers.MockDownloaderAPI.AssertNotCalled(t, "Download", mock.Anything, mock.Anything)
+	output := providers.FakeUI.Outputs()
+	assert.NotContains(t, output, "OK")
+}
+
+func TestDownloadWildcardKeyDryRun(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// no object has the wildcard key itself
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			return aws.StringValue(input.Key) == "data/part-*"
+		})).
+		Return(nil, awserr.NewRequestFailure(awserr.New("NotFound", "Not Found", nil), 404, "request")).
+		Once()
+
+	var listCapture *s3.ListObjectsV2Input
+	providers.MockS3API.
+		On("ListObjectsV2Pages", mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+			listCapture = input
+			return true
+		}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListObjectsV2Output, last bool) bool)
+			pager(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+				{Key: aws.String("data/part-1.parquet"), Size: aws.Int64(10)},
+				{Key: aws.String("data/part-2.parquet"), Size: aws.Int64(20)},
+				{Key: aws.String("data/part-3.csv"), Size: aws.Int64(30)},
+				{Key: aws.String("data/part-x/4.parquet"), Size: aws.Int64(40)},
+			}}, true)
+		}).
+		Return(nil).
+		Once()
+
+	providers.MockFileOperations.On("GetFileInfo", mock.Anything).Return(nil, os.ErrNotExist)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Download, "--bucket", "TargetBucket",
+		"--" + flags.Key, "data/part-*",
+		"--" + flags.Include, "*.parquet",
+		"--" + flags.DryRun,
+		"--" + flags.Region, "REG",
+		"out",
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero and nothing was downloaded
+	assert.Equal(t, (*int)(nil), exitCode)
+	assert.Equal(t, "data/part-", aws.StringValue(listCapture.Prefix))
+	providers.MockDownloaderAPI.AssertNotCalled(t, "DownloadWithIterator", mock.Anything, mock.Anything)
+	output := providers.FakeUI.Outputs()
+	// the matching keys are downloaded relative to the directory of the pattern
+	assert.Contains(t, output, filepath.Join("out", "part-1.parquet"))
+	assert.Contains(t, output, filepath.Join("out", "part-2.parquet"))
+	assert.NotContains(t, output, "part-3.csv")
+	assert.NotContains(t, output, "4.parquet")
+	assert.Contains(t, output, "Dry run: 2 object(s) matched in bucket 'TargetBucket' (30 B).")
+}
+
+func TestDownloadLiteralBracketedKey(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetKey := "reports/a[1].txt"
+	targetFile := "a1.txt"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// an object has the exact key, which is not a wildcard then
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			return aws.StringValue(input.Bucket) == targetBucket && aws.StringValue(input.Key) == targetKey
+		})).
+		Return(new(s3.HeadObjectOutput).SetContentLength(12), nil).
+		Once()
+
+	providers.MockFileOperations.On("GetFileInfo", targetFile).Return(nil, os.ErrNotExist)
+	providers.MockFileOperations.
+		On("WriteCloserOpen", targetFile).
+		Return(utils.WriteToString(nil, nil), nil).
+		Once()
+
+	var inputCapture *s3.GetObjectInput
+	providers.MockDownloaderAPI.
+		On("Download", mock.Anything, mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+			inputCapture = input
+			return true
+		})).
+		Return(int64(12), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Download, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.Region, "REG",
+		targetFile,
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero and the single object was downloaded
+	assert.Equal(t, (*int)(nil), exitCode)
+	assert.Equal(t, targetKey, aws.StringValue(inputCapture.Key))
+	providers.MockS3API.AssertNotCalled(t, "ListObjectsV2Pages", mock.Anything, mock.Anything)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "OK")
+}
+
+func TestDownloadDecryptsRangeOfEncryptedObject(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetKey := "TargetKey"
+	masterKey := strings.Repeat("ab", 32)
+	plaintext := strings.Repeat("0123456789abcdef", 15000)
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "master.key").
+		Return(utils.WrapString(masterKey+"\n", nil), nil).
+		Once()
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "master.key").
+		Return(utils.WrapString(masterKey, nil), nil).
+		Once()
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "plain.txt").
+		Return(utils.WrapString(plaintext, nil), nil).
+		Once()
+
+	stdout := new(strings.Builder)
+	providers.MockFileOperations.
+		On("Stdout").
+		Return(stdout)
+
+	// the object is stored as it is uploaded, its metadata comes back capitalized
+	var ciphertext string
+	metadata := make(map[string]*string)
+	providers.MockS3API.
+		On("PutObject", mock.Anything).
+		Run(func(args mock.Arguments) {
+			input := args.Get(0).(*s3.PutObjectInput)
+			body, _ := io.ReadAll(input.Body)
+			ciphertext = string(body)
+			for key, value := range input.Metadata {
+				metadata[strings.Title(key)] = value
+			}
+		}).
+		Return(new(s3.PutObjectOutput), nil).
+		Once()
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(func(input *s3.HeadObjectInput) *s3.HeadObjectOutput {
+			return new(s3.HeadObjectOutput).
+				SetContentLength(int64(len(ciphertext))).
+				SetMetadata(metadata).
+				SetETag(`"etag"`)
+		}, nil).
+		Once()
+	var lock sync.Mutex
+	var ranges []string
+	providers.MockS3API.
+		On("GetObject", mock.Anything).
+		Return(func(input *s3.GetObjectInput) *s3.GetObjectOutput {
+			var start, end int
+			fmt.Sscanf(aws.StringValue(input.Range), "bytes=%d-%d", &start, &end)
+			lock.Lock()
+			ranges = append(ranges, aws.StringValue(input.Range))
+			lock.Unlock()
+			return new(s3.GetObjectOutput).
+				SetBody(io.NopCloser(strings.NewReader(ciphertext[start : end+1]))).
+				SetContentLength(int64(end + 1 - start))
+		}, nil)
+
+	// --- Act ----
+	// upload the object encrypted, then download a range of it decrypted
+	os.Args = []string{"-", commands.ObjectPut, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.Body, "plain.txt",
+		"--" + flags.EncryptionKeyFile, "master.key",
+		"--" + flags.Region, "REG",
+	}
+	plugin.Start(new(cos.Plugin))
+	os.Args = []string{"-", commands.Download, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.Range, "bytes=70000-140000",
+		"--" + flags.PartSize, "1000",
+		"--" + flags.EncryptionKeyFile, "master.key",
+		"--" + flags.Region, "REG",
+		"-",
+	}
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	assert.NotContains(t, ciphertext, plaintext[:100])
+	assert.Equal(t, "AES256-GCM-SEGMENTED", aws.StringValue(metadata["Cse-Algorithm"]))
+	assert.Equal(t, "AES256-GCM", aws.StringValue(metadata["Cse-Key-Algorithm"]))
+	assert.NotEmpty(t, aws.StringValue(metadata["Cse-Key"]))
+	assert.Equal(t, plaintext[70000:140001], stdout.String())
+	// only the segments holding the range are fetched, a segment per part
+	sort.Strings(ranges)
+	assert.Equal(t, []string{"bytes=131104-196655", "bytes=65552-131103"}, ranges)
+}
+
+func TestDownloadExtractsUploadedArchive(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "ArchiveBucket"
+	targetKey := "backup.tar.gz"
+	srcDir := "src"
+	dstDir := "dst"
+	now := time.Now()
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("Walk", srcDir, mock.Anything).
+		Run(func(args mock.Arguments) {
+			walkFn := args.Get(1).(filepath.WalkFunc)
+			walkFn(srcDir, utils.FakeFileInfo(srcDir, 0, now, true), nil)
+			walkFn(filepath.Join(srcDir, "b.txt"), utils.FakeFileInfo("b.txt", 6, now, false), nil)
+			walkFn(filepath.Join(srcDir, "docs"), utils.FakeFileInfo("docs", 0, now, true), nil)
+			walkFn(filepath.Join(srcDir, "docs", "a.txt"), utils.FakeFileInfo("a.txt", 5, now, false), nil)
+		}).
+		Return(nil).
+		Once()
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", filepath.Join(srcDir, "b.txt")).
+		Return(utils.WrapString("bravo!", nil), nil).
+		Once()
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", filepath.Join(srcDir, "docs", "a.txt")).
+		Return(utils.WrapString("alpha", nil), nil).
+		Once()
+
+	// the archive is read by the Uploader while the directory is walked
+	var uploadInput *s3manager.UploadInput
+	var archive []byte
+	providers.MockUploaderAPI.
+		On("Upload", mock.Anything).
+		Run(func(args mock.Arguments) {
+			uploadInput = args.Get(0).(*s3manager.UploadInput)
+			archive, _ = io.ReadAll(uploadInput.Body)
+		}).
+		Return(nil, nil).
+		Once()
+
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(func(input *s3.HeadObjectInput) *s3.HeadObjectOutput {
+			return new(s3.HeadObjectOutput).SetContentLength(int64(len(archive)))
+		}, nil).
+		Once()
+	providers.MockS3API.
+		On("GetObject", mock.Anything).
+		Return(func(input *s3.GetObjectInput) *s3.GetObjectOutput {
+			return new(s3.GetObjectOutput).
+				SetBody(io.NopCloser(strings.NewReader(string(archive)))).
+				SetContentLength(int64(len(archive)))
+		}, nil).
+		Once()
+
+	extracted := map[string]*string{
+		filepath.Join(dstDir, "b.txt"):         new(string),
+		filepath.Join(dstDir, "docs", "a.txt"): new(string),
+	}
+	providers.MockFileOperations.On("MkdirAll", mock.Anything).Return(nil)
+	providers.MockFileOperations.On("Chmod", mock.Anything, mock.Anything).Return(nil)
+	for path, content := range extracted {
+		providers.MockFileOperations.
+			On("WriteCloserOpen", path).
+			Return(utils.WriteToString(content, nil), nil).
+			Once()
+	}
+
+	// --- Act ----
+	// upload the directory as an archive, then download it extracted into another directory
+	os.Args = []string{"-", commands.Upload, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.File, srcDir,
+		"--" + flags.Archive, "tar.gz",
+		"--" + flags.Region, "REG",
+	}
+	plugin.Start(new(cos.Plugin))
+	assert.Equal(t, (*int)(nil), exitCode)
+	os.Args = []string{"-", commands.Download, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.Extract, dstDir,
+		"--" + flags.Region, "REG",
+	}
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	assert.Equal(t, "application/gzip", aws.StringValue(uploadInput.ContentType))
+	// the paths and the modes of the directory are restored
+	assert.Equal(t, "bravo!", *extracted[filepath.Join(dstDir, "b.txt")])
+	assert.Equal(t, "alpha", *extracted[filepath.Join(dstDir, "docs", "a.txt")])
+	providers.MockFileOperations.AssertCalled(t, "MkdirAll", filepath.Join(dstDir, "docs"))
+	providers.MockFileOperations.AssertCalled(t, "Chmod", filepath.Join(dstDir, "docs", "a.txt"), os.FileMode(0644))
+	providers.MockFileOperations.AssertCalled(t, "Chmod", filepath.Join(dstDir, "docs"), os.FileMode(0755))
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "OK")
+}
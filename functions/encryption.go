@@ -0,0 +1,626 @@
+package functions
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// Client side encryption.
+// The object is encrypted with a random AES-256 data key, in segments sealed with AES-256-GCM,
+// so a range of the object can be decrypted without the segments before it, and the segments of
+// a multipart upload can be encrypted as they are read. The data key is wrapped with AES-256-GCM
+// by the master key of the user and stored in the metadata of the object along with the algorithms.
+const (
+	// encryptionAlgorithm seals the segments of the object
+	encryptionAlgorithm = "AES256-GCM-SEGMENTED"
+	// keyWrapAlgorithm seals the data key with the master key
+	keyWrapAlgorithm = "AES256-GCM"
+	// encryptionSegmentSize is the size of the plaintext of a segment
+	encryptionSegmentSize = 64 * 1024
+	// encryptionKeySize is the size of the master key and of the data keys
+	encryptionKeySize = 32
+)
+
+// Metadata of an object encrypted on the client
+const (
+	metadataEncryptionAlgorithm = "cse-algorithm"
+	metadataKeyWrapAlgorithm    = "cse-key-algorithm"
+	metadataWrappedKey          = "cse-key"
+	metadataEncryptionNonce     = "cse-nonce"
+	metadataSegmentSize         = "cse-segment-size"
+)
+
+// keyWrapData authenticates the data key sealed by the master key
+var keyWrapData = []byte(keyWrapAlgorithm)
+
+// errCodeDecryption is returned when an object cannot be decrypted
+const errCodeDecryption = "DecryptionFailed"
+
+// envelope holds the data key of an object and how its segments are sealed
+type envelope struct {
+	aead        cipher.AEAD
+	nonce       []byte
+	segmentSize int64
+}
+
+// getMasterKey reads the master key file of the command, nil when client side encryption is not asked for.
+// The flags that cannot be combined with client side encryption are rejected.
+func getMasterKey(c *cli.Context, cosContext *utils.CosContext, conflicting ...string) ([]byte, error) {
+	if !c.IsSet(flags.EncryptionKeyFile) {
+		return nil, nil
+	}
+	for _, flag := range conflicting {
+		if c.IsSet(flag) {
+			return nil, &errors.CommandError{
+				CLIContext: c,
+				Cause:      errors.InvalidValue,
+				Flag:       flag,
+				IError:     fmt.Errorf("--%s cannot be used with --%s", flag, flags.EncryptionKeyFile),
+			}
+		}
+	}
+	key, err := readMasterKey(cosContext, c.String(flags.EncryptionKeyFile))
+	if err != nil {
+		return nil, &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.EncryptionKeyFile,
+			IError:     err,
+		}
+	}
+	return key, nil
+}
+
+// readMasterKey reads a 256-bit key from a file, as raw bytes or hex or base64 encoded
+func readMasterKey(cosContext *utils.CosContext, location string) ([]byte, error) {
+	file, err := cosContext.ReadSeekerCloserOpen(location)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) == encryptionKeySize {
+		return content, nil
+	}
+	text := strings.TrimSpace(string(content))
+	if key, err := hex.DecodeString(text); err == nil && len(key) == encryptionKeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == encryptionKeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("the master key must be %d bytes, raw or hex or base64 encoded", encryptionKeySize)
+}
+
+// newGCM returns an AES-256-GCM cipher
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newEnvelope generates the data key of a new object and returns the metadata recording it,
+// wrapped by the master key
+func newEnvelope(masterKey []byte) (*envelope, map[string]*string, error) {
+	dataKey := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+
+	wrapper, err := newGCM(masterKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	wrapNonce := make([]byte, wrapper.NonceSize())
+	if _, err = rand.Read(wrapNonce); err != nil {
+		return nil, nil, err
+	}
+	wrapped := wrapper.Seal(wrapNonce, wrapNonce, dataKey, keyWrapData)
+
+	metadata := map[string]*string{
+		metadataEncryptionAlgorithm: aws.String(encryptionAlgorithm),
+		metadataKeyWrapAlgorithm:    aws.String(keyWrapAlgorithm),
+		metadataWrappedKey:          aws.String(base64.StdEncoding.EncodeToString(wrapped)),
+		metadataEncryptionNonce:     aws.String(base64.StdEncoding.EncodeToString(nonce)),
+		metadataSegmentSize:         aws.String(strconv.Itoa(encryptionSegmentSize)),
+	}
+	return &envelope{aead: aead, nonce: nonce, segmentSize: encryptionSegmentSize}, metadata, nil
+}
+
+// metadataValue looks a key up in the metadata of an object, whose keys may come back capitalized
+func metadataValue(metadata map[string]*string, key string) string {
+	for name, value := range metadata {
+		if strings.EqualFold(name, key) {
+			return aws.StringValue(value)
+		}
+	}
+	return ""
+}
+
+// openEnvelope unwraps the data key recorded in the metadata of an object with the master key,
+// nil when the object is not encrypted on the client
+func openEnvelope(masterKey []byte, metadata map[string]*string) (*envelope, error) {
+	algorithm := metadataValue(metadata, metadataEncryptionAlgorithm)
+	if algorithm == "" {
+		return nil, nil
+	}
+	if algorithm != encryptionAlgorithm || metadataValue(metadata, metadataKeyWrapAlgorithm) != keyWrapAlgorithm {
+		return nil, awserr.New(errCodeDecryption, "unsupported client side encryption algorithm: "+algorithm, nil)
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(metadataValue(metadata, metadataWrappedKey))
+	if err != nil {
+		return nil, awserr.New(errCodeDecryption, "invalid wrapped data key", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(metadataValue(metadata, metadataEncryptionNonce))
+	if err != nil {
+		return nil, awserr.New(errCodeDecryption, "invalid nonce", err)
+	}
+	segmentSize, err := strconv.ParseInt(metadataValue(metadata, metadataSegmentSize), 10, 64)
+	if err != nil || segmentSize <= 0 {
+		return nil, awserr.New(errCodeDecryption, "invalid segment size", err)
+	}
+
+	wrapper, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < wrapper.NonceSize() {
+		return nil, awserr.New(errCodeDecryption, "invalid wrapped data key", nil)
+	}
+	dataKey, err := wrapper.Open(nil, wrapped[:wrapper.NonceSize()], wrapped[wrapper.NonceSize():], keyWrapData)
+	if err != nil {
+		return nil, awserr.New(errCodeDecryption, "the master key does not unwrap the data key of the object", err)
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, awserr.New(errCodeDecryption, "invalid nonce", nil)
+	}
+	return &envelope{aead: aead, nonce: nonce, segmentSize: segmentSize}, nil
+}
+
+// stride is the size of the ciphertext of a whole segment
+func (env *envelope) stride() int64 {
+	return env.segmentSize + int64(env.aead.Overhead())
+}
+
+// plaintextSize returns the size of the plaintext of a ciphertext, which holds at least one segment
+func (env *envelope) plaintextSize(ciphertextSize int64) int64 {
+	segments := (ciphertextSize + env.stride() - 1) / env.stride()
+	return ciphertextSize - segments*int64(env.aead.Overhead())
+}
+
+// ciphertextSize returns the size of the ciphertext of a plaintext
+func (env *envelope) ciphertextSize(plaintextSize int64) int64 {
+	segments := (plaintextSize + env.segmentSize - 1) / env.segmentSize
+	if segments == 0 {
+		segments = 1
+	}
+	return plaintextSize + segments*int64(env.aead.Overhead())
+}
+
+// segmentNonce derives the nonce of a segment from the nonce of the object
+func (env *envelope) segmentNonce(segment int64) []byte {
+	nonce := make([]byte, len(env.nonce))
+	copy(nonce, env.nonce)
+	tail := nonce[len(nonce)-8:]
+	binary.BigEndian.PutUint64(tail, binary.BigEndian.Uint64(tail)^uint64(segment))
+	return nonce
+}
+
+// segmentData authenticates the position of a segment, and whether it ends the object
+// so the object cannot be truncated
+func segmentData(segment int64, final bool) []byte {
+	data := make([]byte, 9)
+	binary.BigEndian.PutUint64(data, uint64(segment))
+	if final {
+		data[8] = 1
+	}
+	return data
+}
+
+// encryptingReader reads the ciphertext of a plaintext source, segment by segment
+type encryptingReader struct {
+	env      *envelope
+	source   *bufio.Reader
+	segment  int64
+	pending  []byte
+	finished bool
+}
+
+// seekingEncryptingReader is an encryptingReader over a source that can seek,
+// it can seek in turn so the body of a request can be measured and read again
+type seekingEncryptingReader struct {
+	encryptingReader
+	seeker io.ReadSeeker
+	size   int64
+}
+
+// encryptReader returns a reader of the ciphertext of the source,
+// it implements io.ReadSeeker when the source does
+func encryptReader(env *envelope, source io.Reader) (io.Reader, error) {
+	reader := encryptingReader{env: env, source: bufio.NewReaderSize(source, int(env.segmentSize))}
+	seeker, ok := source.(io.ReadSeeker)
+	if !ok {
+		return &reader, nil
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = seeker.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return &seekingEncryptingReader{encryptingReader: reader, seeker: seeker, size: end - start}, nil
+}
+
+func (reader *encryptingReader) Read(p []byte) (int, error) {
+	for len(reader.pending) == 0 {
+		if reader.finished {
+			return 0, io.EOF
+		}
+		if err := reader.sealSegment(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, reader.pending)
+	reader.pending = reader.pending[n:]
+	return n, nil
+}
+
+// sealSegment reads the plaintext of the next segment and seals it,
+// the segment is the final one when nothing follows it
+func (reader *encryptingReader) sealSegment() error {
+	plaintext := make([]byte, reader.env.segmentSize)
+	n, err := io.ReadFull(reader.source, plaintext)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	final := int64(n) < reader.env.segmentSize
+	if !final {
+		if _, err = reader.source.Peek(1); err == io.EOF {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+	reader.pending = reader.env.aead.Seal(nil, reader.env.segmentNonce(reader.segment), plaintext[:n],
+		segmentData(reader.segment, final))
+	reader.segment++
+	reader.finished = final
+	return nil
+}
+
+func (reader *seekingEncryptingReader) Seek(offset int64, whence int) (int64, error) {
+	ciphertextSize := reader.env.ciphertextSize(reader.size)
+	switch whence {
+	case io.SeekCurrent:
+		offset += reader.position()
+	case io.SeekEnd:
+		offset += ciphertextSize
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position: %d", offset)
+	}
+	if offset >= ciphertextSize {
+		reader.pending = nil
+		reader.finished = true
+		return offset, nil
+	}
+
+	// Read again the segment holding the offset
+	segment := offset / reader.env.stride()
+	if _, err := reader.seeker.Seek(segment*reader.env.segmentSize-reader.size, io.SeekEnd); err != nil {
+		return 0, err
+	}
+	reader.source.Reset(reader.seeker)
+	reader.segment = segment
+	reader.finished = false
+	if err := reader.sealSegment(); err != nil {
+		return 0, err
+	}
+	reader.pending = reader.pending[offset-segment*reader.env.stride():]
+	return offset, nil
+}
+
+// position returns the offset of the next byte read in the ciphertext
+func (reader *seekingEncryptingReader) position() int64 {
+	if reader.finished && len(reader.pending) == 0 {
+		return reader.env.ciphertextSize(reader.size)
+	}
+	return reader.segment*reader.env.stride() - int64(len(reader.pending))
+}
+
+// encryptBody wraps the body of an upload to encrypt it with a new data key,
+// the metadata of the upload records the wrapped data key
+func encryptBody(masterKey []byte, metadata map[string]*string, body io.Reader) (map[string]*string, io.Reader, error) {
+	env, envelopeMetadata, err := newEnvelope(masterKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if metadata == nil {
+		metadata = make(map[string]*string, len(envelopeMetadata))
+	}
+	for key, value := range envelopeMetadata {
+		metadata[key] = value
+	}
+	if body == nil {
+		body = bytes.NewReader(nil)
+	}
+	encrypted, err := encryptReader(env, body)
+	return metadata, encrypted, err
+}
+
+// decryptingWriter decrypts the segments of a ciphertext written to it in order,
+// writing the plaintext between skip and limit to the underlying writer.
+// The last segment written ends the object when final is set.
+type decryptingWriter struct {
+	w       io.Writer
+	env     *envelope
+	segment int64
+	last    int64
+	final   bool
+	buffer  []byte
+	skip    int64
+	limit   int64
+	written int64
+}
+
+func (writer *decryptingWriter) Write(p []byte) (int, error) {
+	consumed := len(p)
+	stride := int(writer.env.stride())
+	for len(p) > 0 {
+		n := stride - len(writer.buffer)
+		if n > len(p) {
+			n = len(p)
+		}
+		writer.buffer = append(writer.buffer, p[:n]...)
+		p = p[n:]
+		// the final segment may be shorter, it is opened on close
+		if len(writer.buffer) == stride && writer.segment < writer.last {
+			if err := writer.openSegment(false); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return consumed, nil
+}
+
+// Close opens the last segment
+func (writer *decryptingWriter) Close() error {
+	if writer.segment != writer.last || len(writer.buffer) < writer.env.aead.Overhead() {
+		return awserr.New(errCodeDecryption, "the object is truncated", nil)
+	}
+	return writer.openSegment(writer.final)
+}
+
+// openSegment decrypts the buffered segment and writes the requested part of its plaintext
+func (writer *decryptingWriter) openSegment(final bool) error {
+	plaintext, err := writer.env.aead.Open(writer.buffer[:0], writer.env.segmentNonce(writer.segment),
+		writer.buffer, segmentData(writer.segment, final))
+	if err != nil {
+		return awserr.New(errCodeDecryption, "the object was altered or the master key is not the one it was encrypted with", err)
+	}
+	writer.segment++
+	if writer.skip > 0 {
+		skip := writer.skip
+		if skip > int64(len(plaintext)) {
+			skip = int64(len(plaintext))
+		}
+		plaintext = plaintext[skip:]
+		writer.skip -= skip
+	}
+	if remaining := writer.limit - writer.written; int64(len(plaintext)) > remaining {
+		plaintext = plaintext[:remaining]
+	}
+	n, err := writer.w.Write(plaintext)
+	writer.written += int64(n)
+	writer.buffer = writer.buffer[:0]
+	return err
+}
+
+// parseByteRange returns the first and the last byte, included, of a bytes=start-end range
+// over a content of the given size, the whole content when the range is empty
+func parseByteRange(byteRange string, size int64) (start, end int64, err error) {
+	if byteRange == "" {
+		return 0, size - 1, nil
+	}
+	invalid := awserr.New(errCodeInvalidRange, "invalid range: "+byteRange, nil)
+	spec := strings.TrimPrefix(strings.TrimSpace(byteRange), "bytes=")
+	bounds := strings.SplitN(spec, "-", 2)
+	if spec == byteRange || len(bounds) != 2 {
+		return 0, 0, invalid
+	}
+	switch {
+	case bounds[0] == "":
+		var suffix int64
+		if suffix, err = strconv.ParseInt(bounds[1], 10, 64); err != nil || suffix <= 0 {
+			return 0, 0, invalid
+		}
+		if suffix > size {
+			suffix = size
+		}
+		start, end = size-suffix, size-1
+	default:
+		if start, err = strconv.ParseInt(bounds[0], 10, 64); err != nil {
+			return 0, 0, invalid
+		}
+		end = size - 1
+		if bounds[1] != "" {
+			if end, err = strconv.ParseInt(bounds[1], 10, 64); err != nil || end < start {
+				return 0, 0, invalid
+			}
+			if end >= size {
+				end = size - 1
+			}
+		}
+	}
+	if start >= size {
+		return 0, 0, invalid
+	}
+	return start, end, nil
+}
+
+// getDecoded heads the object and writes it to w, the requested range of its plaintext when it was
+// encrypted on the client with the master key, decompressed according to its Content-Encoding when asked to.
+// The segments holding the range are fetched in parts with concurrent ranged GETs, in a single GET
+// when the part size is not positive, every part guarded by the ETag of the object.
+// It returns the head of the object with the length written.
+func getDecoded(client s3iface.S3API, input *s3.GetObjectInput, masterKey []byte, decompress bool, w io.Writer,
+	partSize int64, concurrency int, tracker *transferProgress) (*s3.GetObjectOutput, error) {
+	head, err := client.HeadObject(&s3.HeadObjectInput{
+		Bucket:            input.Bucket,
+		Key:               input.Key,
+		VersionId:         input.VersionId,
+		IfMatch:           input.IfMatch,
+		IfModifiedSince:   input.IfModifiedSince,
+		IfNoneMatch:       input.IfNoneMatch,
+		IfUnmodifiedSince: input.IfUnmodifiedSince,
+		// an object also encrypted with a customer-provided key is only read with the key
+		SSECustomerAlgorithm: input.SSECustomerAlgorithm,
+		SSECustomerKey:       input.SSECustomerKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var env *envelope
+	if masterKey != nil {
+		if env, err = openEnvelope(masterKey, head.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	// The plaintext is decompressed before it is written
+	var decompressing *decompressingWriter
+	if algorithm := compressionOf(head.ContentEncoding); decompress && algorithm != "" {
+		decompressing = newDecompressingWriter(w, algorithm)
+		w = decompressing
+	}
+
+	// The range of the object to fetch
+	size := aws.Int64Value(head.ContentLength)
+	plaintextSize := size
+	if env != nil {
+		plaintextSize = env.plaintextSize(size)
+	}
+	var first, last int64 = 0, -1
+	if plaintextSize > 0 || input.Range != nil {
+		if first, last, err = parseByteRange(aws.StringValue(input.Range), plaintextSize); err != nil {
+			return nil, err
+		}
+	}
+	start, end := first, last+1
+	writer := w
+	var decrypter *decryptingWriter
+	if env != nil {
+		firstSegment, lastSegment := first/env.segmentSize, last/env.segmentSize
+		if last < first {
+			firstSegment, lastSegment = 0, 0
+		}
+		start = firstSegment * env.stride()
+		end = (lastSegment + 1) * env.stride()
+		if end > size {
+			end = size
+		}
+		decrypter = &decryptingWriter{
+			w:       w,
+			env:     env,
+			segment: firstSegment,
+			last:    lastSegment,
+			final:   end == size,
+			skip:    first - firstSegment*env.segmentSize,
+			limit:   last - first + 1,
+		}
+		writer = decrypter
+	}
+
+	// Parts are aligned on segments
+	if partSize <= 0 || partSize > end-start {
+		partSize = end - start
+	}
+	if env != nil && partSize%env.stride() != 0 {
+		partSize += env.stride() - partSize%env.stride()
+	}
+	getInput := *input
+	getInput.Range = nil
+	download := &orderedDownload{
+		client:      client,
+		input:       &getInput,
+		partSize:    partSize,
+		concurrency: concurrency,
+		tracker:     tracker,
+	}
+	tracker.setSize(end-start, partSize)
+	if end > start {
+		err = download.writeParts(&streamWriter{Writer: writer, tracker: tracker}, start, end, head.ETag)
+	}
+	length := end - start
+	if decrypter != nil && err == nil {
+		err = decrypter.Close()
+		length = decrypter.written
+	}
+	if decompressing != nil {
+		if err != nil {
+			decompressing.abort(err)
+		} else {
+			err = decompressing.Close()
+			length = decompressing.written
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3.GetObjectOutput{
+		AcceptRanges:       head.AcceptRanges,
+		CacheControl:       head.CacheControl,
+		ContentDisposition: head.ContentDisposition,
+		ContentEncoding:    head.ContentEncoding,
+		ContentLanguage:    head.ContentLanguage,
+		ContentLength:      aws.Int64(length),
+		ContentType:        head.ContentType,
+		ETag:               head.ETag,
+		LastModified:       head.LastModified,
+		Metadata:           head.Metadata,
+		VersionId:          head.VersionId,
+	}, nil
+}
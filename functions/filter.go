@@ -0,0 +1,275 @@
+package functions
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/urfave/cli"
+)
+
+// keyFilter selects the keys matching the wildcard --key, when given, any of the --include patterns,
+// all the keys when there is none, and none of the --exclude patterns
+type keyFilter struct {
+	key     *regexp.Regexp
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// newKeyFilter compiles the --include and --exclude patterns of the command
+func newKeyFilter(c *cli.Context) (filter *keyFilter, err error) {
+	filter = new(keyFilter)
+	if filter.include, err = compileGlobs(c, flags.Include); err != nil {
+		return nil, err
+	}
+	if filter.exclude, err = compileGlobs(c, flags.Exclude); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// newKeyPatternFilter compiles the wildcard --key of the command along with its --include and
+// --exclude patterns, and returns the prefix the keys matching it are listed under
+func newKeyPatternFilter(c *cli.Context) (filter *keyFilter, prefix string, err error) {
+	if filter, err = newKeyFilter(c); err != nil {
+		return
+	}
+	pattern := c.String(flags.Key)
+	if filter.key, err = globExpression(pattern, false); err != nil {
+		return nil, "", &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.Key,
+			IError:     err,
+		}
+	}
+	prefix = globPrefix(pattern)
+	return
+}
+
+// compileGlobs compiles the patterns given to a flag
+func compileGlobs(c *cli.Context, flag string) ([]*regexp.Regexp, error) {
+	patterns := c.StringSlice(flag)
+	globs := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		glob, err := globToRegexp(pattern)
+		if err != nil {
+			return nil, &errors.CommandError{
+				CLIContext: c,
+				Cause:      errors.InvalidValue,
+				Flag:       flag,
+				IError:     err,
+			}
+		}
+		globs = append(globs, glob)
+	}
+	return globs, nil
+}
+
+// globToRegexp translates a glob pattern, where * and ? do not match /, ** matches anything,
+// [...] matches a class of characters and \ escapes the character that follows.
+// A pattern without / matches the last element of a key, at any depth.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	return globExpression(pattern, !strings.Contains(pattern, "/"))
+}
+
+// globExpression translates a glob pattern matching whole keys, or the last elements of keys
+// when anyDepth is set
+func globExpression(pattern string, anyDepth bool) (*regexp.Regexp, error) {
+	var expression strings.Builder
+	if anyDepth {
+		expression.WriteString("^(?:.*/)?")
+	} else {
+		expression.WriteString("^")
+	}
+	runes := []rune(pattern)
+	for index := 0; index < len(runes); index++ {
+		switch char := runes[index]; char {
+		case '\\':
+			if index+1 == len(runes) {
+				return nil, path.ErrBadPattern
+			}
+			index++
+			expression.WriteString(regexp.QuoteMeta(string(runes[index])))
+		case '*':
+			if index+1 < len(runes) && runes[index+1] == '*' {
+				expression.WriteString(".*")
+				index++
+			} else {
+				expression.WriteString("[^/]*")
+			}
+		case '?':
+			expression.WriteString("[^/]")
+		case '[':
+			class, end, err := globClass(runes, index)
+			if err != nil {
+				return nil, err
+			}
+			expression.WriteString(class)
+			index = end
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	expression.WriteString("$")
+	return regexp.Compile(expression.String())
+}
+
+// globClass translates the [...] class of characters opening at start and returns the index of
+// its closing ]. A leading ! or ^ negates the class, \ escapes the character that follows and
+// a-z is a range. Like * and ?, a class never matches /.
+func globClass(runes []rune, start int) (class string, end int, err error) {
+	index := start + 1
+	negated := index < len(runes) && (runes[index] == '!' || runes[index] == '^')
+	if negated {
+		index++
+	}
+	// next returns the character at index, unescaped
+	next := func() (rune, bool) {
+		if index < len(runes) && runes[index] == '\\' {
+			index++
+		}
+		if index == len(runes) {
+			return 0, false
+		}
+		char := runes[index]
+		index++
+		return char, true
+	}
+
+	var members strings.Builder
+	count := 0
+	for index < len(runes) && runes[index] != ']' {
+		low, ok := next()
+		if !ok {
+			return "", 0, path.ErrBadPattern
+		}
+		high := low
+		if index+1 < len(runes) && runes[index] == '-' && runes[index+1] != ']' {
+			index++
+			if high, ok = next(); !ok || high < low {
+				return "", 0, path.ErrBadPattern
+			}
+		}
+		count++
+		// the / is taken out of the members of the class
+		if !negated && low <= '/' && '/' <= high {
+			if low < '/' {
+				members.WriteString(classRange(low, '/'-1))
+			}
+			if high > '/' {
+				members.WriteString(classRange('/'+1, high))
+			}
+			continue
+		}
+		members.WriteString(classRange(low, high))
+	}
+	if index == len(runes) || count == 0 {
+		return "", 0, path.ErrBadPattern
+	}
+	switch {
+	case negated:
+		class = "[^/" + members.String() + "]"
+	case members.Len() == 0:
+		// a class of / alone matches nothing
+		class = `[^\x00-\x{10FFFF}]`
+	default:
+		class = "[" + members.String() + "]"
+	}
+	return class, index, nil
+}
+
+// classRange writes the range of characters from low to high as members of a regexp class
+func classRange(low, high rune) string {
+	quote := func(char rune) string {
+		if char < 0x80 && !('a' <= char && char <= 'z' || 'A' <= char && char <= 'Z' || '0' <= char && char <= '9') {
+			return `\x{` + strconv.FormatInt(int64(char), 16) + `}`
+		}
+		return string(char)
+	}
+	if low == high {
+		return quote(low)
+	}
+	return quote(low) + "-" + quote(high)
+}
+
+// isKeyPattern reports whether a key holds wildcards or escaped characters, and selects
+// the keys matching it rather than a single key
+func isKeyPattern(key string) bool {
+	return strings.ContainsAny(key, "*?[\\")
+}
+
+// globPrefix returns the literal beginning of a pattern, before its first wildcard,
+// which all the keys matching the pattern begin with
+func globPrefix(pattern string) string {
+	var prefix strings.Builder
+	runes := []rune(pattern)
+	for index := 0; index < len(runes); index++ {
+		switch char := runes[index]; char {
+		case '*', '?', '[':
+			return prefix.String()
+		case '\\':
+			if index+1 < len(runes) {
+				index++
+				prefix.WriteRune(runes[index])
+			}
+		default:
+			prefix.WriteRune(char)
+		}
+	}
+	return prefix.String()
+}
+
+// globDirectory returns the part of the prefix of a pattern up to its last /,
+// the keys matching the pattern are relative to it
+func globDirectory(pattern string) string {
+	prefix := globPrefix(pattern)
+	return prefix[:strings.LastIndex(prefix, "/")+1]
+}
+
+// empty reports whether the filter selects every key
+func (filter *keyFilter) empty() bool {
+	return filter.key == nil && len(filter.include) == 0 && len(filter.exclude) == 0
+}
+
+// filterObjects returns the objects selected by the filter, the include and exclude patterns
+// apply to the keys relative to the prefix
+func (filter *keyFilter) filterObjects(objects []*s3.Object, prefix string) []*s3.Object {
+	if filter.empty() {
+		return objects
+	}
+	selected := make([]*s3.Object, 0, len(objects))
+	for _, object := range objects {
+		key := aws.StringValue(object.Key)
+		if filter.key != nil && !filter.key.MatchString(key) {
+			continue
+		}
+		if filter.matches(strings.TrimPrefix(key, prefix)) {
+			selected = append(selected, object)
+		}
+	}
+	return selected
+}
+
+// matches reports whether the key, relative to the prefix the command works on,
+// is selected by the include and exclude patterns
+func (filter *keyFilter) matches(key string) bool {
+	if len(filter.include) > 0 && !matchAny(filter.include, key) {
+		return false
+	}
+	return !matchAny(filter.exclude, key)
+}
+
+func matchAny(globs []*regexp.Regexp, key string) bool {
+	for _, glob := range globs {
+		if glob.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package functions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/bluemix/terminal"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
+	"github.com/IBM/ibmcloud-cos-cli/config/fields"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/render"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// MultipartUploadsCleanup aborts the incomplete multipart uploads of a bucket initiated before
+// a given age, optionally under a prefix, and reports the size of the parts deleted.
+// Parameter:
+//
+//	CLI Context Application
+//
+// Returns:
+//
+//	Error = zero or non-zero
+func MultipartUploadsCleanup(c *cli.Context) (err error) {
+	// check the number of arguments
+	if c.NArg() > 0 {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidNArg,
+		}
+		return
+	}
+
+	// Load COS Context
+	var cosContext *utils.CosContext
+	if cosContext, err = GetCosContext(c); err != nil {
+		return
+	}
+
+	// Build ListMultipartUploadsInput
+	input := new(s3.ListMultipartUploadsInput)
+	mandatory := map[string]string{
+		fields.Bucket: flags.Bucket,
+	}
+	options := map[string]string{
+		fields.Prefix: flags.Prefix,
+	}
+	if err = MapToSDKInput(c, input, mandatory, options); err != nil {
+		return
+	}
+	bucket := aws.StringValue(input.Bucket)
+
+	// The age of the uploads to abort is required
+	if !c.IsSet(flags.OlderThan) {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.MissingRequiredFlag,
+			Flag:       flags.OlderThan,
+		}
+		return
+	}
+	var age time.Duration
+	if age, err = parseAge(c.String(flags.OlderThan)); err != nil {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.OlderThan,
+			IError:     err,
+		}
+		return
+	}
+
+	// Number of uploads aborted in parallel
+	var jobs int
+	if jobs, err = parseJobs(c); err != nil {
+		return
+	}
+
+	// Setting client to do the calls
+	var client s3iface.S3API
+	if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+		return
+	}
+
+	// List the uploads of every page initiated before the cutoff
+	cutoff := time.Now().Add(-age)
+	items := make([]*render.TransferItem, 0)
+	if err = client.ListMultipartUploadsPages(input, func(page *s3.ListMultipartUploadsOutput, _ bool) bool {
+		for _, upload := range page.Uploads {
+			if upload.Initiated == nil || !upload.Initiated.Before(cutoff) {
+				continue
+			}
+			items = append(items, &render.TransferItem{
+				Action:   render.ActionAbort,
+				Key:      aws.StringValue(upload.Key),
+				UploadId: aws.StringValue(upload.UploadId),
+			})
+		}
+		return true
+	}); err != nil {
+		return
+	}
+
+	// Ask for confirmation before aborting the uploads (prevent accidental deletions)
+	dryRun := c.Bool(flags.DryRun)
+	if !dryRun && len(items) > 0 && !c.Bool(flags.Force) {
+		confirmed := false
+		cosContext.UI.Warn(render.WarningAbortUploads(bucket, len(items), c.String(flags.OlderThan)))
+		cosContext.UI.Prompt(render.MessageConfirmationContinue(), &terminal.PromptOptions{}).Resolve(&confirmed)
+		if !confirmed {
+			cosContext.UI.Say(render.MessageOperationCanceled())
+			return
+		}
+	}
+
+	// The workers size the parts of the uploads and abort them, on a dry run they are only sized
+	queue := make(chan *render.TransferItem)
+	var workers sync.WaitGroup
+	for worker := 0; worker < jobs; worker++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for item := range queue {
+				item.Size = sizeUploadParts(client, input.Bucket, item)
+				if dryRun {
+					continue
+				}
+				if _, abortErr := client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+					Bucket:   input.Bucket,
+					Key:      aws.String(item.Key),
+					UploadId: aws.String(item.UploadId),
+				}); abortErr != nil {
+					item.Error = abortErr.Error()
+				}
+			}
+		}()
+	}
+	for _, item := range items {
+		queue <- item
+	}
+	close(queue)
+	workers.Wait()
+
+	// Output the summary of the uploads aborted and the bytes reclaimed
+	output := newTransferSummary(render.ActionAbort, bucket, items)
+	output.DryRun = dryRun
+	if err = cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input, output, nil); err != nil {
+		return
+	}
+
+	if output.Failed > 0 {
+		err = transferIncompleteError(output.Failed)
+	}
+
+	// Return
+	return
+}
+
+// sizeUploadParts returns the total size of the parts uploaded so far. A listing which fails
+// does not prevent the upload from being aborted, only its size is not counted.
+func sizeUploadParts(client s3iface.S3API, bucket *string, item *render.TransferItem) (size int64) {
+	client.ListPartsPages(&s3.ListPartsInput{
+		Bucket:   bucket,
+		Key:      aws.String(item.Key),
+		UploadId: aws.String(item.UploadId),
+	}, func(page *s3.ListPartsOutput, _ bool) bool {
+		for _, part := range page.Parts {
+			size += aws.Int64Value(part.Size)
+		}
+		return true
+	})
+	return
+}
+
+// parseAge parses an age in days (7d) or as a duration (12h, 30m)
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("invalid age: %s", value)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age: %s", value)
+	}
+	return age, nil
+}
@@ -0,0 +1,181 @@
+//+build unit
+
+package functions_test
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibmcloud-cos-cli/config"
+	"github.com/IBM/ibmcloud-cos-cli/config/commands"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/cos"
+	"github.com/IBM/ibmcloud-cos-cli/di/providers"
+	"github.com/IBM/ibmcloud-cos-cli/render"
+)
+
+// mockStaleUploads lists two pages of uploads, two of them older than a week
+func mockStaleUploads(listed **s3.ListMultipartUploadsInput) {
+	now := time.Now()
+	providers.MockS3API.
+		On("ListMultipartUploadsPages", mock.MatchedBy(func(input *s3.ListMultipartUploadsInput) bool {
+			*listed = input
+			return true
+		}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListMultipartUploadsOutput, last bool) bool)
+			pager(&s3.ListMultipartUploadsOutput{Uploads: []*s3.MultipartUpload{
+				{Key: aws.String("tmp/old"), UploadId: aws.String("u1"), Initiated: aws.Time(now.Add(-30 * 24 * time.Hour))},
+				{Key: aws.String("tmp/new"), UploadId: aws.String("u2"), Initiated: aws.Time(now.Add(-time.Hour))},
+			}}, false)
+			pager(&s3.ListMultipartUploadsOutput{Uploads: []*s3.MultipartUpload{
+				{Key: aws.String("tmp/older"), UploadId: aws.String("u3"), Initiated: aws.Time(now.Add(-8 * 24 * time.Hour))},
+			}}, true)
+		}).
+		Return(nil)
+
+	providers.MockS3API.
+		On("ListPartsPages", mock.AnythingOfType("*s3.ListPartsInput"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListPartsOutput, last bool) bool)
+			pager(&s3.ListPartsOutput{Parts: []*s3.Part{
+				{PartNumber: aws.Int64(1), Size: aws.Int64(5 << 20)},
+				{PartNumber: aws.Int64(2), Size: aws.Int64(5 << 20)},
+			}}, true)
+		}).
+		Return(nil)
+}
+
+func TestMultipartUploadsCleanupAbortsStaleUploads(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "CleanupBucket"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	var listed *s3.ListMultipartUploadsInput
+	mockStaleUploads(&listed)
+
+	var lock sync.Mutex
+	aborted := make([]string, 0)
+	providers.MockS3API.
+		On("AbortMultipartUpload", mock.MatchedBy(func(input *s3.AbortMultipartUploadInput) bool {
+			lock.Lock()
+			defer lock.Unlock()
+			aborted = append(aborted, aws.StringValue(input.UploadId))
+			return aws.StringValue(input.Bucket) == targetBucket
+		})).
+		Return(new(s3.AbortMultipartUploadOutput), nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-",
+		commands.MultipartUploadsCleanup,
+		"--" + flags.Bucket, targetBucket,
+		"--" + flags.OlderThan, "7d",
+		"--" + flags.Prefix, "tmp/",
+		"--" + flags.Force,
+		"--" + flags.Region, "REG",
+		"--" + flags.Output, "json",
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode)
+	if assert.NotNil(t, listed) {
+		assert.Equal(t, "tmp/", aws.StringValue(listed.Prefix))
+	}
+	// only the uploads initiated more than a week ago are aborted
+	assert.ElementsMatch(t, []string{"u1", "u3"}, aborted)
+	providers.MockS3API.AssertNumberOfCalls(t, "ListPartsPages", 2)
+
+	output := new(render.TransferSummaryOutput)
+	assert.NoError(t, json.Unmarshal([]byte(providers.FakeUI.Outputs()), output))
+	assert.Equal(t, render.ActionAbort, output.Action)
+	assert.Equal(t, 2, output.Succeeded)
+	assert.Equal(t, int64(20<<20), output.TotalBytes)
+}
+
+func TestMultipartUploadsCleanupDryRun(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	var listed *s3.ListMultipartUploadsInput
+	mockStaleUploads(&listed)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-",
+		commands.MultipartUploadsCleanup,
+		"--" + flags.Bucket, "CleanupBucket",
+		"--" + flags.OlderThan, "168h",
+		"--" + flags.DryRun,
+		"--" + flags.Region, "REG",
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode)
+	providers.MockS3API.AssertNotCalled(t, "AbortMultipartUpload", mock.Anything)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "tmp/old")
+	assert.Contains(t, output, "tmp/older")
+	assert.NotContains(t, output, "tmp/new")
+	assert.Contains(t, output, "Dry run: 2 multipart upload(s) matched in bucket 'CleanupBucket' (20.00 MiB).")
+}
+
+func TestMultipartUploadsCleanupInvalidAge(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-",
+		commands.MultipartUploadsCleanup,
+		"--" + flags.Bucket, "CleanupBucket",
+		"--" + flags.OlderThan, "a week",
+		"--" + flags.Region, "REG",
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "ListMultipartUploadsPages", mock.Anything, mock.Anything)
+	assert.Contains(t, providers.FakeUI.Errors(), "The value in flag '--older-than' is invalid")
+}
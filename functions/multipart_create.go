@@ -58,6 +58,8 @@ func MultipartCreate(c *cli.Context) (err error) {
 		fields.Metadata:                flags.Metadata,
 		fields.Tagging:                 flags.Tagging,
 		fields.WebsiteRedirectLocation: flags.WebsiteRedirectLocation,
+		fields.SSECustomerAlgorithm:    flags.SSECustomerAlgorithm,
+		fields.SSECustomerKey:          flags.SSECustomerKey,
 	}
 
 	// Check through user inputs for validation
@@ -6,6 +6,7 @@ import (
 	"github.com/IBM/ibmcloud-cos-cli/config/fields"
 	"github.com/IBM/ibmcloud-cos-cli/config/flags"
 	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/render"
 	"github.com/IBM/ibmcloud-cos-cli/utils"
 	"github.com/urfave/cli"
 )
@@ -95,6 +96,13 @@ func MultiPartList(c *cli.Context) (err error) {
 		return
 	}
 
+	// Stream the entries of every page as it arrives
+	if isNDJSON(c) {
+		stream := render.NewNDJSONRender(cosContext.UI)
+		return finishStream(stream, client.ListMultipartUploadsPages(pageIterInput,
+			listMultipartUploadsStream(paginationHelper, stream)))
+	}
+
 	// List Multipart Uploads Op
 	output := new(s3.ListMultipartUploadsOutput)
 	if err = client.ListMultipartUploadsPages(pageIterInput,
@@ -0,0 +1,162 @@
+package functions
+
+import (
+	"strings"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/render"
+	"github.com/urfave/cli"
+)
+
+// outputNDJSON streams a listing, one entry per line as soon as its page is received,
+// instead of merging every page before rendering it
+const outputNDJSON = "ndjson"
+
+// isNDJSON tells whether the listing is streamed
+func isNDJSON(c *cli.Context) bool {
+	return strings.EqualFold(c.String(flags.Output), outputNDJSON)
+}
+
+// streamsOutput tells whether the command can stream its listing with --output ndjson
+func streamsOutput(c *cli.Context) bool {
+	for _, flag := range c.Command.Flags {
+		if flag == cli.Flag(flags.FlagOutputStream) {
+			return true
+		}
+	}
+	return false
+}
+
+// finishStream writes the markers to resume the listing after the last page rendered,
+// also when the listing failed, and returns the error of the listing first
+func finishStream(stream *render.NDJSONRender, err error) error {
+	if finishErr := stream.Finish(); err == nil {
+		err = finishErr
+	}
+	return err
+}
+
+// listObjectsStream - Render the objects and common prefixes of every List Objects page as it arrives
+func listObjectsStream(paginationHelper *PaginationHelper, stream *render.NDJSONRender,
+	filter *keyFilter, prefix string) func(*s3.ListObjectsOutput, bool) bool {
+	return func(page *s3.ListObjectsOutput, _ bool) bool {
+		stream.Resume(nil)
+		if aws.BoolValue(page.IsTruncated) {
+			// without delimiter the listing continues after the last key of the page
+			marker := page.NextMarker
+			if marker == nil && len(page.Contents) > 0 {
+				marker = page.Contents[len(page.Contents)-1].Key
+			}
+			stream.Resume(&render.ListingResume{NextMarker: marker})
+		}
+		contents := filter.filterObjects(page.Contents, prefix)
+		for _, object := range contents {
+			if !stream.Entry(object) {
+				return false
+			}
+		}
+		for _, commonPrefix := range page.CommonPrefixes {
+			if !stream.Entry(commonPrefix) {
+				return false
+			}
+		}
+		return paginationHelper.UpdateTotal(len(contents))
+	}
+}
+
+// listObjectsV2Stream - Render the objects and common prefixes of every List Objects V2 page as it arrives
+func listObjectsV2Stream(paginationHelper *PaginationHelper, stream *render.NDJSONRender,
+	filter *keyFilter, prefix string) func(*s3.ListObjectsV2Output, bool) bool {
+	return func(page *s3.ListObjectsV2Output, _ bool) bool {
+		stream.Resume(nil)
+		if aws.BoolValue(page.IsTruncated) {
+			stream.Resume(&render.ListingResume{NextContinuationToken: page.NextContinuationToken})
+		}
+		contents := filter.filterObjects(page.Contents, prefix)
+		for _, object := range contents {
+			if !stream.Entry(object) {
+				return false
+			}
+		}
+		for _, commonPrefix := range page.CommonPrefixes {
+			if !stream.Entry(commonPrefix) {
+				return false
+			}
+		}
+		return paginationHelper.UpdateTotal(len(contents))
+	}
+}
+
+// listObjectVersionsStream - Render the versions, delete markers and common prefixes
+// of every List Object Versions page as it arrives
+func listObjectVersionsStream(paginationHelper *PaginationHelper,
+	stream *render.NDJSONRender) func(*s3.ListObjectVersionsOutput, bool) bool {
+	return func(page *s3.ListObjectVersionsOutput, _ bool) bool {
+		stream.Resume(nil)
+		if aws.BoolValue(page.IsTruncated) {
+			stream.Resume(&render.ListingResume{
+				NextKeyMarker:       page.NextKeyMarker,
+				NextVersionIdMarker: page.NextVersionIdMarker,
+			})
+		}
+		for _, version := range page.Versions {
+			if !stream.Entry(version) {
+				return false
+			}
+		}
+		for _, deleteMarker := range page.DeleteMarkers {
+			if !stream.Entry(&render.DeleteMarkerEntry{DeleteMarkerEntry: deleteMarker, DeleteMarker: true}) {
+				return false
+			}
+		}
+		for _, commonPrefix := range page.CommonPrefixes {
+			if !stream.Entry(commonPrefix) {
+				return false
+			}
+		}
+		return paginationHelper.UpdateTotal(len(page.Versions) + len(page.DeleteMarkers))
+	}
+}
+
+// listMultipartUploadsStream - Render the uploads and common prefixes of every List Multipart Uploads page as it arrives
+func listMultipartUploadsStream(paginationHelper *PaginationHelper,
+	stream *render.NDJSONRender) func(*s3.ListMultipartUploadsOutput, bool) bool {
+	return func(page *s3.ListMultipartUploadsOutput, _ bool) bool {
+		stream.Resume(nil)
+		if aws.BoolValue(page.IsTruncated) {
+			stream.Resume(&render.ListingResume{
+				NextKeyMarker:      page.NextKeyMarker,
+				NextUploadIdMarker: page.NextUploadIdMarker,
+			})
+		}
+		for _, upload := range page.Uploads {
+			if !stream.Entry(upload) {
+				return false
+			}
+		}
+		for _, commonPrefix := range page.CommonPrefixes {
+			if !stream.Entry(commonPrefix) {
+				return false
+			}
+		}
+		return paginationHelper.UpdateTotal(len(page.Uploads))
+	}
+}
+
+// listPartsStream - Render the parts of every List Parts page as it arrives
+func listPartsStream(paginationHelper *PaginationHelper, stream *render.NDJSONRender) func(*s3.ListPartsOutput, bool) bool {
+	return func(page *s3.ListPartsOutput, _ bool) bool {
+		stream.Resume(nil)
+		if aws.BoolValue(page.IsTruncated) {
+			stream.Resume(&render.ListingResume{NextPartNumberMarker: page.NextPartNumberMarker})
+		}
+		for _, part := range page.Parts {
+			if !stream.Entry(part) {
+				return false
+			}
+		}
+		return paginationHelper.UpdateTotal(len(page.Parts))
+	}
+}
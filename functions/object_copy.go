@@ -16,7 +16,8 @@ import (
 )
 
 // ObjectCopy copies a file from one bucket to another. The source and destination
-// buckets must be in the same region.
+// buckets must be in the same region. Sources larger than the multipart threshold
+// are copied part by part.
 // Parameter:
 //   	CLI Context Application
 // Returns:
@@ -52,20 +53,24 @@ func ObjectCopy(c *cli.Context) (err error) {
 	//
 	// Optional parameters for CopyObject
 	options := map[string]string{
-		fields.CacheControl:                flags.CacheControl,
-		fields.ContentDisposition:          flags.ContentDisposition,
-		fields.ContentEncoding:             flags.ContentEncoding,
-		fields.ContentLanguage:             flags.ContentLanguage,
-		fields.ContentType:                 flags.ContentType,
-		fields.CopySourceIfMatch:           flags.CopySourceIfMatch,
-		fields.CopySourceIfModifiedSince:   flags.CopySourceIfModifiedSince,
-		fields.CopySourceIfNoneMatch:       flags.CopySourceIfNoneMatch,
-		fields.CopySourceIfUnmodifiedSince: flags.CopySourceIfUnmodifiedSince,
-		fields.Metadata:                    flags.Metadata,
-		fields.MetadataDirective:           flags.MetadataDirective,
-		fields.Tagging:                     flags.Tagging,
-		fields.TaggingDirective:            flags.TaggingDirective,
-		fields.WebsiteRedirectLocation:     flags.WebsiteRedirectLocation,
+		fields.CacheControl:                   flags.CacheControl,
+		fields.ContentDisposition:             flags.ContentDisposition,
+		fields.ContentEncoding:                flags.ContentEncoding,
+		fields.ContentLanguage:                flags.ContentLanguage,
+		fields.ContentType:                    flags.ContentType,
+		fields.CopySourceIfMatch:              flags.CopySourceIfMatch,
+		fields.CopySourceIfModifiedSince:      flags.CopySourceIfModifiedSince,
+		fields.CopySourceIfNoneMatch:          flags.CopySourceIfNoneMatch,
+		fields.CopySourceIfUnmodifiedSince:    flags.CopySourceIfUnmodifiedSince,
+		fields.Metadata:                       flags.Metadata,
+		fields.MetadataDirective:              flags.MetadataDirective,
+		fields.Tagging:                        flags.Tagging,
+		fields.TaggingDirective:               flags.TaggingDirective,
+		fields.WebsiteRedirectLocation:        flags.WebsiteRedirectLocation,
+		fields.CopySourceSSECustomerAlgorithm: flags.CopySourceSSECustomerAlgorithm,
+		fields.CopySourceSSECustomerKey:       flags.CopySourceSSECustomerKey,
+		fields.SSECustomerAlgorithm:           flags.SSECustomerAlgorithm,
+		fields.SSECustomerKey:                 flags.SSECustomerKey,
 	}
 
 	// Validate User Inputs
@@ -84,9 +89,21 @@ func ObjectCopy(c *cli.Context) (err error) {
 		return
 	}
 
-	// CopyObject Op
+	// Size above which the copy is done part by part
+	var threshold int64
+	if threshold, err = getCopyThreshold(c); err != nil {
+		return
+	}
+
+	// Head the source to learn its size
+	var head *s3.HeadObjectOutput
+	if head, err = headCopySource(client, input); err != nil {
+		return
+	}
+
+	// CopyObject Op, or a multipart copy for objects too large for a single copy
 	var output *s3.CopyObjectOutput
-	if output, err = client.CopyObject(input); err != nil {
+	if output, err = copyObjectFrom(c, client, input, head, threshold); err != nil {
 		return
 	}
 
@@ -0,0 +1,308 @@
+package functions
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config/fields"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/urfave/cli"
+)
+
+const (
+	// defaultCopyThreshold is the largest object copied with a single CopyObject call
+	defaultCopyThreshold = 5 * 1024 * 1024 * 1024
+	// defaultCopyPartSize is the size of the parts of a multipart copy
+	defaultCopyPartSize = 100 * 1024 * 1024
+)
+
+// parseCopySource splits a copy source <bucket>/<key>?versionId=<version> in its parts,
+// the key may be URL encoded
+func parseCopySource(source string) (bucket, key, versionId string) {
+	if index := strings.Index(source, "?versionId="); index >= 0 {
+		versionId = source[index+len("?versionId="):]
+		source = source[:index]
+	}
+	path := strings.SplitN(strings.TrimPrefix(source, "/"), "/", 2)
+	bucket = path[0]
+	if len(path) > 1 {
+		key = path[1]
+		if unescaped, err := url.PathUnescape(key); err == nil {
+			key = unescaped
+		}
+	}
+	return
+}
+
+// copySourceFor builds the copy source of a key, URL encoded
+func copySourceFor(bucket, key, versionId string) string {
+	segments := strings.Split(key, "/")
+	for index, segment := range segments {
+		segments[index] = url.PathEscape(segment)
+	}
+	source := bucket + "/" + strings.Join(segments, "/")
+	if versionId != "" {
+		source += "?versionId=" + versionId
+	}
+	return source
+}
+
+// headCopySource heads the object the copy source of a copy refers to,
+// with the customer-provided key of the source when it has one
+func headCopySource(client s3iface.S3API, copyInput *s3.CopyObjectInput) (*s3.HeadObjectOutput, error) {
+	bucket, key, versionId := parseCopySource(aws.StringValue(copyInput.CopySource))
+	input := &s3.HeadObjectInput{
+		Bucket:               aws.String(bucket),
+		Key:                  aws.String(key),
+		SSECustomerAlgorithm: copyInput.CopySourceSSECustomerAlgorithm,
+		SSECustomerKey:       copyInput.CopySourceSSECustomerKey,
+	}
+	if versionId != "" {
+		input.VersionId = aws.String(versionId)
+	}
+	return client.HeadObject(input)
+}
+
+// copyObjectFrom copies the source object with a single CopyObject call,
+// or part by part when it is larger than the threshold
+func copyObjectFrom(c *cli.Context, client s3iface.S3API, input *s3.CopyObjectInput,
+	head *s3.HeadObjectOutput, threshold int64) (*s3.CopyObjectOutput, error) {
+	if aws.Int64Value(head.ContentLength) > threshold {
+		return multipartCopy(c, client, input, head)
+	}
+	return client.CopyObject(input)
+}
+
+// getCopyThreshold returns the size above which an object is copied with a multipart copy
+func getCopyThreshold(c *cli.Context) (int64, error) {
+	if !c.IsSet(flags.MultipartThreshold) {
+		return defaultCopyThreshold, nil
+	}
+	threshold, err := parseInt64(c.String(flags.MultipartThreshold))
+	if err == nil && threshold < 0 {
+		err = fmt.Errorf("negative size: %d", threshold)
+	}
+	if err != nil {
+		return 0, &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.MultipartThreshold,
+			IError:     err,
+		}
+	}
+	return threshold, nil
+}
+
+// multipartCopy copies the source object part by part with concurrent UploadPartCopy calls,
+// keeping the metadata and the tags of the source unless told to replace them.
+// The multipart upload is aborted when any part fails.
+func multipartCopy(c *cli.Context, client s3iface.S3API, input *s3.CopyObjectInput,
+	head *s3.HeadObjectOutput) (output *s3.CopyObjectOutput, err error) {
+
+	// Part size and concurrency, the Uploader fields hold the options
+	config := &s3manager.Uploader{
+		PartSize:    defaultCopyPartSize,
+		Concurrency: s3manager.DefaultUploadConcurrency,
+	}
+	copyOptions := map[string]string{
+		fields.PartSize:    flags.PartSize,
+		fields.Concurrency: flags.Concurrency,
+	}
+	if err = MapToSDKInput(c, config, map[string]string{}, copyOptions); err != nil {
+		return
+	}
+	size := aws.Int64Value(head.ContentLength)
+	partSize := resumablePartSize(config.PartSize, size, config.MaxUploadParts)
+
+	// The destination gets the metadata of the source, or the one given when replaced
+	create := &s3.CreateMultipartUploadInput{
+		Bucket:                  input.Bucket,
+		Key:                     input.Key,
+		CacheControl:            head.CacheControl,
+		ContentDisposition:      head.ContentDisposition,
+		ContentEncoding:         head.ContentEncoding,
+		ContentLanguage:         head.ContentLanguage,
+		ContentType:             head.ContentType,
+		Metadata:                head.Metadata,
+		WebsiteRedirectLocation: head.WebsiteRedirectLocation,
+		// The object lock of the copy, when asked for
+		ObjectLockLegalHoldStatus: input.ObjectLockLegalHoldStatus,
+		ObjectLockMode:            input.ObjectLockMode,
+		ObjectLockRetainUntilDate: input.ObjectLockRetainUntilDate,
+		// The copy is encrypted with the customer-provided key of the destination
+		SSECustomerAlgorithm: input.SSECustomerAlgorithm,
+		SSECustomerKey:       input.SSECustomerKey,
+	}
+	if strings.EqualFold(aws.StringValue(input.MetadataDirective), s3.MetadataDirectiveReplace) {
+		create.CacheControl = input.CacheControl
+		create.ContentDisposition = input.ContentDisposition
+		create.ContentEncoding = input.ContentEncoding
+		create.ContentLanguage = input.ContentLanguage
+		create.ContentType = input.ContentType
+		create.Metadata = input.Metadata
+	}
+	if input.WebsiteRedirectLocation != nil {
+		create.WebsiteRedirectLocation = input.WebsiteRedirectLocation
+	}
+
+	// The destination gets the tags of the source, or the ones given when replaced
+	if strings.EqualFold(aws.StringValue(input.TaggingDirective), s3.TaggingDirectiveReplace) {
+		create.Tagging = input.Tagging
+	} else if create.Tagging, err = copySourceTagging(client, input.CopySource); err != nil {
+		return
+	}
+
+	var created *s3.CreateMultipartUploadOutput
+	if created, err = client.CreateMultipartUpload(create); err != nil {
+		return
+	}
+
+	// Abort the upload if any part fails, so no parts are left behind
+	var parts []*s3.CompletedPart
+	if parts, err = copyParts(client, input, head, created.UploadId, size, partSize, config.Concurrency); err != nil {
+		client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+			Bucket:   input.Bucket,
+			Key:      input.Key,
+			UploadId: created.UploadId,
+		})
+		return
+	}
+
+	var completed *s3.CompleteMultipartUploadOutput
+	if completed, err = client.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          input.Bucket,
+		Key:             input.Key,
+		UploadId:        created.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	}); err != nil {
+		client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+			Bucket:   input.Bucket,
+			Key:      input.Key,
+			UploadId: created.UploadId,
+		})
+		return
+	}
+
+	output = &s3.CopyObjectOutput{
+		CopyObjectResult:    &s3.CopyObjectResult{ETag: completed.ETag},
+		CopySourceVersionId: head.VersionId,
+		VersionId:           completed.VersionId,
+	}
+	return
+}
+
+// copySourceTagging returns the tags of the source object encoded as the Tagging header
+func copySourceTagging(client s3iface.S3API, copySource *string) (*string, error) {
+	bucket, key, versionId := parseCopySource(aws.StringValue(copySource))
+	input := &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if versionId != "" {
+		input.VersionId = aws.String(versionId)
+	}
+	output, err := client.GetObjectTagging(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(output.TagSet) == 0 {
+		return nil, nil
+	}
+	tags := url.Values{}
+	for _, tag := range output.TagSet {
+		tags.Add(aws.StringValue(tag.Key), aws.StringValue(tag.Value))
+	}
+	return aws.String(tags.Encode()), nil
+}
+
+// copyParts copies the ranges of the source into the parts of the upload with concurrency workers,
+// every part is guarded by the ETag of the source so all the parts come from the same object
+func copyParts(client s3iface.S3API, input *s3.CopyObjectInput, head *s3.HeadObjectOutput, uploadId *string,
+	size, partSize int64, concurrency int) ([]*s3.CompletedPart, error) {
+	if concurrency < 1 {
+		concurrency = s3manager.DefaultUploadConcurrency
+	}
+	ifMatch := input.CopySourceIfMatch
+	if ifMatch == nil {
+		ifMatch = head.ETag
+	}
+
+	count := (size + partSize - 1) / partSize
+	partNumbers := make(chan int64)
+	go func() {
+		defer close(partNumbers)
+		for partNumber := int64(1); partNumber <= count; partNumber++ {
+			partNumbers <- partNumber
+		}
+	}()
+
+	parts := make([]*s3.CompletedPart, 0, count)
+	var lock sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for partNumber := range partNumbers {
+				lock.Lock()
+				failed := firstErr != nil
+				lock.Unlock()
+				if failed {
+					continue
+				}
+
+				start := (partNumber - 1) * partSize
+				end := start + partSize - 1
+				if end >= size {
+					end = size - 1
+				}
+				output, err := client.UploadPartCopy(&s3.UploadPartCopyInput{
+					Bucket:                      input.Bucket,
+					Key:                         input.Key,
+					UploadId:                    uploadId,
+					PartNumber:                  aws.Int64(partNumber),
+					CopySource:                  input.CopySource,
+					CopySourceRange:             aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+					CopySourceIfMatch:           ifMatch,
+					CopySourceIfModifiedSince:   input.CopySourceIfModifiedSince,
+					CopySourceIfNoneMatch:       input.CopySourceIfNoneMatch,
+					CopySourceIfUnmodifiedSince: input.CopySourceIfUnmodifiedSince,
+					// The parts are read and written with the customer-provided keys
+					CopySourceSSECustomerAlgorithm: input.CopySourceSSECustomerAlgorithm,
+					CopySourceSSECustomerKey:       input.CopySourceSSECustomerKey,
+					SSECustomerAlgorithm:           input.SSECustomerAlgorithm,
+					SSECustomerKey:                 input.SSECustomerKey,
+				})
+
+				lock.Lock()
+				if err == nil {
+					parts = append(parts, &s3.CompletedPart{
+						PartNumber: aws.Int64(partNumber),
+						ETag:       output.CopyPartResult.ETag,
+					})
+				} else if firstErr == nil {
+					firstErr = err
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	sort.Slice(parts, func(i, j int) bool {
+		return aws.Int64Value(parts[i].PartNumber) < aws.Int64Value(parts[j].PartNumber)
+	})
+	return parts, nil
+}
@@ -4,7 +4,10 @@ package functions_test
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -37,6 +40,10 @@ func TestObjectCopySunnyPath(t *testing.T) {
 
 	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
 
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(new(s3.HeadObjectOutput).SetContentLength(1024), nil)
+
 	providers.MockS3API.
 		On("CopyObject", mock.MatchedBy(
 			func(input *s3.CopyObjectInput) bool {
@@ -84,6 +91,10 @@ func TestObjectCopyRainyPath(t *testing.T) {
 
 	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
 
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(new(s3.HeadObjectOutput).SetContentLength(1024), nil)
+
 	providers.MockS3API.
 		On("CopyObject", mock.MatchedBy(
 			func(input *s3.CopyObjectInput) bool {
@@ -131,6 +142,10 @@ func TestObjectCopyWithoutCopySource(t *testing.T) {
 
 	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
 
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(new(s3.HeadObjectOutput).SetContentLength(1024), nil)
+
 	providers.MockS3API.
 		On("CopyObject", mock.MatchedBy(
 			func(input *s3.CopyObjectInput) bool {
@@ -182,6 +197,10 @@ func TestObjectCopyWebsiteRedirectLocation(t *testing.T) {
 
 	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
 
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(new(s3.HeadObjectOutput).SetContentLength(1024), nil)
+
 	providers.MockS3API.
 		On("CopyObject", mock.MatchedBy(
 			func(input *s3.CopyObjectInput) bool {
@@ -238,6 +257,10 @@ func TestObjectCopyVersionIdText(t *testing.T) {
 
 	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
 
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(new(s3.HeadObjectOutput).SetContentLength(1024), nil)
+
 	providers.MockS3API.
 		On("CopyObject", mock.MatchedBy(
 			func(input *s3.CopyObjectInput) bool {
@@ -291,6 +314,10 @@ func TestObjectCopyVersionIdJson(t *testing.T) {
 
 	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
 
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(new(s3.HeadObjectOutput).SetContentLength(1024), nil)
+
 	providers.MockS3API.
 		On("CopyObject", mock.MatchedBy(
 			func(input *s3.CopyObjectInput) bool {
@@ -344,6 +371,10 @@ func TestObjectCopyTaggingDirectiveCopy(t *testing.T) {
 	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
 
 	var capturedInput = new(s3.CopyObjectInput)
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(new(s3.HeadObjectOutput).SetContentLength(1024), nil)
+
 	providers.MockS3API.
 		On("CopyObject", mock.MatchedBy(
 			func(input *s3.CopyObjectInput) bool {
@@ -398,6 +429,10 @@ func TestObjectCopyTaggingDirectiveReplace(t *testing.T) {
 	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
 
 	var capturedInput = new(s3.CopyObjectInput)
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(new(s3.HeadObjectOutput).SetContentLength(1024), nil)
+
 	providers.MockS3API.
 		On("CopyObject", mock.MatchedBy(
 			func(input *s3.CopyObjectInput) bool {
@@ -433,3 +468,222 @@ func TestObjectCopyTaggingDirectiveReplace(t *testing.T) {
 	// assert Not Fail
 	assert.NotContains(t, errors, "FAIL")
 }
+
+func TestObjectCopyMultipartPreservesMetadataAndTags(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetKey := "TargetKey"
+	targetCopySource := "SourceBucket/Source%20Key?versionId=v1"
+	partSize := int64(5 * 1024 * 1024)
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	var headCapture *s3.HeadObjectInput
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			headCapture = input
+			return true
+		})).
+		Return(new(s3.HeadObjectOutput).
+			SetContentLength(2*partSize+1).
+			SetContentType("text/plain").
+			SetETag(`"source"`).
+			SetMetadata(map[string]*string{"Owner": aws.String("me")}), nil).
+		Once()
+
+	providers.MockS3API.
+		On("GetObjectTagging", mock.Anything).
+		Return(new(s3.GetObjectTaggingOutput).
+			SetTagSet([]*s3.Tag{{Key: aws.String("project"), Value: aws.String("cos")}}), nil).
+		Once()
+
+	var createCapture *s3.CreateMultipartUploadInput
+	providers.MockS3API.
+		On("CreateMultipartUpload", mock.MatchedBy(func(input *s3.CreateMultipartUploadInput) bool {
+			createCapture = input
+			return true
+		})).
+		Return(new(s3.CreateMultipartUploadOutput).SetUploadId("UploadId"), nil).
+		Once()
+
+	var lock sync.Mutex
+	ranges := make(map[int64]string)
+	providers.MockS3API.
+		On("UploadPartCopy", mock.MatchedBy(func(input *s3.UploadPartCopyInput) bool {
+			lock.Lock()
+			defer lock.Unlock()
+			ranges[aws.Int64Value(input.PartNumber)] = aws.StringValue(input.CopySourceRange)
+			return aws.StringValue(input.CopySourceIfMatch) == `"source"`
+		})).
+		Return(new(s3.UploadPartCopyOutput).
+			SetCopyPartResult(new(s3.CopyPartResult).SetETag(`"part"`)), nil)
+
+	var completeCapture *s3.CompleteMultipartUploadInput
+	providers.MockS3API.
+		On("CompleteMultipartUpload", mock.MatchedBy(func(input *s3.CompleteMultipartUploadInput) bool {
+			completeCapture = input
+			return true
+		})).
+		Return(new(s3.CompleteMultipartUploadOutput).SetETag(`"copy-3"`), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectCopy, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.CopySource, targetCopySource,
+		"--" + flags.MultipartThreshold, strconv.FormatInt(partSize, 10),
+		"--" + flags.PartSize, strconv.FormatInt(partSize, 10),
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	providers.MockS3API.AssertNotCalled(t, "CopyObject", mock.Anything)
+	assert.Equal(t, "SourceBucket", aws.StringValue(headCapture.Bucket))
+	assert.Equal(t, "Source Key", aws.StringValue(headCapture.Key))
+	assert.Equal(t, "v1", aws.StringValue(headCapture.VersionId))
+	// the metadata and the tags of the source are kept
+	assert.Equal(t, "text/plain", aws.StringValue(createCapture.ContentType))
+	assert.Equal(t, "me", aws.StringValue(createCapture.Metadata["Owner"]))
+	assert.Equal(t, "project=cos", aws.StringValue(createCapture.Tagging))
+	// every range of the source is copied once
+	assert.Equal(t, map[int64]string{
+		1: fmt.Sprintf("bytes=0-%d", partSize-1),
+		2: fmt.Sprintf("bytes=%d-%d", partSize, 2*partSize-1),
+		3: fmt.Sprintf("bytes=%d-%d", 2*partSize, 2*partSize),
+	}, ranges)
+	assert.Len(t, completeCapture.MultipartUpload.Parts, 3)
+	for index, part := range completeCapture.MultipartUpload.Parts {
+		assert.Equal(t, int64(index+1), aws.Int64Value(part.PartNumber))
+	}
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "OK")
+}
+
+func TestObjectCopyMultipartAbortsOnFailure(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetKey := "TargetKey"
+	targetCopySource := "SourceBucket/SourceKey"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(new(s3.HeadObjectOutput).SetContentLength(100), nil).
+		Once()
+
+	providers.MockS3API.
+		On("CreateMultipartUpload", mock.Anything).
+		Return(new(s3.CreateMultipartUploadOutput).SetUploadId("UploadId"), nil).
+		Once()
+
+	providers.MockS3API.
+		On("UploadPartCopy", mock.Anything).
+		Return(nil, errors.New("NoSuchKey")).
+		Once()
+
+	providers.MockS3API.
+		On("AbortMultipartUpload", mock.MatchedBy(func(input *s3.AbortMultipartUploadInput) bool {
+			return aws.StringValue(input.UploadId) == "UploadId"
+		})).
+		Return(new(s3.AbortMultipartUploadOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectCopy, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.CopySource, targetCopySource,
+		"--" + flags.MultipartThreshold, "10",
+		"--" + flags.TaggingDirective, s3.TaggingDirectiveReplace,
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "CompleteMultipartUpload", mock.Anything)
+	providers.MockS3API.AssertNumberOfCalls(t, "AbortMultipartUpload", 1)
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, errors, "FAIL")
+}
+
+func TestObjectCopyCustomerKeys(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	sourceKey := "ssssssssssssssssssssssssssssssss"
+	destinationKey := "dddddddddddddddddddddddddddddddd"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// the source is read with its own key
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			return aws.StringValue(input.SSECustomerKey) == sourceKey &&
+				aws.StringValue(input.SSECustomerAlgorithm) == "AES256"
+		})).
+		Return(new(s3.HeadObjectOutput).SetContentLength(1024), nil).
+		Once()
+
+	var copyInput *s3.CopyObjectInput
+	providers.MockS3API.
+		On("CopyObject", mock.MatchedBy(func(input *s3.CopyObjectInput) bool {
+			copyInput = input
+			return true
+		})).
+		Return(new(s3.CopyObjectOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectCopy, "--bucket", "TargetBucket",
+		"--" + flags.Key, "TargetKey",
+		"--" + flags.CopySource, "SourceBucket/SourceKey",
+		"--" + flags.CopySourceSSECustomerKey, sourceKey,
+		"--" + flags.SSECustomerKey, destinationKey,
+		"--" + flags.SSECustomerAlgorithm, "AES256",
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	providers.MockS3API.AssertExpectations(t)
+	assert.Equal(t, sourceKey, aws.StringValue(copyInput.CopySourceSSECustomerKey))
+	assert.Equal(t, "AES256", aws.StringValue(copyInput.CopySourceSSECustomerAlgorithm))
+	assert.Equal(t, destinationKey, aws.StringValue(copyInput.SSECustomerKey))
+	assert.Equal(t, "AES256", aws.StringValue(copyInput.SSECustomerAlgorithm))
+	// the keys are not rendered
+	output := providers.FakeUI.Outputs()
+	assert.NotContains(t, output, sourceKey)
+	assert.NotContains(t, output, destinationKey)
+}
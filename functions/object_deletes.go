@@ -1,11 +1,17 @@
 package functions
 
 import (
+	"fmt"
+	"sync"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/bluemix/terminal"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
 	"github.com/IBM/ibmcloud-cos-cli/config/fields"
 	"github.com/IBM/ibmcloud-cos-cli/config/flags"
 	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/render"
 	"github.com/IBM/ibmcloud-cos-cli/utils"
 	"github.com/urfave/cli"
 )
@@ -35,6 +41,30 @@ func ObjectDeletes(c *cli.Context) (err error) {
 		return
 	}
 
+	// Delete the objects matching a wildcard key rather than the ones listed
+	if c.IsSet(flags.Key) {
+		for _, flag := range []string{flags.Delete, flags.Prefix} {
+			if c.IsSet(flag) {
+				err = &errors.CommandError{
+					CLIContext: c,
+					Cause:      errors.InvalidValue,
+					Flag:       flag,
+					IError:     fmt.Errorf("--%s cannot be used with --%s", flag, flags.Key),
+				}
+				return
+			}
+		}
+		return deleteMatchingObjects(c, cosContext)
+	}
+
+	// Delete every object under a prefix rather than the ones listed
+	if c.Bool(flags.Recursive) || c.IsSet(flags.Prefix) {
+		if err = validateDeletePrefix(c); err != nil {
+			return
+		}
+		return deletePrefixObjects(c, cosContext)
+	}
+
 	// Set DeleteObjectInput
 	input := new(s3.DeleteObjectsInput)
 
@@ -72,3 +102,250 @@ func ObjectDeletes(c *cli.Context) (err error) {
 	// Return
 	return
 }
+
+// deleteMatchingObjects lists the objects under the literal prefix of the wildcard --key and
+// deletes the ones matching it, along with the --include and --exclude patterns.
+// With --dry-run the matching objects are displayed and nothing is deleted.
+func deleteMatchingObjects(c *cli.Context, cosContext *utils.CosContext) (err error) {
+	// Build ListObjectsV2Input
+	input := new(s3.ListObjectsV2Input)
+	mandatory := map[string]string{
+		fields.Bucket: flags.Bucket,
+	}
+	if err = MapToSDKInput(c, input, mandatory, map[string]string{}); err != nil {
+		return
+	}
+
+	// Keys selected by the patterns
+	var filter *keyFilter
+	var prefix string
+	if filter, prefix, err = newKeyPatternFilter(c); err != nil {
+		return
+	}
+	input.Prefix = aws.String(prefix)
+
+	// Setting client to do the calls
+	var client s3iface.S3API
+	if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+		return
+	}
+
+	// List all the pages under the prefix
+	var objects []*s3.Object
+	if objects, err = listObjectsWithPrefix(client, input); err != nil {
+		return
+	}
+	objects = filter.filterObjects(objects, globDirectory(c.String(flags.Key)))
+
+	items := make([]*render.TransferItem, 0, len(objects))
+	for _, object := range objects {
+		items = append(items, &render.TransferItem{
+			Action: render.ActionDelete,
+			Key:    aws.StringValue(object.Key),
+			Size:   aws.Int64Value(object.Size),
+		})
+	}
+
+	// Ask for confirmation before deleting the objects (prevent accidental deletions)
+	if !c.Bool(flags.DryRun) && len(items) > 0 && !c.Bool(flags.Force) {
+		confirmed := false
+		cosContext.UI.Warn(render.WarningDeleteMatching(aws.StringValue(input.Bucket), c.String(flags.Key), len(items)))
+		cosContext.UI.Prompt(render.MessageConfirmationContinue(), &terminal.PromptOptions{}).Resolve(&confirmed)
+		if !confirmed {
+			cosContext.UI.Say(render.MessageOperationCanceled())
+			return
+		}
+	}
+
+	// Batch Delete Op, per object errors are recorded in the items
+	if !c.Bool(flags.DryRun) && len(items) > 0 {
+		if err = deleteObjectKeys(client, newDeleteObjectsTemplate(c, input.Bucket), items); err != nil {
+			return
+		}
+	}
+
+	// Output the summary, or the preview on a dry run
+	output := newTransferSummary(render.ActionDelete, aws.StringValue(input.Bucket), items)
+	output.DryRun = c.Bool(flags.DryRun)
+	if err = cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input, output, nil); err != nil {
+		return
+	}
+
+	if output.Failed > 0 {
+		err = transferIncompleteError(output.Failed)
+	}
+
+	// Return
+	return
+}
+
+// newDeleteObjectsTemplate returns the input of the batches deleting the listed objects of the bucket,
+// which bypass the governance retention when asked to
+func newDeleteObjectsTemplate(c *cli.Context, bucket *string) *s3.DeleteObjectsInput {
+	template := &s3.DeleteObjectsInput{Bucket: bucket}
+	if c.Bool(flags.BypassGovernanceRetention) {
+		template.BypassGovernanceRetention = aws.Bool(true)
+	}
+	return template
+}
+
+// validateDeletePrefix checks --prefix and --recursive are used together, and without --delete
+func validateDeletePrefix(c *cli.Context) error {
+	if !c.IsSet(flags.Prefix) {
+		return &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.MissingRequiredFlag,
+			Flag:       flags.Prefix,
+		}
+	}
+	if !c.Bool(flags.Recursive) {
+		return &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.MissingRequiredFlag,
+			Flag:       flags.Recursive,
+		}
+	}
+	if c.IsSet(flags.Delete) {
+		return &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.Delete,
+			IError:     fmt.Errorf("--%s cannot be used with --%s", flags.Delete, flags.Recursive),
+		}
+	}
+	return nil
+}
+
+// deletePrefixObjects deletes every object under --prefix selected by the --include and --exclude patterns.
+// The listing is paged and every page is deleted in a DeleteObjects batch while the next one is listed,
+// --jobs batches are sent in parallel. Only the keys which cannot be deleted are kept and displayed.
+// With --dry-run the objects are displayed and nothing is deleted.
+func deletePrefixObjects(c *cli.Context, cosContext *utils.CosContext) (err error) {
+	// Build ListObjectsV2Input
+	input := new(s3.ListObjectsV2Input)
+	mandatory := map[string]string{
+		fields.Bucket: flags.Bucket,
+		fields.Prefix: flags.Prefix,
+	}
+	if err = MapToSDKInput(c, input, mandatory, map[string]string{}); err != nil {
+		return
+	}
+	bucket, prefix := aws.StringValue(input.Bucket), aws.StringValue(input.Prefix)
+
+	// Keys selected by the patterns, relative to the prefix
+	var filter *keyFilter
+	if filter, err = newKeyFilter(c); err != nil {
+		return
+	}
+
+	// Number of batches deleted in parallel
+	var jobs int
+	if jobs, err = parseJobs(c); err != nil {
+		return
+	}
+
+	// Setting client to do the calls
+	var client s3iface.S3API
+	if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+		return
+	}
+
+	// Preview the objects that would be deleted
+	if c.Bool(flags.DryRun) {
+		var objects []*s3.Object
+		if objects, err = listObjectsWithPrefix(client, input); err != nil {
+			return
+		}
+		items := make([]*render.TransferItem, 0, len(objects))
+		for _, object := range filter.filterObjects(objects, prefix) {
+			items = append(items, &render.TransferItem{
+				Action: render.ActionDelete,
+				Key:    aws.StringValue(object.Key),
+				Size:   aws.Int64Value(object.Size),
+			})
+		}
+		output := newTransferSummary(render.ActionDelete, bucket, items)
+		output.DryRun = true
+		return cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input, output, nil)
+	}
+
+	// Ask for confirmation before deleting the objects (prevent accidental deletions)
+	if !c.Bool(flags.Force) {
+		confirmed := false
+		cosContext.UI.Warn(render.WarningDeletePrefix(bucket, prefix))
+		cosContext.UI.Prompt(render.MessageConfirmationContinue(), &terminal.PromptOptions{}).Resolve(&confirmed)
+		if !confirmed {
+			cosContext.UI.Say(render.MessageOperationCanceled())
+			return
+		}
+	}
+
+	// The batches are deleted by the workers, the result of every key is aggregated in the summary
+	output := &render.TransferSummaryOutput{
+		Bucket: bucket,
+		Action: render.ActionDelete,
+		Items:  make([]*render.TransferItem, 0),
+	}
+	template := newDeleteObjectsTemplate(c, input.Bucket)
+	var lock sync.Mutex
+	batches := make(chan []*render.TransferItem, jobs)
+	var workers sync.WaitGroup
+	for worker := 0; worker < jobs; worker++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for batch := range batches {
+				// a batch failing as a whole fails each of its keys
+				if batchErr := deleteObjectKeys(client, template, batch); batchErr != nil {
+					for _, item := range batch {
+						item.Error = batchErr.Error()
+					}
+				}
+				lock.Lock()
+				for _, item := range batch {
+					if item.Error != "" {
+						output.Failed++
+						output.Items = append(output.Items, item)
+						continue
+					}
+					output.Succeeded++
+					output.TotalBytes += item.Size
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+
+	// List the pages, a page holds up to as many keys as a batch
+	err = client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		batch := make([]*render.TransferItem, 0, len(page.Contents))
+		for _, object := range filter.filterObjects(page.Contents, prefix) {
+			batch = append(batch, &render.TransferItem{
+				Action: render.ActionDelete,
+				Key:    aws.StringValue(object.Key),
+				Size:   aws.Int64Value(object.Size),
+			})
+		}
+		if len(batch) > 0 {
+			batches <- batch
+		}
+		return true
+	})
+	close(batches)
+	workers.Wait()
+	if err != nil {
+		return
+	}
+
+	// Output the summary with the keys that failed
+	if err = cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input, output, nil); err != nil {
+		return
+	}
+
+	if output.Failed > 0 {
+		err = transferIncompleteError(output.Failed)
+	}
+
+	// Return
+	return
+}
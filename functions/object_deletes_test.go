@@ -4,6 +4,7 @@ package functions_test
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"testing"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/urfave/cli"
 
 	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3"
 	"github.com/IBM/ibmcloud-cos-cli/config"
 	"github.com/IBM/ibmcloud-cos-cli/config/commands"
@@ -244,3 +246,386 @@ func TestObjectsDeletVersionId(t *testing.T) {
 	// assert Not Fail
 	assert.NotContains(t, errors, "FAIL")
 }
+
+func TestObjectsDeleteWildcardKey(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	var listed *s3.ListObjectsV2Input
+	providers.MockS3API.
+		On("ListObjectsV2Pages", mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+			listed = input
+			return true
+		}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListObjectsV2Output, last bool) bool)
+			pager(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+				{Key: aws.String("logs/a.gz"), Size: aws.Int64(10)},
+				{Key: aws.String("logs/b.gz"), Size: aws.Int64(10)},
+				{Key: aws.String("logs/2024/c.gz"), Size: aws.Int64(10)},
+				{Key: aws.String("logs/d.txt"), Size: aws.Int64(10)},
+			}}, true)
+		}).
+		Return(nil).
+		Once()
+
+	var deleted []string
+	providers.MockS3API.
+		On("DeleteObjects", mock.MatchedBy(func(input *s3.DeleteObjectsInput) bool {
+			for _, object := range input.Delete.Objects {
+				deleted = append(deleted, aws.StringValue(object.Key))
+			}
+			return true
+		})).
+		Return(new(s3.DeleteObjectsOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectsDelete, "--bucket", "TargetBucket",
+		"--" + flags.Key, "logs/*.gz",
+		"--" + flags.Exclude, "b*",
+		"--" + flags.Force,
+		"--" + flags.Region, "REG"}
+	// call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// assert the listing starts at the literal prefix of the pattern
+	assert.Equal(t, "logs/", aws.StringValue(listed.Prefix))
+	assert.Equal(t, []string{"logs/a.gz"}, deleted)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "Deleted 1 of 1 object(s) from bucket 'TargetBucket' (10 B).")
+}
+
+func TestObjectsDeleteWildcardKeyDryRun(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	mockSourceListing("tmp/a", "tmp/b", "keep/c")
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectsDelete, "--bucket", "TargetBucket",
+		"--" + flags.Key, "**",
+		"--" + flags.Include, "tmp/**",
+		"--" + flags.DryRun,
+		"--" + flags.Region, "REG"}
+	// call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero and nothing was deleted
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	providers.MockS3API.AssertNotCalled(t, "DeleteObjects", mock.Anything)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "tmp/a")
+	assert.Contains(t, output, "tmp/b")
+	assert.NotContains(t, output, "keep/c")
+	assert.Contains(t, output, "Dry run: 2 object(s) matched in bucket 'TargetBucket' (20 B).")
+}
+
+func TestObjectsDeleteWildcardKeyCanceled(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+	providers.FakeUI.Inputs("n")
+
+	mockSourceListing("logs/a.gz", "logs/b.gz", "data/c.gz")
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectsDelete, "--bucket", "TargetBucket",
+		"--" + flags.Key, "logs/*",
+		"--" + flags.Region, "REG"}
+	// call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	providers.MockS3API.AssertNotCalled(t, "DeleteObjects", mock.Anything)
+	assert.Contains(t, providers.FakeUI.Errors(), "delete 2 object(s) matching 'logs/*'")
+	assert.Contains(t, providers.FakeUI.Outputs(), "Operation canceled.")
+}
+
+func TestObjectsDeleteWildcardKeyClasses(t *testing.T) {
+	for pattern, matched := range map[string]int{
+		// a negated class does not match /
+		`logs/[!a]*`: 4,
+		// the characters of the class are not regular expression syntax
+		`logs/[\d].gz`: 1,
+		`logs/[+-.].gz`: 1,
+		// a class never matches /, even when listed or in a range
+		`logs/a[/-]b`: 1,
+		`logs/[*-0]*`: 1,
+	} {
+		t.Run(pattern, func(t *testing.T) {
+			defer providers.MocksRESET()
+
+			// --- Arrange ---
+			// disable and capture OS EXIT
+			var exitCode *int
+			cli.OsExiter = func(ec int) {
+				exitCode = &ec
+			}
+
+			providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+			mockSourceListing("logs/a.gz", "logs/b.gz", "logs/d.gz", "logs/1.gz", "logs/+.gz",
+				"logs/a/b", "logs/a-b", "logs//x")
+
+			// --- Act ----
+			// set os args
+			os.Args = []string{"-", commands.ObjectsDelete, "--bucket", "TargetBucket",
+				"--" + flags.Key, pattern,
+				"--" + flags.DryRun,
+				"--" + flags.Region, "REG"}
+			// call plugin
+			plugin.Start(new(cos.Plugin))
+
+			// --- Assert ----
+			assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+			assert.Contains(t, providers.FakeUI.Outputs(),
+				fmt.Sprintf("Dry run: %d object(s) matched in bucket 'TargetBucket' (%d B).", matched, 10*matched))
+		})
+	}
+}
+
+func TestObjectsDeleteKeyWithDelete(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectsDelete, "--bucket", "TargetBucket",
+		"--" + flags.Key, "logs/*",
+		"--" + flags.Delete, "Objects=[{Key=a}]",
+		"--" + flags.Region, "REG"}
+	// call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "DeleteObjects", mock.Anything)
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, errors, "FAIL")
+}
+
+func TestObjectsDeleteRecursivePrefixAggregatesErrors(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	var listed *s3.ListObjectsV2Input
+	providers.MockS3API.
+		On("ListObjectsV2Pages", mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+			listed = input
+			return true
+		}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListObjectsV2Output, last bool) bool)
+			pager(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+				{Key: aws.String("tmp/a"), Size: aws.Int64(10)},
+				{Key: aws.String("tmp/b"), Size: aws.Int64(10)},
+			}}, false)
+			pager(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+				{Key: aws.String("tmp/locked"), Size: aws.Int64(10)},
+			}}, true)
+		}).
+		Return(nil).
+		Once()
+
+	providers.MockS3API.
+		On("DeleteObjects", mock.MatchedBy(func(input *s3.DeleteObjectsInput) bool {
+			return len(input.Delete.Objects) == 2
+		})).
+		Return(new(s3.DeleteObjectsOutput), nil).
+		Once()
+	providers.MockS3API.
+		On("DeleteObjects", mock.MatchedBy(func(input *s3.DeleteObjectsInput) bool {
+			return len(input.Delete.Objects) == 1
+		})).
+		Return(&s3.DeleteObjectsOutput{Errors: []*s3.Error{{
+			Key:     aws.String("tmp/locked"),
+			Code:    aws.String("AccessDenied"),
+			Message: aws.String("Access Denied"),
+		}}}, nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectsDelete, "--bucket", "TargetBucket",
+		"--" + flags.Prefix, "tmp/",
+		"--" + flags.Recursive,
+		"--" + flags.Jobs, "2",
+		"--" + flags.Force,
+		"--" + flags.Region, "REG"}
+	// call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, 1, *exitCode)
+	assert.Equal(t, "tmp/", aws.StringValue(listed.Prefix))
+	// every page is deleted in its own batch
+	providers.MockS3API.AssertNumberOfCalls(t, "DeleteObjects", 2)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "Deleted 2 of 3 object(s) from bucket 'TargetBucket' (20 B).")
+	assert.Contains(t, output, "AccessDenied: Access Denied")
+	// only the keys that failed are listed
+	assert.Contains(t, output, "tmp/locked")
+	assert.NotContains(t, output, "tmp/a")
+}
+
+func TestObjectsDeleteBypassesGovernanceRetentionOfListedObjects(t *testing.T) {
+	selections := map[string][]string{
+		"wildcard key": {"--" + flags.Key, "logs/*"},
+		"prefix":       {"--" + flags.Prefix, "logs/", "--" + flags.Recursive},
+	}
+	for name, selection := range selections {
+		t.Run(name, func(t *testing.T) {
+			defer providers.MocksRESET()
+
+			// --- Arrange ---
+			// disable and capture OS EXIT
+			var exitCode *int
+			cli.OsExiter = func(ec int) {
+				exitCode = &ec
+			}
+
+			providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+			providers.MockS3API.
+				On("ListObjectsV2Pages", mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) {
+					pager := args.Get(1).(func(page *s3.ListObjectsV2Output, last bool) bool)
+					pager(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+						{Key: aws.String("logs/locked"), Size: aws.Int64(10)},
+					}}, true)
+				}).
+				Return(nil).
+				Once()
+
+			var deleteCapture *s3.DeleteObjectsInput
+			providers.MockS3API.
+				On("DeleteObjects", mock.MatchedBy(func(input *s3.DeleteObjectsInput) bool {
+					deleteCapture = input
+					return true
+				})).
+				Return(new(s3.DeleteObjectsOutput), nil).
+				Once()
+
+			// --- Act ----
+			// set os args
+			os.Args = append(append([]string{"-", commands.ObjectsDelete, "--bucket", "TargetBucket"},
+				selection...),
+				"--"+flags.BypassGovernanceRetention,
+				"--"+flags.Force,
+				"--"+flags.Region, "REG")
+			// call plugin
+			plugin.Start(new(cos.Plugin))
+
+			// --- Assert ----
+			// assert exit code is zero and the batch bypassed the governance retention
+			assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+			if assert.NotNil(t, deleteCapture) {
+				assert.True(t, aws.BoolValue(deleteCapture.BypassGovernanceRetention))
+				assert.Equal(t, "TargetBucket", aws.StringValue(deleteCapture.Bucket))
+			}
+		})
+	}
+}
+
+func TestObjectsDeleteRecursivePrefixCanceled(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+	providers.FakeUI.Inputs("n")
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectsDelete, "--bucket", "TargetBucket",
+		"--" + flags.Prefix, "tmp/",
+		"--" + flags.Recursive,
+		"--" + flags.Region, "REG"}
+	// call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	providers.MockS3API.AssertNotCalled(t, "ListObjectsV2Pages", mock.Anything, mock.Anything)
+	providers.MockS3API.AssertNotCalled(t, "DeleteObjects", mock.Anything)
+	assert.Contains(t, providers.FakeUI.Errors(), "every object under the prefix 'tmp/'")
+	assert.Contains(t, providers.FakeUI.Outputs(), "Operation canceled.")
+}
+
+func TestObjectsDeletePrefixWithoutRecursive(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectsDelete, "--bucket", "TargetBucket",
+		"--" + flags.Prefix, "tmp/",
+		"--" + flags.Region, "REG"}
+	// call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "DeleteObjects", mock.Anything)
+	assert.Contains(t, providers.FakeUI.Errors(), "--recursive")
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/IBM/ibmcloud-cos-cli/render"
 	"github.com/IBM/ibmcloud-cos-cli/utils"
 	"github.com/urfave/cli"
-	"gopkg.in/cheggaaa/pb.v1"
 )
 
 // ObjectGet downloads a file from a bucket.
@@ -54,9 +53,9 @@ func ObjectGet(c *cli.Context) (err error) {
 
 	// register a deferred function to remove the file if the get did not complete
 	defer func() {
-		// check that the file download did not complete
-		// and that the file name is not empty
-		if !keepFile && dstPath != "" {
+		// check that the file download did not complete,
+		// that the file name is not empty and that the partial file is not kept to resume later
+		if !keepFile && dstPath != "" && !c.Bool(flags.Resume) {
 			// call the context wrapper to the SO remove function
 			cosContext.Remove(dstPath)
 		}
@@ -87,6 +86,8 @@ func ObjectGet(c *cli.Context) (err error) {
 		fields.ResponseContentType:        flags.ResponseContentType,
 		fields.ResponseExpires:            flags.ResponseExpires,
 		fields.VersionId:                  flags.VersionId,
+		fields.SSECustomerAlgorithm:       flags.SSECustomerAlgorithm,
+		fields.SSECustomerKey:             flags.SSECustomerKey,
 	}
 
 	// populate the input values using the mandatory and optional maps define before
@@ -97,21 +98,63 @@ func ObjectGet(c *cli.Context) (err error) {
 		return
 	}
 
-	// allocate a variable of type io.WriteCloser,
+	// a resumed download sets its own range
+	if err = validateResume(c); err != nil {
+		return
+	}
+
+	// a compressed object is only decompressed from its beginning
+	if err = validateDecompress(c); err != nil {
+		return
+	}
+
+	// the object is decrypted on the client when a master key is given
+	var masterKey []byte
+	if masterKey, err = getMasterKey(c, cosContext, flags.Resume); err != nil {
+		return
+	}
+	decode := masterKey != nil || c.Bool(flags.Decompress)
+
+	// the object is written to the standard output when the destination is -
+	if c.Args().First() == stdStream {
+		if err = validateStdout(c); err != nil {
+			return
+		}
+		if decode {
+			_, err = getDecodedObject(c, cosContext, input, masterKey, cosContext.Stdout(), false)
+			return
+		}
+		return getObjectToStdout(c, cosContext, input)
+	}
+
+	// allocate a variable of type utils.WriteCloser,
 	// that will be used as destination of the download/get operation
-	var file io.WriteCloser
+	var file utils.WriteCloser
 	// calls the auxiliary getAndValidateDownloadPath passing
 	// the Cos Context, the first argument of command invocation and the key
 	// checks if an error occurred or
 	// the file value is empty ( when destination exists and user do not confirm overwrite )
 	if dstPath, file, err = getAndValidateDownloadPath(cosContext, c.Args().First(),
-		aws.StringValue(input.Key), c.IsSet(flags.Force)); err != nil || file == nil {
+		aws.StringValue(input.Key), c.IsSet(flags.Force), c.Bool(flags.Resume)); err != nil || file == nil {
 		// propagate current error value ( can be nil )
 		// and stops any further processing
 		return
 	}
-	// register a deferred function to close the writer on the exit of current scope
-	defer file.Close()
+	// register a deferred function to close the writer on the exit of current scope,
+	// a resumed download may replace it
+	defer func() { file.Close() }()
+
+	// an object encrypted on the client or compressed is decoded while it is written
+	if decode {
+		var output *s3.GetObjectOutput
+		if output, err = getDecodedObject(c, cosContext, input, masterKey, file, true); err != nil {
+			return
+		}
+		keepFile = true
+		err = cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input,
+			&render.GetObjectOutputWrapper{GetObjectOutput: output, DownloadLocation: &dstPath}, nil)
+		return
+	}
 
 	// allocate a variable to hold the S3API
 	var client s3iface.S3API
@@ -123,10 +166,30 @@ func ObjectGet(c *cli.Context) (err error) {
 		return
 	}
 
+	// a resumed download continues after the bytes already written to the destination
+	var resumable *resumableDownload
+	if c.Bool(flags.Resume) {
+		resumable = newResumableDownload(cosContext, client, input, dstPath, file)
+	}
+
 	// allocate a variable to hold the GetObject result
 	var output *s3.GetObjectOutput
-	// invokes the GetObject operation using the client and checks the result
-	if output, err = client.GetObject(input); err != nil {
+	for {
+		if resumable != nil {
+			if _, file, err = resumable.prepare(); err != nil {
+				return
+			}
+		}
+		// invokes the GetObject operation using the client
+		output, err = client.GetObject(input)
+		// the object changed since the download started, start it again
+		if resumable != nil && resumable.restart(err) {
+			continue
+		}
+		break
+	}
+	// checks the result of the GetObject operation
+	if err != nil {
 		// if an error occurs in the GetObject request call
 		// propagate the error and stop any further processing
 		return
@@ -135,28 +198,34 @@ func ObjectGet(c *cli.Context) (err error) {
 	// register a function to close the Body once function gets out of scope
 	defer output.Body.Close()
 
-	// No need to show progress bar when user wants json output,
-	display := cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON))
-	if _, ok := display.(*render.TextRender); ok {
-		// Create a new progress bar with the total content length in bytes
-		bar := pb.New64(*output.ContentLength).SetUnits(pb.U_BYTES)
-		bar.Start()
-		// Write output to both file and bar
-		if _, err = io.Copy(io.MultiWriter(file, bar), output.Body); err != nil {
-			return
-		}
-		bar.Finish()
-	} else {
-		// copy from the response body to the file defined in the command invocation / default destination
-		// and checks no error occurred
-		if _, err = io.Copy(file, output.Body); err != nil {
-			// if error occurred propagate the error
-			return
+	// Limit the bandwidth of the transfer
+	var limiter *bandwidthLimiter
+	if limiter, err = getBandwidthLimiter(c, cosContext); err != nil {
+		return
+	}
+
+	// Report the progress while the response body is written to the file,
+	// as a progress bar or as JSON events when the user wants json output
+	progress := newTransferProgress(c, cosContext, aws.StringValue(input.Key),
+		aws.Int64Value(output.ContentLength), limiter)
+	// copy from the response body to the file defined in the command invocation / default destination
+	// and checks no error occurred
+	_, err = io.Copy(&progressWriter{WriteCloser: file, tracker: progress}, output.Body)
+	progress.finish()
+	if err != nil {
+		// Keep the progress for the next resumed download
+		if resumable != nil {
+			resumable.interrupt()
 		}
+		// if error occurred propagate the error
+		return
 	}
 
 	// flags the file should not be deleted
 	keepFile = true
+	if resumable != nil {
+		resumable.finish()
+	}
 
 	// use a wrapper struct to add a new field
 	objectOutputWrapper := &render.GetObjectOutputWrapper{
@@ -173,18 +242,43 @@ func ObjectGet(c *cli.Context) (err error) {
 
 }
 
+// getDecodedObject gets an object into w with a single GET, decrypting it when it was encrypted on the client
+// and decompressing it when asked to.
+// The progress is not reported on the standard output.
+func getDecodedObject(c *cli.Context, cosContext *utils.CosContext, input *s3.GetObjectInput,
+	masterKey []byte, w io.Writer, showProgress bool) (output *s3.GetObjectOutput, err error) {
+	var client s3iface.S3API
+	if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+		return
+	}
+
+	var limiter *bandwidthLimiter
+	if limiter, err = getBandwidthLimiter(c, cosContext); err != nil {
+		return
+	}
+
+	tracker := newTransferTracker(0, limiter)
+	if showProgress {
+		tracker = newTransferProgress(c, cosContext, aws.StringValue(input.Key), 0, limiter)
+		defer tracker.finish()
+	}
+	return getDecoded(client, input, masterKey, c.Bool(flags.Decompress), w, 0, 1, tracker)
+}
+
 // getAndValidateDownloadPath is an auxiliary function that
 // takes the COS Context, the value of the first argument as destination location and the value of the key
 // checks if the destination location is not empty
 // if not set and/or is empty
 // uses the concatenation of the default download location and the key as the destination location
 // checks if the destination location is writable and not already exists,
-// if exists ask confirmation before overwrite it
+// if exists ask confirmation before overwrite it,
+// unless resuming a download whose state was saved next to the destination location
 func getAndValidateDownloadPath(
 	cosContext *utils.CosContext,
 	outfile string,
 	key string,
 	force bool,
+	resume bool,
 ) (
 	path string,
 	wc utils.WriteCloser,
@@ -219,6 +313,22 @@ func getAndValidateDownloadPath(
 		return
 	}
 
+	// a partial download is continued in place, it is neither confirmed nor truncated
+	if resume && readDownloadState(cosContext, outfile+downloadStateSuffix) != nil {
+		if wc, err = cosContext.WriteCloserOpenExisting(outfile); err != nil {
+			innerError := err
+			err = &errors.ObjectGetError{
+				ParentError:      innerError,
+				Location:         outfile,
+				UsingDefaultRule: usingDefaultRule,
+				Cause:            errors.Opening,
+			}
+			return
+		}
+		path = outfile
+		return
+	}
+
 	// checks that the destination location does not already exists
 	if fileInfo, innerError := cosContext.GetFileInfo(outfile); innerError == nil {
 		// if it exists checks it is not a folder
@@ -3,11 +3,17 @@
 package functions_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -15,6 +21,9 @@ import (
 	"github.com/urfave/cli"
 
 	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3"
 	"github.com/IBM/ibmcloud-cos-cli/config"
 	"github.com/IBM/ibmcloud-cos-cli/config/commands"
@@ -776,3 +785,505 @@ func TestObjectGetTagCountJson(t *testing.T) {
 
 	assert.Equal(t, objectContent, wroteFileContent)
 }
+
+func TestObjectGetResumeContinuesPartialFile(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "GetObjBucket"
+	targetKey := "GetObjKey"
+	targetETag := `"0123456789abcdef"`
+	targetFileName := "/mock/path/MockFileName"
+	stateFileName := targetFileName + ".cos-download"
+	objectContent := "0123456789"
+
+	// the first 4 bytes were written by the interrupted download
+	wroteFileContent := ""
+	partialFile := utils.WriteToString(&wroteFileContent, nil)
+	partialFile.Write([]byte(objectContent[:4]))
+	savedState := `{"Bucket":"` + targetBucket + `","Key":"` + targetKey + `","VersionId":"",` +
+		`"ETag":"\"0123456789abcdef\"","Size":10,"Written":4}`
+
+	var inputCapture *s3.GetObjectInput
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", stateFileName).
+		Return(func(string) utils.ReadSeekerCloser { return utils.WrapString(savedState, nil) }, nil)
+	providers.MockFileOperations.
+		On("WriteCloserOpenExisting", targetFileName).
+		Return(partialFile, nil).
+		Once()
+	providers.MockFileOperations.
+		On("GetFileInfo", targetFileName).
+		Return(utils.FakeFileInfo("MockFileName", 4, time.Now(), false), nil)
+	providers.MockFileOperations.
+		On("WriteCloserOpen", stateFileName).
+		Return(utils.WriteToString(nil, nil), nil)
+	providers.MockFileOperations.
+		On("Remove", stateFileName).
+		Return(nil).
+		Once()
+
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(new(s3.HeadObjectOutput).
+			SetETag(targetETag).
+			SetContentLength(int64(len(objectContent))), nil).
+		Once()
+
+	providers.MockS3API.
+		On("GetObject", mock.MatchedBy(
+			func(input *s3.GetObjectInput) bool {
+				inputCapture = input
+				return true
+			})).
+		Return(new(s3.GetObjectOutput).
+			SetContentLength(int64(len(objectContent)-4)).
+			SetBody(ioutil.NopCloser(strings.NewReader(objectContent[4:]))).
+			SetLastModified(time.Now()), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{
+		"-",
+		commands.ObjectGet,
+		"--" + flags.Bucket, targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.Resume,
+		"--" + flags.Region, "REG",
+		targetFileName,
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	assert.Equal(t, "bytes=4-", *inputCapture.Range)
+	assert.Equal(t, targetETag, *inputCapture.IfMatch)
+	assert.Equal(t, objectContent, wroteFileContent)
+	providers.MockFileOperations.AssertNotCalled(t, "WriteCloserOpen", targetFileName)
+	providers.MockFileOperations.AssertCalled(t, "Remove", stateFileName)
+	// capture all output //
+	output := providers.FakeUI.Outputs()
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, output, "OK")
+	assert.NotContains(t, errors, "FAIL")
+}
+
+func TestObjectGetResumeKeepsPartialFileOnError(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetFileName := "/mock/path/MockFileName"
+	stateFileName := targetFileName + ".cos-download"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", stateFileName).
+		Return(nil, os.ErrNotExist)
+	providers.MockFileOperations.
+		On("GetFileInfo", targetFileName).
+		Return(nil, os.ErrNotExist)
+	providers.MockFileOperations.
+		On("WriteCloserOpen", mock.Anything).
+		Return(utils.WriteToString(nil, nil), nil)
+
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(new(s3.HeadObjectOutput).
+			SetETag(`"etag"`).
+			SetContentLength(10), nil).
+		Once()
+
+	providers.MockS3API.
+		On("GetObject", mock.Anything).
+		Return(nil, os.ErrDeadlineExceeded).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{
+		"-",
+		commands.ObjectGet,
+		"--" + flags.Bucket, "GetObjBucket",
+		"--" + flags.Key, "GetObjKey",
+		"--" + flags.Resume,
+		"--" + flags.Region, "REG",
+		targetFileName,
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero
+	assert.Equal(t, 1, *exitCode)
+	// the partial file and its state are kept for the next attempt
+	providers.MockFileOperations.AssertCalled(t, "WriteCloserOpen", stateFileName)
+	providers.MockFileOperations.AssertNotCalled(t, "Remove", mock.Anything)
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, errors, "FAIL")
+}
+
+func TestObjectGetResumeSavesProgressOnInterruptedBody(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetFileName := "/mock/path/MockFileName"
+	stateFileName := targetFileName + ".cos-download"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", stateFileName).
+		Return(nil, os.ErrNotExist)
+	providers.MockFileOperations.
+		On("GetFileInfo", targetFileName).
+		Return(nil, os.ErrNotExist)
+	state := ""
+	providers.MockFileOperations.
+		On("WriteCloserOpen", stateFileName).
+		Return(func(string) utils.WriteCloser { return utils.WriteToString(&state, nil) }, nil)
+	providers.MockFileOperations.
+		On("WriteCloserOpen", targetFileName).
+		Return(utils.WriteToString(nil, nil), nil)
+
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(new(s3.HeadObjectOutput).
+			SetETag(`"etag"`).
+			SetContentLength(10), nil).
+		Once()
+
+	// the connection is lost after the first bytes of the body
+	providers.MockS3API.
+		On("GetObject", mock.Anything).
+		Return(new(s3.GetObjectOutput).
+			SetBody(ioutil.NopCloser(io.MultiReader(strings.NewReader("01234"),
+				iotest.ErrReader(os.ErrDeadlineExceeded)))).
+			SetContentLength(10), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{
+		"-",
+		commands.ObjectGet,
+		"--" + flags.Bucket, "GetObjBucket",
+		"--" + flags.Key, "GetObjKey",
+		"--" + flags.Resume,
+		"--" + flags.Region, "REG",
+		targetFileName,
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero
+	assert.Equal(t, 1, *exitCode)
+	// the bytes written before the failure are saved for the next attempt
+	assert.Contains(t, state, `"Written":5`)
+	providers.MockFileOperations.AssertNotCalled(t, "Remove", mock.Anything)
+}
+
+func TestObjectGetToStandardOutput(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "GetObjBucket"
+	targetKey := "GetObjKey"
+	objectContent := "magicBytesUnicornsAndFairyDustPurpurin"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	stdout := new(strings.Builder)
+	providers.MockFileOperations.
+		On("Stdout").
+		Return(stdout)
+
+	providers.MockS3API.
+		On("GetObject", mock.Anything).
+		Return(new(s3.GetObjectOutput).
+			SetBody(ioutil.NopCloser(strings.NewReader(objectContent))).
+			SetContentLength(int64(len(objectContent))), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectGet, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.Region, "REG",
+		"-",
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// the object is the only content written to the standard output
+	assert.Equal(t, objectContent, stdout.String())
+	providers.MockFileOperations.AssertNotCalled(t, "WriteCloserOpen", mock.Anything)
+	output := providers.FakeUI.Outputs()
+	assert.NotContains(t, output, "OK")
+}
+
+func TestObjectGetWithWrongMasterKey(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "GetObjBucket"
+	targetKey := "GetObjKey"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "master.key").
+		Return(utils.WrapString(strings.Repeat("k", 32), nil), nil).
+		Once()
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "other.key").
+		Return(utils.WrapString(strings.Repeat("o", 32), nil), nil).
+		Once()
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "plain.txt").
+		Return(utils.WrapString("magicBytesUnicornsAndFairyDustPurpurin", nil), nil).
+		Once()
+
+	stdout := new(strings.Builder)
+	providers.MockFileOperations.
+		On("Stdout").
+		Return(stdout)
+
+	var metadata map[string]*string
+	providers.MockS3API.
+		On("PutObject", mock.Anything).
+		Run(func(args mock.Arguments) {
+			metadata = args.Get(0).(*s3.PutObjectInput).Metadata
+		}).
+		Return(new(s3.PutObjectOutput), nil).
+		Once()
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(func(input *s3.HeadObjectInput) *s3.HeadObjectOutput {
+			return new(s3.HeadObjectOutput).SetContentLength(54).SetMetadata(metadata)
+		}, nil).
+		Once()
+
+	// --- Act ----
+	// upload the object encrypted with a master key, then get it with another one
+	os.Args = []string{"-", commands.ObjectPut, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.Body, "plain.txt",
+		"--" + flags.EncryptionKeyFile, "master.key",
+		"--" + flags.Region, "REG",
+	}
+	plugin.Start(new(cos.Plugin))
+	assert.Equal(t, (*int)(nil), exitCode)
+	os.Args = []string{"-", commands.ObjectGet, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.EncryptionKeyFile, "other.key",
+		"--" + flags.Region, "REG",
+		"-",
+	}
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero
+	assert.Equal(t, 1, *exitCode)
+	// the data key is not unwrapped, nothing is fetched
+	providers.MockS3API.AssertNotCalled(t, "GetObject", mock.Anything)
+	assert.Empty(t, stdout.String())
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, errors, "DecryptionFailed")
+}
+
+func TestObjectGetDecompressesObject(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "GetObjBucket"
+	targetKey := "GetObjKey"
+	objectContent := strings.Repeat("magicBytesUnicornsAndFairyDustPurpurin", 100)
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "plain.txt").
+		Return(utils.WrapString(objectContent, nil), nil).
+		Once()
+
+	stdout := new(strings.Builder)
+	providers.MockFileOperations.
+		On("Stdout").
+		Return(stdout)
+
+	var putInput *s3.PutObjectInput
+	var compressed []byte
+	providers.MockS3API.
+		On("PutObject", mock.Anything).
+		Run(func(args mock.Arguments) {
+			putInput = args.Get(0).(*s3.PutObjectInput)
+			compressed, _ = ioutil.ReadAll(putInput.Body)
+		}).
+		Return(new(s3.PutObjectOutput), nil).
+		Once()
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(func(input *s3.HeadObjectInput) *s3.HeadObjectOutput {
+			return new(s3.HeadObjectOutput).
+				SetContentLength(int64(len(compressed))).
+				SetContentEncoding(aws.StringValue(putInput.ContentEncoding))
+		}, nil).
+		Once()
+	providers.MockS3API.
+		On("GetObject", mock.Anything).
+		Return(func(input *s3.GetObjectInput) *s3.GetObjectOutput {
+			return new(s3.GetObjectOutput).
+				SetBody(ioutil.NopCloser(bytes.NewReader(compressed))).
+				SetContentLength(int64(len(compressed)))
+		}, nil).
+		Once()
+
+	// --- Act ----
+	// upload the object compressed with zstd, then get it decompressed
+	os.Args = []string{"-", commands.ObjectPut, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.Body, "plain.txt",
+		"--" + flags.Compress, "zstd",
+		"--" + flags.Region, "REG",
+	}
+	plugin.Start(new(cos.Plugin))
+	assert.Equal(t, (*int)(nil), exitCode)
+	os.Args = []string{"-", commands.ObjectGet, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.Decompress,
+		"--" + flags.Region, "REG",
+		"-",
+	}
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// the object is stored compressed with its original size
+	assert.Equal(t, "zstd", aws.StringValue(putInput.ContentEncoding))
+	assert.Equal(t, strconv.Itoa(len(objectContent)), aws.StringValue(putInput.Metadata["original-size"]))
+	assert.Less(t, len(compressed), len(objectContent))
+	// the original bytes are restored
+	assert.Equal(t, objectContent, stdout.String())
+}
+
+func TestObjectGetDecompressWithRange(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectGet, "--bucket", "GetObjBucket",
+		"--" + flags.Key, "GetObjKey",
+		"--" + flags.Decompress,
+		"--" + flags.Range, "bytes=0-9",
+		"--" + flags.Region, "REG",
+		"-",
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "GetObject", mock.Anything)
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, errors, "The value in flag '--range' is invalid")
+}
+
+func TestObjectGetKeepsContentEncodingOfStoredObject(t *testing.T) {
+	// --- Arrange ---
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte("stored compressed"))
+	writer.Close()
+
+	acceptEncodings := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncodings[r.Method+" "+r.URL.Path] = r.Header.Get("Accept-Encoding")
+		if r.Method != http.MethodGet || r.URL.Query().Get("list-type") != "" {
+			w.Write([]byte("<ListBucketResult></ListBucketResult>"))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Credentials:      credentials.AnonymousCredentials,
+		Endpoint:         aws.String(server.URL),
+		Region:           aws.String("REG"),
+		S3ForcePathStyle: aws.Bool(true),
+	}))
+	sess.Handlers.Send.PushFrontNamed(utils.IdentityEncodingHandler)
+	client := s3.New(sess)
+
+	// --- Act ----
+	output, err := client.GetObject(&s3.GetObjectInput{Bucket: aws.String("Bucket"), Key: aws.String("Key")})
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(output.Body)
+	_, err = client.ListObjectsV2(&s3.ListObjectsV2Input{Bucket: aws.String("Bucket")})
+	assert.NoError(t, err)
+
+	// --- Assert ----
+	// the object is downloaded as it is stored, the other calls keep the transport compression
+	assert.Equal(t, compressed.Bytes(), body)
+	assert.Equal(t, "identity", acceptEncodings["GET /Bucket/Key"])
+	assert.Equal(t, "gzip", acceptEncodings["GET /Bucket"])
+}
@@ -43,12 +43,14 @@ func ObjectHead(c *cli.Context) (err error) {
 
 	// Optional parameters for HeadObject
 	options := map[string]string{
-		fields.IfMatch:           flags.IfMatch,
-		fields.IfModifiedSince:   flags.IfModifiedSince,
-		fields.IfNoneMatch:       flags.IfNoneMatch,
-		fields.IfUnmodifiedSince: flags.IfUnmodifiedSince,
-		fields.Range:             flags.Range,
-		fields.VersionId:         flags.VersionId,
+		fields.IfMatch:              flags.IfMatch,
+		fields.IfModifiedSince:      flags.IfModifiedSince,
+		fields.IfNoneMatch:          flags.IfNoneMatch,
+		fields.IfUnmodifiedSince:    flags.IfUnmodifiedSince,
+		fields.Range:                flags.Range,
+		fields.VersionId:            flags.VersionId,
+		fields.SSECustomerAlgorithm: flags.SSECustomerAlgorithm,
+		fields.SSECustomerKey:       flags.SSECustomerKey,
 	}
 
 	// Validate User Inputs
@@ -1,11 +1,13 @@
 package functions
 
 import (
+	"github.com/IBM/ibm-cos-sdk-go/aws"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
 	"github.com/IBM/ibmcloud-cos-cli/config/fields"
 	"github.com/IBM/ibmcloud-cos-cli/config/flags"
 	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/render"
 	"github.com/IBM/ibmcloud-cos-cli/utils"
 	"github.com/urfave/cli"
 )
@@ -95,9 +97,31 @@ func ObjectsListV2(c *cli.Context) (err error) {
 		return
 	}
 
+	// Keys selected by the patterns, relative to the prefix
+	var filter *keyFilter
+	if filter, err = newKeyFilter(c); err != nil {
+		return
+	}
+
+	// Stream the entries of every page as it arrives
+	if isNDJSON(c) {
+		stream := render.NewNDJSONRender(cosContext.UI)
+		return finishStream(stream, client.ListObjectsV2Pages(pageIterInput,
+			listObjectsV2Stream(paginationHelper, stream, filter, aws.StringValue(pageIterInput.Prefix))))
+	}
+
 	// List Objects Op
 	output := new(s3.ListObjectsV2Output)
-	if err = client.ListObjectsV2Pages(pageIterInput, ListObjectsV2Itx(paginationHelper, output)); err != nil {
+	pager := ListObjectsV2Itx(paginationHelper, output)
+	if !filter.empty() {
+		merge := pager
+		pager = func(page *s3.ListObjectsV2Output, last bool) bool {
+			page.Contents = filter.filterObjects(page.Contents, aws.StringValue(pageIterInput.Prefix))
+			page.KeyCount = aws.Int64(int64(len(page.Contents)))
+			return merge(page, last)
+		}
+	}
+	if err = client.ListObjectsV2Pages(pageIterInput, pager); err != nil {
 		return
 	}
 
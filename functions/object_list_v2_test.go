@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -306,3 +307,161 @@ func buildListObjectsV2Output(howMany int) *s3.ListObjectsV2Output {
 	}
 	return result
 }
+
+func TestObjectsListV2IncludeExclude(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockS3API.
+		On("ListObjectsV2Pages", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListObjectsV2Output, last bool) bool)
+			pager(&s3.ListObjectsV2Output{
+				Contents: []*s3.Object{
+					{Key: aws.String("in/a.parquet")},
+					{Key: aws.String("in/tmp/b.parquet")},
+				},
+				KeyCount: aws.Int64(2),
+			}, false)
+			pager(&s3.ListObjectsV2Output{
+				Contents: []*s3.Object{
+					{Key: aws.String("in/c.json")},
+					{Key: aws.String("in/d/e.parquet")},
+				},
+				KeyCount: aws.Int64(2),
+			}, true)
+		}).
+		Return(nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-",
+		commands.ListObjectsV2,
+		"--" + flags.Bucket, "TargetBucket",
+		"--" + flags.Prefix, "in/",
+		"--" + flags.Include, "*.parquet",
+		"--" + flags.Exclude, "tmp/**",
+		"--" + flags.Output, "json",
+		"--region", "REG",
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "in/a.parquet")
+	assert.Contains(t, output, "in/d/e.parquet")
+	assert.NotContains(t, output, "in/tmp/b.parquet")
+	assert.NotContains(t, output, "in/c.json")
+	assert.Contains(t, output, `"KeyCount": 2`)
+}
+
+func TestObjectsListV2NDJSONStreamsPages(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	var firstPageOutput string
+	providers.MockS3API.
+		On("ListObjectsV2Pages", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListObjectsV2Output, last bool) bool)
+			pager(&s3.ListObjectsV2Output{
+				Contents: []*s3.Object{
+					{Key: aws.String("a"), Size: aws.Int64(1)},
+					{Key: aws.String("b"), Size: aws.Int64(2)},
+				},
+				IsTruncated:           aws.Bool(true),
+				NextContinuationToken: aws.String("token-1"),
+			}, false)
+			// the entries of a page are rendered before the next page is listed
+			firstPageOutput = providers.FakeUI.Outputs()
+			pager(&s3.ListObjectsV2Output{
+				Contents: []*s3.Object{
+					{Key: aws.String("c"), Size: aws.Int64(3)},
+				},
+				CommonPrefixes:        []*s3.CommonPrefix{{Prefix: aws.String("d/")}},
+				IsTruncated:           aws.Bool(true),
+				NextContinuationToken: aws.String("token-2"),
+			}, false)
+		}).
+		Return(nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-",
+		commands.ListObjectsV2,
+		"--" + flags.Bucket, "TargetBucket",
+		"--" + flags.MaxItems, "3",
+		"--" + flags.Output, "ndjson",
+		"--region", "REG",
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	assert.Contains(t, firstPageOutput, `"Key":"b"`)
+	assert.NotContains(t, firstPageOutput, `"Key":"c"`)
+	// one entry per line, the continuation token last
+	lines := strings.Split(strings.TrimSpace(providers.FakeUI.Outputs()), "\n")
+	if assert.Len(t, lines, 5) {
+		assert.Contains(t, lines[0], `"Key":"a"`)
+		assert.Contains(t, lines[1], `"Key":"b"`)
+		assert.Contains(t, lines[2], `"Key":"c"`)
+		assert.Equal(t, `{"Prefix":"d/"}`, lines[3])
+		assert.Equal(t, `{"NextContinuationToken":"token-2"}`, lines[4])
+	}
+}
+
+func TestObjectHeadRejectsNDJSON(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-",
+		commands.ObjectHead,
+		"--" + flags.Bucket, "TargetBucket",
+		"--" + flags.Key, "TargetKey",
+		"--" + flags.Output, "ndjson",
+		"--region", "REG",
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// only the listings stream their output
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "HeadObject", mock.Anything)
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, errors, "Invalid output format")
+}
@@ -0,0 +1,200 @@
+package functions
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
+	"github.com/IBM/ibmcloud-cos-cli/config/fields"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/render"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// ObjectMove moves an object with a server side copy, within a bucket or to another bucket
+// of the same region. The source is deleted only once the copy is verified against it.
+// Parameter:
+//
+//	CLI Context Application
+//
+// Returns:
+//
+//	Error = zero or non-zero
+func ObjectMove(c *cli.Context) (err error) {
+	// check the number of arguments
+	if c.NArg() > 0 {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidNArg,
+		}
+		return
+	}
+
+	// Load COS Context
+	var cosContext *utils.CosContext
+	if cosContext, err = GetCosContext(c); err != nil {
+		return
+	}
+
+	// The destination of the move, with the customer-provided keys of the source and of the copy
+	input := new(s3.CopyObjectInput)
+	mandatory := map[string]string{
+		fields.Bucket: flags.Bucket,
+		fields.Key:    flags.Key,
+	}
+	options := map[string]string{
+		fields.CopySourceSSECustomerAlgorithm: flags.CopySourceSSECustomerAlgorithm,
+		fields.CopySourceSSECustomerKey:       flags.CopySourceSSECustomerKey,
+		fields.SSECustomerAlgorithm:           flags.SSECustomerAlgorithm,
+		fields.SSECustomerKey:                 flags.SSECustomerKey,
+	}
+	if err = MapToSDKInput(c, input, mandatory, options); err != nil {
+		return
+	}
+
+	// The source of the move, deleted once copied
+	source := new(s3.DeleteObjectInput)
+	sourceMandatory := map[string]string{
+		fields.Key: flags.SourceKey,
+	}
+	sourceOptions := map[string]string{
+		fields.Bucket:    flags.SourceBucket,
+		fields.VersionId: flags.VersionId,
+	}
+	if err = MapToSDKInput(c, source, sourceMandatory, sourceOptions); err != nil {
+		return
+	}
+	if source.Bucket == nil {
+		source.Bucket = input.Bucket
+	}
+
+	// An object cannot be moved onto itself, only one of its versions can
+	if aws.StringValue(source.Bucket) == aws.StringValue(input.Bucket) &&
+		aws.StringValue(source.Key) == aws.StringValue(input.Key) && source.VersionId == nil {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.Key,
+		}
+		return
+	}
+
+	// Size above which the copy is done part by part
+	var threshold int64
+	if threshold, err = getCopyThreshold(c); err != nil {
+		return
+	}
+
+	// Setting client to do the calls
+	var client s3iface.S3API
+	if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+		return
+	}
+
+	// Head the source, the copy is guarded by its ETag
+	input.CopySource = aws.String(copySourceFor(aws.StringValue(source.Bucket), aws.StringValue(source.Key),
+		aws.StringValue(source.VersionId)))
+	var head *s3.HeadObjectOutput
+	if head, err = headCopySource(client, input); err != nil {
+		return
+	}
+	input.CopySourceIfMatch = head.ETag
+
+	// The retention and the object lock of the source go along where the destination permits it,
+	// the retention is only copied from a source which has one
+	if head.RetentionExpirationDate != nil || head.RetentionPeriod != nil {
+		input.RetentionDirective = aws.String(s3.RetentionDirectiveCopy)
+	}
+	if head.ObjectLockMode != nil || head.ObjectLockRetainUntilDate != nil || head.ObjectLockLegalHoldStatus != nil {
+		var locked bool
+		if locked, err = objectLockEnabled(client, input.Bucket); err != nil {
+			return
+		}
+		if locked {
+			input.ObjectLockMode = head.ObjectLockMode
+			input.ObjectLockRetainUntilDate = head.ObjectLockRetainUntilDate
+			input.ObjectLockLegalHoldStatus = head.ObjectLockLegalHoldStatus
+		}
+	}
+
+	// Copy the object, the metadata and the tags are copied from the source
+	var copied *s3.CopyObjectOutput
+	if copied, err = copyObjectFrom(c, client, input, head, threshold); err != nil {
+		return
+	}
+
+	// Verify the copy before deleting the source, the copy is read with its customer-provided key
+	var copyHead *s3.HeadObjectOutput
+	if copyHead, err = client.HeadObject(&s3.HeadObjectInput{
+		Bucket:               input.Bucket,
+		Key:                  input.Key,
+		VersionId:            copied.VersionId,
+		SSECustomerAlgorithm: input.SSECustomerAlgorithm,
+		SSECustomerKey:       input.SSECustomerKey,
+	}); err != nil {
+		return
+	}
+	if !sameObject(head, copyHead) {
+		return awserr.New("move.copy.mismatch",
+			"the copy does not match the source, the source was not deleted", nil)
+	}
+
+	// Delete the source
+	if _, err = client.DeleteObject(source); err != nil {
+		return awserr.New("move.source.delete",
+			"the object was copied but the source could not be deleted", err)
+	}
+
+	output := &render.ObjectMoveOutput{
+		SourceBucket:    aws.StringValue(source.Bucket),
+		SourceKey:       aws.StringValue(source.Key),
+		SourceVersionId: aws.StringValue(head.VersionId),
+		Bucket:          aws.StringValue(input.Bucket),
+		Key:             aws.StringValue(input.Key),
+		VersionId:       aws.StringValue(copyHead.VersionId),
+		ETag:            aws.StringValue(copyHead.ETag),
+		Size:            aws.Int64Value(copyHead.ContentLength),
+	}
+
+	// Display either in JSON or text
+	err = cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input, output, nil)
+
+	// Return
+	return
+}
+
+// objectLockEnabled tells whether object lock is enabled on the bucket, the object lock
+// of a source is only copied into such a bucket
+func objectLockEnabled(client s3iface.S3API, bucket *string) (bool, error) {
+	output, err := client.GetObjectLockConfiguration(&s3.GetObjectLockConfigurationInput{Bucket: bucket})
+	if err != nil {
+		if failure, ok := err.(awserr.RequestFailure); ok && failure.StatusCode() == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return output.ObjectLockConfiguration != nil &&
+		aws.StringValue(output.ObjectLockConfiguration.ObjectLockEnabled) == s3.ObjectLockEnabledEnabled, nil
+}
+
+// sameObject reports whether the copy has the size of the source and, unless one of them
+// was uploaded in parts and has a composite ETag, the same ETag. The ETag of an object
+// encrypted with a customer-provided key is not the MD5 of its content, only the size is compared
+func sameObject(source, copy *s3.HeadObjectOutput) bool {
+	if aws.Int64Value(source.ContentLength) != aws.Int64Value(copy.ContentLength) {
+		return false
+	}
+	if source.SSECustomerAlgorithm != nil || copy.SSECustomerAlgorithm != nil {
+		return true
+	}
+	sourceETag, copyETag := aws.StringValue(source.ETag), aws.StringValue(copy.ETag)
+	if strings.Contains(sourceETag, "-") || strings.Contains(copyETag, "-") {
+		return true
+	}
+	return sourceETag == copyETag
+}
@@ -0,0 +1,425 @@
+//+build unit
+
+package functions_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibmcloud-cos-cli/config"
+	"github.com/IBM/ibmcloud-cos-cli/config/commands"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/cos"
+	"github.com/IBM/ibmcloud-cos-cli/di/providers"
+)
+
+func TestObjectMoveSunnyPath(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	sourceBucket := "SourceBucket"
+	sourceKey := "dir/Source Key"
+	sourceVersionId := "SourceVersion"
+	targetBucket := "TargetBucket"
+	targetKey := "TargetKey"
+	retainUntil := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			return aws.StringValue(input.Bucket) == sourceBucket &&
+				aws.StringValue(input.Key) == sourceKey &&
+				aws.StringValue(input.VersionId) == sourceVersionId
+		})).
+		Return(new(s3.HeadObjectOutput).
+			SetContentLength(42).
+			SetETag(`"etag"`).
+			SetVersionId(sourceVersionId).
+			SetObjectLockMode(s3.ObjectLockModeGovernance).
+			SetObjectLockRetainUntilDate(retainUntil), nil).
+		Once()
+
+	providers.MockS3API.
+		On("GetObjectLockConfiguration", mock.MatchedBy(func(input *s3.GetObjectLockConfigurationInput) bool {
+			return aws.StringValue(input.Bucket) == targetBucket
+		})).
+		Return(&s3.GetObjectLockConfigurationOutput{ObjectLockConfiguration: &s3.ObjectLockConfiguration{
+			ObjectLockEnabled: aws.String(s3.ObjectLockEnabledEnabled),
+		}}, nil).
+		Once()
+
+	var copyCapture *s3.CopyObjectInput
+	providers.MockS3API.
+		On("CopyObject", mock.MatchedBy(func(input *s3.CopyObjectInput) bool {
+			copyCapture = input
+			return true
+		})).
+		Return(new(s3.CopyObjectOutput).SetVersionId("TargetVersion"), nil).
+		Once()
+
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			return aws.StringValue(input.Bucket) == targetBucket &&
+				aws.StringValue(input.VersionId) == "TargetVersion"
+		})).
+		Return(new(s3.HeadObjectOutput).
+			SetContentLength(42).
+			SetETag(`"etag"`).
+			SetVersionId("TargetVersion"), nil).
+		Once()
+
+	var deleteCapture *s3.DeleteObjectInput
+	providers.MockS3API.
+		On("DeleteObject", mock.MatchedBy(func(input *s3.DeleteObjectInput) bool {
+			deleteCapture = input
+			return true
+		})).
+		Return(new(s3.DeleteObjectOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectMove,
+		"--" + flags.SourceBucket, sourceBucket,
+		"--" + flags.SourceKey, sourceKey,
+		"--" + flags.VersionId, sourceVersionId,
+		"--" + flags.Bucket, targetBucket,
+		"--" + flags.Key, targetKey,
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// the copy is guarded by the source ETag and keeps the object lock
+	assert.Equal(t, "SourceBucket/dir/Source%20Key?versionId=SourceVersion", aws.StringValue(copyCapture.CopySource))
+	assert.Equal(t, `"etag"`, aws.StringValue(copyCapture.CopySourceIfMatch))
+	assert.Equal(t, s3.ObjectLockModeGovernance, aws.StringValue(copyCapture.ObjectLockMode))
+	assert.Equal(t, retainUntil, aws.TimeValue(copyCapture.ObjectLockRetainUntilDate))
+	// the exact source version is deleted
+	assert.Equal(t, sourceBucket, aws.StringValue(deleteCapture.Bucket))
+	assert.Equal(t, sourceKey, aws.StringValue(deleteCapture.Key))
+	assert.Equal(t, sourceVersionId, aws.StringValue(deleteCapture.VersionId))
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "OK")
+	assert.Contains(t, output, "TargetVersion")
+}
+
+func TestObjectMoveEncryptedObject(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	sourceKey := strings.Repeat("s", 32)
+	targetKey := strings.Repeat("t", 32)
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// the source is under a legal hold, without retention
+	var sourceHead *s3.HeadObjectInput
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			if aws.StringValue(input.Key) != "SourceKey" {
+				return false
+			}
+			sourceHead = input
+			return true
+		})).
+		Return(new(s3.HeadObjectOutput).
+			SetContentLength(42).
+			SetETag(`"etag"`).
+			SetRetentionLegalHoldCount(1), nil).
+		Once()
+
+	var copyCapture *s3.CopyObjectInput
+	providers.MockS3API.
+		On("CopyObject", mock.MatchedBy(func(input *s3.CopyObjectInput) bool {
+			copyCapture = input
+			return true
+		})).
+		Return(new(s3.CopyObjectOutput), nil).
+		Once()
+
+	var copyHead *s3.HeadObjectInput
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			if aws.StringValue(input.Key) != "TargetKey" {
+				return false
+			}
+			copyHead = input
+			return true
+		})).
+		Return(new(s3.HeadObjectOutput).
+			SetContentLength(42).
+			SetETag(`"etag"`), nil).
+		Once()
+
+	providers.MockS3API.
+		On("DeleteObject", mock.Anything).
+		Return(new(s3.DeleteObjectOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectMove,
+		"--" + flags.SourceKey, "SourceKey",
+		"--" + flags.Bucket, "TargetBucket",
+		"--" + flags.Key, "TargetKey",
+		"--" + flags.CopySourceSSECustomerKey, sourceKey,
+		"--" + flags.SSECustomerKey, targetKey,
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// the source and the copy are each read with their own key
+	assert.Equal(t, sourceKey, aws.StringValue(sourceHead.SSECustomerKey))
+	assert.Equal(t, sourceKey, aws.StringValue(copyCapture.CopySourceSSECustomerKey))
+	assert.Equal(t, targetKey, aws.StringValue(copyCapture.SSECustomerKey))
+	assert.Equal(t, targetKey, aws.StringValue(copyHead.SSECustomerKey))
+	assert.Equal(t, "AES256", aws.StringValue(copyHead.SSECustomerAlgorithm))
+	// a legal hold alone is no retention to copy
+	assert.Nil(t, copyCapture.RetentionDirective)
+	providers.MockS3API.AssertNumberOfCalls(t, "DeleteObject", 1)
+}
+
+func TestObjectMoveIntoBucketWithoutObjectLock(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			return aws.StringValue(input.Key) == "SourceKey"
+		})).
+		Return(new(s3.HeadObjectOutput).
+			SetContentLength(42).
+			SetETag(`"etag"`).
+			SetObjectLockLegalHoldStatus(s3.ObjectLockLegalHoldStatusOn), nil).
+		Once()
+
+	// the destination has no object lock configuration
+	providers.MockS3API.
+		On("GetObjectLockConfiguration", mock.Anything).
+		Return(nil, awserr.NewRequestFailure(
+			awserr.New("ObjectLockConfigurationNotFoundError", "Object Lock configuration does not exist", nil),
+			404, "request")).
+		Once()
+
+	var copyCapture *s3.CopyObjectInput
+	providers.MockS3API.
+		On("CopyObject", mock.MatchedBy(func(input *s3.CopyObjectInput) bool {
+			copyCapture = input
+			return true
+		})).
+		Return(new(s3.CopyObjectOutput), nil).
+		Once()
+
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			return aws.StringValue(input.Key) == "TargetKey"
+		})).
+		Return(new(s3.HeadObjectOutput).
+			SetContentLength(42).
+			SetETag(`"etag"`), nil).
+		Once()
+
+	providers.MockS3API.
+		On("DeleteObject", mock.Anything).
+		Return(new(s3.DeleteObjectOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectMove,
+		"--" + flags.SourceBucket, "SourceBucket",
+		"--" + flags.SourceKey, "SourceKey",
+		"--" + flags.Bucket, "TargetBucket",
+		"--" + flags.Key, "TargetKey",
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero and the legal hold was left out of the copy
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	assert.Nil(t, copyCapture.ObjectLockLegalHoldStatus)
+	assert.Nil(t, copyCapture.ObjectLockMode)
+	providers.MockS3API.AssertNumberOfCalls(t, "DeleteObject", 1)
+}
+
+func TestObjectMoveReKeyedObject(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// the ETags of the objects encrypted with different keys differ
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			return aws.StringValue(input.Key) == "SourceKey"
+		})).
+		Return(new(s3.HeadObjectOutput).
+			SetContentLength(42).
+			SetETag(`"source"`).
+			SetSSECustomerAlgorithm("AES256"), nil).
+		Once()
+
+	providers.MockS3API.
+		On("CopyObject", mock.Anything).
+		Return(new(s3.CopyObjectOutput), nil).
+		Once()
+
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			return aws.StringValue(input.Key) == "TargetKey"
+		})).
+		Return(new(s3.HeadObjectOutput).
+			SetContentLength(42).
+			SetETag(`"copy"`).
+			SetSSECustomerAlgorithm("AES256"), nil).
+		Once()
+
+	providers.MockS3API.
+		On("DeleteObject", mock.Anything).
+		Return(new(s3.DeleteObjectOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectMove,
+		"--" + flags.SourceKey, "SourceKey",
+		"--" + flags.Bucket, "TargetBucket",
+		"--" + flags.Key, "TargetKey",
+		"--" + flags.CopySourceSSECustomerKey, strings.Repeat("s", 32),
+		"--" + flags.SSECustomerKey, strings.Repeat("t", 32),
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero and the source was deleted once the copy was verified by its size
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	providers.MockS3API.AssertNumberOfCalls(t, "DeleteObject", 1)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "OK")
+}
+
+func TestObjectMoveKeepsSourceWhenCopyDiffers(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			return aws.StringValue(input.Key) == "OldKey"
+		})).
+		Return(new(s3.HeadObjectOutput).SetContentLength(42).SetETag(`"etag"`), nil).
+		Once()
+
+	providers.MockS3API.
+		On("CopyObject", mock.Anything).
+		Return(new(s3.CopyObjectOutput), nil).
+		Once()
+
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			return aws.StringValue(input.Key) == "NewKey"
+		})).
+		Return(new(s3.HeadObjectOutput).SetContentLength(41).SetETag(`"other"`), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectMove,
+		"--" + flags.SourceKey, "OldKey",
+		"--" + flags.Bucket, targetBucket,
+		"--" + flags.Key, "NewKey",
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "DeleteObject", mock.Anything)
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, errors, "FAIL")
+}
+
+func TestObjectMoveOntoItself(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectMove,
+		"--" + flags.SourceKey, "SameKey",
+		"--" + flags.Bucket, "SameBucket",
+		"--" + flags.Key, "SameKey",
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "CopyObject", mock.Anything)
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, errors, "FAIL")
+}
@@ -1,10 +1,13 @@
 package functions
 
 import (
+	"bytes"
 	"io"
+	"io/ioutil"
 
 	"github.com/IBM/ibmcloud-cos-cli/errors"
 
+	"github.com/IBM/ibm-cos-sdk-go/aws"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
 
@@ -59,6 +62,8 @@ func ObjectPut(c *cli.Context) (err error) {
 		fields.Body:                    flags.Body,
 		fields.Tagging:                 flags.Tagging,
 		fields.WebsiteRedirectLocation: flags.WebsiteRedirectLocation,
+		fields.SSECustomerAlgorithm:    flags.SSECustomerAlgorithm,
+		fields.SSECustomerKey:          flags.SSECustomerKey,
 	}
 
 	// Validate User Inputs
@@ -77,6 +82,86 @@ func ObjectPut(c *cli.Context) (err error) {
 		return
 	}
 
+	// Skip the upload when the bucket already holds the body
+	var skipper *uploadSkipper
+	if skipper, err = getUploadSkipper(c, nil, flags.Compress, flags.EncryptionKeyFile); err != nil {
+		return
+	}
+	if skipper != nil {
+		var skip bool
+		if skip, err = skipper.skipObject(client, &s3.HeadObjectInput{
+			Bucket:               input.Bucket,
+			Key:                  input.Key,
+			SSECustomerAlgorithm: input.SSECustomerAlgorithm,
+			SSECustomerKey:       input.SSECustomerKey,
+		}, input.Body); err != nil || skip {
+			if skip {
+				err = displaySkipped(c, cosContext, input, aws.StringValue(input.Bucket), aws.StringValue(input.Key),
+					c.String(flags.Body), input.Body)
+			}
+			return
+		}
+	}
+
+	// Detect the Content-Type when not given
+	if input.ContentType == nil && input.Body != nil {
+		var detector *contentTypeDetector
+		if detector, err = newContentTypeDetector(cosContext); err != nil {
+			return
+		}
+		var body io.Reader
+		if input.ContentType, body, err = detector.detect(c.String(flags.Body), input.Body); err != nil {
+			return
+		}
+		input.Body = body.(io.ReadSeeker)
+	}
+
+	// Compress the body when asked to,
+	// in memory since the body of a single request must be seekable
+	var compression string
+	if compression, err = getCompression(c, flags.ContentEncoding, flags.ContentMD5, flags.ContentLength); err != nil {
+		return
+	}
+	if compression != "" {
+		var compressed io.ReadCloser
+		if input.Metadata, compressed, err = compressBody(compression, input.Metadata, input.Body); err != nil {
+			return
+		}
+		var content []byte
+		content, err = ioutil.ReadAll(compressed)
+		compressed.Close()
+		if err != nil {
+			return
+		}
+		input.Body = bytes.NewReader(content)
+		input.ContentEncoding = aws.String(compression)
+	}
+
+	// Encrypt the body on the client when asked to
+	var masterKey []byte
+	if masterKey, err = getMasterKey(c, cosContext, flags.ContentMD5, flags.ContentLength); err != nil {
+		return
+	}
+	if masterKey != nil {
+		var body io.Reader
+		if input.Metadata, body, err = encryptBody(masterKey, input.Metadata, input.Body); err != nil {
+			return
+		}
+		input.Body = body.(io.ReadSeeker)
+	}
+
+	// Report the progress of the upload while the body is read,
+	// limiting the bandwidth of the transfer
+	if input.Body != nil {
+		var limiter *bandwidthLimiter
+		if limiter, err = getBandwidthLimiter(c, cosContext); err != nil {
+			return
+		}
+		progress := newTransferProgress(c, cosContext, aws.StringValue(input.Key), bodyLength(input.Body), limiter)
+		defer progress.finish()
+		input.Body = wrapProgressReader(input.Body, progress).(io.ReadSeeker)
+	}
+
 	// PutObject Op
 	var output *s3.PutObjectOutput
 	if output, err = client.PutObject(input); err != nil {
@@ -4,8 +4,10 @@ package functions_test
 
 import (
 	"errors"
+	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -13,12 +15,14 @@ import (
 
 	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
 	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3"
 	"github.com/IBM/ibmcloud-cos-cli/config"
 	"github.com/IBM/ibmcloud-cos-cli/config/commands"
 	"github.com/IBM/ibmcloud-cos-cli/config/flags"
 	"github.com/IBM/ibmcloud-cos-cli/cos"
 	"github.com/IBM/ibmcloud-cos-cli/di/providers"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
 )
 
 func TestObjectPutSunnyPath(t *testing.T) {
@@ -355,3 +359,316 @@ func TestObjectPutWithTagging(t *testing.T) {
 	// assert Not Fail
 	assert.NotContains(t, errors, "FAIL")
 }
+
+func TestObjectPutJsonProgressEvents(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetKey := "TargetKey"
+	targetBody := "body.txt"
+	bodyContent := "0123456789"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", targetBody).
+		Return(utils.WrapString(bodyContent, nil), nil)
+
+	var uploaded []byte
+	providers.MockS3API.
+		On("PutObject", mock.Anything).
+		Run(func(args mock.Arguments) {
+			uploaded, _ = ioutil.ReadAll(args.Get(0).(*s3.PutObjectInput).Body)
+		}).
+		Return(new(s3.PutObjectOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectPut, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.Body, targetBody,
+		"--" + flags.Region, "REG",
+		"--" + flags.Output, "json"}
+	// call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	assert.Equal(t, bodyContent, string(uploaded))
+	// progress events are emitted one per line apart from the result
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, errors, `{"Event":"start","Key":"TargetKey","Bytes":0,"TotalBytes":10,"Parts":0,"TotalParts":1,`)
+	assert.Contains(t, errors, `{"Event":"complete","Key":"TargetKey","Bytes":10,"TotalBytes":10,"Parts":1,"TotalParts":1,`)
+	assert.NotContains(t, providers.FakeUI.Outputs(), `"Event"`)
+}
+
+func TestObjectPutMaxBandwidth(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBody := "body.txt"
+	// one and a half second of transfer at 1KB/s, the first second is available at once
+	bodyContent := string(make([]byte, 1536))
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", targetBody).
+		Return(utils.WrapString(bodyContent, nil), nil)
+
+	var elapsed time.Duration
+	providers.MockS3API.
+		On("PutObject", mock.Anything).
+		Run(func(args mock.Arguments) {
+			start := time.Now()
+			ioutil.ReadAll(args.Get(0).(*s3.PutObjectInput).Body)
+			elapsed = time.Since(start)
+		}).
+		Return(new(s3.PutObjectOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectPut, "--bucket", "TargetBucket",
+		"--" + flags.Key, "TargetKey",
+		"--" + flags.Body, targetBody,
+		"--" + flags.MaxBandwidth, "1KB/s",
+		"--" + flags.Region, "REG",
+		"--" + flags.Output, "json"}
+	// call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	assert.True(t, elapsed >= 400*time.Millisecond, "body read in %v", elapsed)
+}
+
+func TestObjectPutMaxBandwidthInvalid(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "body.txt").
+		Return(utils.WrapString("content", nil), nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectPut, "--bucket", "TargetBucket",
+		"--" + flags.Key, "TargetKey",
+		"--" + flags.Body, "body.txt",
+		"--" + flags.MaxBandwidth, "50XB/s",
+		"--" + flags.Region, "REG"}
+	// call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "PutObject", mock.Anything)
+	assert.Contains(t, providers.FakeUI.Errors(), "FAIL")
+}
+
+func TestObjectPutDetectsContentType(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	pngContent := "\x89PNG\r\n\x1a\n" + string(make([]byte, 16))
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "index.html").
+		Return(utils.WrapString("<p>hello</p>", nil), nil)
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "logo").
+		Return(utils.WrapString(pngContent, nil), nil)
+
+	contentTypes := make(map[string]string)
+	bodies := make(map[string]string)
+	providers.MockS3API.
+		On("PutObject", mock.Anything).
+		Run(func(args mock.Arguments) {
+			input := args.Get(0).(*s3.PutObjectInput)
+			body, _ := ioutil.ReadAll(input.Body)
+			contentTypes[aws.StringValue(input.Key)] = aws.StringValue(input.ContentType)
+			bodies[aws.StringValue(input.Key)] = string(body)
+		}).
+		Return(new(s3.PutObjectOutput), nil)
+
+	// --- Act ----
+	// call plugin, from the extension and then from the content
+	for key, body := range map[string]string{"page": "index.html", "image": "logo"} {
+		os.Args = []string{"-", commands.ObjectPut, "--bucket", "TargetBucket",
+			"--" + flags.Key, key,
+			"--" + flags.Body, body,
+			"--" + flags.Region, "REG"}
+		plugin.Start(new(cos.Plugin))
+	}
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	assert.Equal(t, "text/html; charset=utf-8", contentTypes["page"])
+	assert.Equal(t, "image/png", contentTypes["image"])
+	// the sniffed body is uploaded from its beginning
+	assert.Equal(t, pngContent, bodies["image"])
+}
+
+func TestObjectPutCustomerKeyFromFile(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	customerKey := "0123456789abcdef0123456789abcdef"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "sse.key").
+		Return(utils.WrapString(customerKey+"\n", nil), nil).
+		Once()
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "data.txt").
+		Return(utils.WrapString("content", nil), nil).
+		Once()
+
+	var putInput *s3.PutObjectInput
+	providers.MockS3API.
+		On("PutObject", mock.MatchedBy(func(input *s3.PutObjectInput) bool {
+			putInput = input
+			return true
+		})).
+		Return(new(s3.PutObjectOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectPut, "--bucket", "TargetBucket",
+		"--" + flags.Key, "TargetKey",
+		"--" + flags.Body, "data.txt",
+		"--" + flags.SSECustomerKey, "file://sse.key",
+		"--" + flags.Region, "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// the key is sent without the new line of the file, with the default algorithm
+	assert.Equal(t, customerKey, aws.StringValue(putInput.SSECustomerKey))
+	assert.Equal(t, "AES256", aws.StringValue(putInput.SSECustomerAlgorithm))
+	// the key is not rendered
+	output := providers.FakeUI.Outputs()
+	assert.NotContains(t, output, customerKey)
+}
+
+func TestObjectPutCustomerKeyTooShort(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectPut, "--bucket", "TargetBucket",
+		"--" + flags.Key, "TargetKey",
+		"--" + flags.SSECustomerKey, "secret",
+		"--" + flags.Region, "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero and the object is not uploaded
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "PutObject", mock.Anything)
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, errors, "--"+flags.SSECustomerKey)
+	assert.NotContains(t, errors, "secret")
+}
+
+func TestObjectPutSkipExistingUploadsMissingKey(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "data.txt").
+		Return(utils.WrapString("content", nil), nil).
+		Once()
+
+	providers.MockS3API.
+		On("HeadObject", mock.Anything).
+		Return(nil, awserr.NewRequestFailure(awserr.New("NotFound", "Not Found", nil), 404, "request")).
+		Once()
+	providers.MockS3API.
+		On("PutObject", mock.Anything).
+		Return(new(s3.PutObjectOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectPut, "--bucket", "TargetBucket",
+		"--" + flags.Key, "TargetKey",
+		"--" + flags.Body, "data.txt",
+		"--" + flags.SkipExisting,
+		"--" + flags.Region, "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// the missing key is uploaded
+	providers.MockS3API.AssertNumberOfCalls(t, "PutObject", 1)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "OK")
+	assert.NotContains(t, output, "Skipped")
+}
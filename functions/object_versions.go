@@ -6,6 +6,7 @@ import (
 	"github.com/IBM/ibmcloud-cos-cli/config/fields"
 	"github.com/IBM/ibmcloud-cos-cli/config/flags"
 	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/render"
 	"github.com/IBM/ibmcloud-cos-cli/utils"
 	"github.com/urfave/cli"
 )
@@ -93,6 +94,13 @@ func ObjectVersions(c *cli.Context) (err error) {
 		return
 	}
 
+	// Stream the entries of every page as it arrives
+	if isNDJSON(c) {
+		stream := render.NewNDJSONRender(cosContext.UI)
+		return finishStream(stream, client.ListObjectVersionsPages(pageIterInput,
+			listObjectVersionsStream(paginationHelper, stream)))
+	}
+
 	// ListObjectVersions Op
 	output := new(s3.ListObjectVersionsOutput)
 	if err = client.ListObjectVersionsPages(pageIterInput, ListObjectVersionsItx(paginationHelper, output)); err != nil {
@@ -892,3 +892,58 @@ func buildListObjectVersionsOutputDM(howMany int) *s3.ListObjectVersionsOutput {
 	}
 	return result
 }
+
+func TestListObjectVersionsNDJSONResumesAfterFailure(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockS3API.
+		On("ListObjectVersionsPages", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListObjectVersionsOutput, last bool) bool)
+			pager(&s3.ListObjectVersionsOutput{
+				Versions: []*s3.ObjectVersion{
+					{Key: aws.String("a"), VersionId: aws.String("v1")},
+				},
+				DeleteMarkers: []*s3.DeleteMarkerEntry{
+					{Key: aws.String("b"), VersionId: aws.String("v2")},
+				},
+				IsTruncated:         aws.Bool(true),
+				NextKeyMarker:       aws.String("b"),
+				NextVersionIdMarker: aws.String("v2"),
+			}, false)
+		}).
+		Return(fmt.Errorf("connection reset")).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-",
+		commands.ObjectVersions,
+		"--" + flags.Bucket, "TargetBucket",
+		"--" + flags.Output, "ndjson",
+		"--region", "REG",
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero
+	assert.Equal(t, 1, *exitCode)
+	output := providers.FakeUI.Outputs()
+	// the delete markers are told apart from the versions
+	assert.Contains(t, output, `"VersionId":"v1"`)
+	assert.Contains(t, output, `"DeleteMarker":true`)
+	// the listing can be resumed after the page rendered before the failure
+	assert.Contains(t, output, `{"NextKeyMarker":"b","NextVersionIdMarker":"v2"}`)
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, errors, "connection reset")
+}
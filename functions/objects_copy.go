@@ -0,0 +1,363 @@
+package functions
+
+import (
+	"bufio"
+	"strings"
+	"sync"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config/fields"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/render"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// ObjectsCopy copies every object under --source-prefix of the source bucket to the destination bucket,
+// replacing the source prefix of the keys with --prefix.
+// Objects are copied server side by a bounded pool of workers, or streamed between the regions
+// when the source bucket is in another region.
+// Failures are collected per object and reported once every object has been processed.
+// Parameter:
+//
+//	CLI Context Application
+//
+// Returns:
+//
+//	Error = zero or non-zero
+func ObjectsCopy(c *cli.Context) (err error) {
+	// check the number of arguments
+	if c.NArg() > 0 {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidNArg,
+		}
+		return
+	}
+
+	// Load COS Context
+	var cosContext *utils.CosContext
+	if cosContext, err = GetCosContext(c); err != nil {
+		return
+	}
+
+	// Build ListObjectsV2Input for the source
+	input := new(s3.ListObjectsV2Input)
+	mandatory := map[string]string{
+		fields.Bucket: flags.SourceBucket,
+	}
+	options := map[string]string{
+		fields.Prefix: flags.SourcePrefix,
+	}
+	if err = MapToSDKInput(c, input, mandatory, options); err != nil {
+		return
+	}
+
+	// The destination bucket and prefix
+	destination := new(s3.ListObjectsV2Input)
+	mandatory = map[string]string{
+		fields.Bucket: flags.Bucket,
+	}
+	options = map[string]string{
+		fields.Prefix: flags.Prefix,
+	}
+	if err = MapToSDKInput(c, destination, mandatory, options); err != nil {
+		return
+	}
+
+	// Number of objects to copy in parallel
+	var jobs int
+	if jobs, err = parseJobs(c); err != nil {
+		return
+	}
+
+	// Size above which an object is copied part by part
+	var threshold int64
+	if threshold, err = getCopyThreshold(c); err != nil {
+		return
+	}
+
+	// Keys selected by the patterns
+	var filter *keyFilter
+	if filter, err = newKeyFilter(c); err != nil {
+		return
+	}
+
+	// The customer-provided keys of the source objects and of their copies
+	sse := new(s3.CopyObjectInput)
+	sseOptions := map[string]string{
+		fields.CopySourceSSECustomerAlgorithm: flags.CopySourceSSECustomerAlgorithm,
+		fields.CopySourceSSECustomerKey:       flags.CopySourceSSECustomerKey,
+		fields.SSECustomerAlgorithm:           flags.SSECustomerAlgorithm,
+		fields.SSECustomerKey:                 flags.SSECustomerKey,
+	}
+	if err = MapToSDKInput(c, sse, map[string]string{}, sseOptions); err != nil {
+		return
+	}
+
+	// Keys to retry, when given
+	var retry map[string]bool
+	if c.IsSet(flags.KeysFile) {
+		if retry, err = readKeysFile(cosContext, c.String(flags.KeysFile)); err != nil {
+			return
+		}
+	}
+
+	// Each bucket is reached through the client of its region
+	region := c.String(flags.Region)
+	sourceRegion := region
+	if c.IsSet(flags.SourceRegion) {
+		sourceRegion = c.String(flags.SourceRegion)
+	}
+	var sourceClient s3iface.S3API
+	if sourceClient, err = cosContext.GetClient(sourceRegion); err != nil {
+		return
+	}
+	var copier objectCopier
+	if sourceRegion == region {
+		copier = &serverSideCopier{c: c, client: sourceClient, threshold: threshold, sse: sse}
+	} else {
+		// S3 Manager Uploader Options
+		uploadOptions := map[string]string{
+			fields.PartSize:    flags.PartSize,
+			fields.Concurrency: flags.Concurrency,
+		}
+		errorHolder := new(error)
+		var uploader utils.Uploader
+		if uploader, err = cosContext.GetUploader(region,
+			applyConfigUploader(c, uploadOptions, errorHolder)); err != nil {
+			return
+		}
+		if *errorHolder != nil {
+			return *errorHolder
+		}
+		copier = &streamingCopier{client: sourceClient, uploader: uploader, sse: sse}
+	}
+
+	// Map the source keys of a page to the destination keys
+	sourcePrefix := aws.StringValue(input.Prefix)
+	items := make([]*render.TransferItem, 0)
+	pageItems := func(page *s3.ListObjectsV2Output) []copyItem {
+		selected := make([]copyItem, 0, len(page.Contents))
+		for _, object := range filter.filterObjects(page.Contents, sourcePrefix) {
+			key := aws.StringValue(object.Key)
+			if retry != nil && !retry[key] {
+				continue
+			}
+			item := &render.TransferItem{
+				Action: render.ActionCopy,
+				Key:    key,
+				Path:   aws.StringValue(destination.Prefix) + strings.TrimPrefix(key, sourcePrefix),
+				Size:   aws.Int64Value(object.Size),
+			}
+			items = append(items, item)
+			selected = append(selected, copyItem{TransferItem: item, etag: object.ETag})
+		}
+		return selected
+	}
+
+	// Preview the objects that would be copied
+	if c.Bool(flags.DryRun) {
+		if err = sourceClient.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+			pageItems(page)
+			return true
+		}); err != nil {
+			return
+		}
+		output := newTransferSummary(render.ActionCopy, aws.StringValue(destination.Bucket), items)
+		output.DryRun = true
+		return cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input, output, nil)
+	}
+
+	// Batch Copy Op, the objects of a page are copied while the next page is listed,
+	// per object errors are recorded in the items
+	queue := make(chan copyItem)
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		copyItems(copier, aws.StringValue(input.Bucket), aws.StringValue(destination.Bucket), queue, jobs)
+	}()
+	err = sourceClient.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, item := range pageItems(page) {
+			queue <- item
+		}
+		return true
+	})
+	close(queue)
+	<-copied
+	if err != nil {
+		return
+	}
+
+	// Keep the failures to retry them
+	if c.IsSet(flags.FailuresFile) {
+		if err = writeFailuresFile(cosContext, c.String(flags.FailuresFile), items); err != nil {
+			return
+		}
+	}
+
+	// Output the summary
+	output := newTransferSummary(render.ActionCopy, aws.StringValue(destination.Bucket), items)
+	if err = cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input, output, nil); err != nil {
+		return
+	}
+
+	if output.Failed > 0 {
+		err = transferIncompleteError(output.Failed)
+	}
+
+	// Return
+	return
+}
+
+// objectCopier copies a single object from the source bucket to the destination bucket
+type objectCopier interface {
+	copyObject(sourceBucket, sourceKey string, etag *string, size int64, bucket, key string) error
+}
+
+// copyItem is an object to copy along with the ETag the source is expected to have
+type copyItem struct {
+	*render.TransferItem
+	etag *string
+}
+
+// copyItems copies the items of the queue with a pool of workers until the queue is closed,
+// recording the errors on the items
+func copyItems(copier objectCopier, sourceBucket, bucket string, queue <-chan copyItem, jobs int) {
+	var wg sync.WaitGroup
+	for worker := 0; worker < jobs; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range queue {
+				if err := copier.copyObject(sourceBucket, item.Key, item.etag, item.Size,
+					bucket, item.Path); err != nil {
+					item.Error = err.Error()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// serverSideCopier copies objects within a region with CopyObject,
+// or with a multipart copy above the threshold, keeping their metadata and tags.
+// The customer-provided keys of the source and of the copy are taken from sse
+type serverSideCopier struct {
+	c         *cli.Context
+	client    s3iface.S3API
+	threshold int64
+	sse       *s3.CopyObjectInput
+}
+
+func (copier *serverSideCopier) copyObject(sourceBucket, sourceKey string, etag *string, size int64,
+	bucket, key string) (err error) {
+	input := &s3.CopyObjectInput{
+		Bucket:            aws.String(bucket),
+		Key:               aws.String(key),
+		CopySource:        aws.String(copySourceFor(sourceBucket, sourceKey, "")),
+		CopySourceIfMatch: etag,
+		// the objects encrypted with a customer-provided key
+		CopySourceSSECustomerAlgorithm: copier.sse.CopySourceSSECustomerAlgorithm,
+		CopySourceSSECustomerKey:       copier.sse.CopySourceSSECustomerKey,
+		SSECustomerAlgorithm:           copier.sse.SSECustomerAlgorithm,
+		SSECustomerKey:                 copier.sse.SSECustomerKey,
+	}
+	// The multipart copy needs the metadata of the source
+	head := &s3.HeadObjectOutput{ContentLength: aws.Int64(size), ETag: etag}
+	if size > copier.threshold {
+		if head, err = headCopySource(copier.client, input); err != nil {
+			return
+		}
+	}
+	_, err = copyObjectFrom(copier.c, copier.client, input, head, copier.threshold)
+	return
+}
+
+// streamingCopier copies objects between regions, downloading them from the source region
+// while uploading them to the destination region, with their metadata and tags.
+// The source is read with the copy source customer-provided key of sse, the copy
+// is encrypted with its customer-provided key
+type streamingCopier struct {
+	client   s3iface.S3API
+	uploader utils.Uploader
+	sse      *s3.CopyObjectInput
+}
+
+func (copier *streamingCopier) copyObject(sourceBucket, sourceKey string, etag *string, _ int64,
+	bucket, key string) (err error) {
+	var tagging *string
+	if tagging, err = copySourceTagging(copier.client,
+		aws.String(copySourceFor(sourceBucket, sourceKey, ""))); err != nil {
+		return
+	}
+
+	var object *s3.GetObjectOutput
+	if object, err = copier.client.GetObject(&s3.GetObjectInput{
+		Bucket:  aws.String(sourceBucket),
+		Key:     aws.String(sourceKey),
+		IfMatch: etag,
+		// the source is decrypted with its customer-provided key
+		SSECustomerAlgorithm: copier.sse.CopySourceSSECustomerAlgorithm,
+		SSECustomerKey:       copier.sse.CopySourceSSECustomerKey,
+	}); err != nil {
+		return
+	}
+	defer object.Body.Close()
+
+	_, err = copier.uploader.Upload(&s3manager.UploadInput{
+		Bucket:                  aws.String(bucket),
+		Key:                     aws.String(key),
+		Body:                    object.Body,
+		CacheControl:            object.CacheControl,
+		ContentDisposition:      object.ContentDisposition,
+		ContentEncoding:         object.ContentEncoding,
+		ContentLanguage:         object.ContentLanguage,
+		ContentType:             object.ContentType,
+		Metadata:                object.Metadata,
+		Tagging:                 tagging,
+		WebsiteRedirectLocation: object.WebsiteRedirectLocation,
+		SSECustomerAlgorithm:    copier.sse.SSECustomerAlgorithm,
+		SSECustomerKey:          copier.sse.SSECustomerKey,
+	})
+	return
+}
+
+// readKeysFile reads a list of keys, one per line
+func readKeysFile(cosContext *utils.CosContext, location string) (map[string]bool, error) {
+	file, err := cosContext.ReadSeekerCloserOpen(location)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	keys := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if key := scanner.Text(); key != "" {
+			keys[key] = true
+		}
+	}
+	return keys, scanner.Err()
+}
+
+// writeFailuresFile writes the keys of the failed items, one per line
+func writeFailuresFile(cosContext *utils.CosContext, location string, items []*render.TransferItem) error {
+	file, err := cosContext.WriteCloserOpen(location)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.Error == "" {
+			continue
+		}
+		if _, err = file.Write([]byte(item.Key + "\n")); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
@@ -0,0 +1,354 @@
+//+build unit
+
+package functions_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config"
+	"github.com/IBM/ibmcloud-cos-cli/config/commands"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/cos"
+	"github.com/IBM/ibmcloud-cos-cli/di/providers"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+)
+
+// mockSourceListing serves a single page of objects for the source prefix
+func mockSourceListing(keys ...string) {
+	providers.MockS3API.
+		On("ListObjectsV2Pages", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			contents := make([]*s3.Object, 0, len(keys))
+			for _, key := range keys {
+				contents = append(contents, &s3.Object{
+					Key:  aws.String(key),
+					Size: aws.Int64(10),
+					ETag: aws.String(`"` + key + `"`),
+				})
+			}
+			pager := args.Get(1).(func(page *s3.ListObjectsV2Output, last bool) bool)
+			pager(&s3.ListObjectsV2Output{Contents: contents}, true)
+		}).
+		Return(nil).
+		Once()
+}
+
+func TestObjectsCopyFiltersAndReportsFailures(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	mockSourceListing("data/a.csv", "data/b.csv", "data/tmp/c.csv", "data/d.json")
+
+	var lock sync.Mutex
+	copies := make(map[string]string)
+	providers.MockS3API.
+		On("CopyObject", mock.MatchedBy(func(input *s3.CopyObjectInput) bool {
+			lock.Lock()
+			defer lock.Unlock()
+			copies[aws.StringValue(input.CopySource)] = aws.StringValue(input.Bucket) + "/" +
+				aws.StringValue(input.Key)
+			return aws.StringValue(input.CopySource) != "SourceBucket/data/b.csv"
+		})).
+		Return(new(s3.CopyObjectOutput), nil)
+	providers.MockS3API.
+		On("CopyObject", mock.Anything).
+		Return(nil, errors.New("AccessDenied"))
+
+	failures := ""
+	providers.MockFileOperations.
+		On("WriteCloserOpen", "failed.txt").
+		Return(utils.WriteToString(&failures, nil), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectsCopy,
+		"--" + flags.SourceBucket, "SourceBucket",
+		"--" + flags.SourcePrefix, "data/",
+		"--" + flags.Bucket, "TargetBucket",
+		"--" + flags.Prefix, "archive/",
+		"--" + flags.Include, "*.csv",
+		"--" + flags.Exclude, "tmp/**",
+		"--" + flags.FailuresFile, "failed.txt",
+		"--" + flags.Jobs, "2",
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero since one object failed
+	assert.Equal(t, 1, *exitCode)
+	assert.Equal(t, map[string]string{
+		"SourceBucket/data/a.csv": "TargetBucket/archive/a.csv",
+		"SourceBucket/data/b.csv": "TargetBucket/archive/b.csv",
+	}, copies)
+	assert.Equal(t, "data/b.csv\n", failures)
+	output := providers.FakeUI.Outputs()
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, output, "Copied 1 of 2 object(s) to bucket 'TargetBucket' (10 B).")
+	assert.Contains(t, output, "AccessDenied")
+	assert.Contains(t, errors, "FAIL")
+}
+
+func TestObjectsCopyRetriesKeysFile(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	mockSourceListing("a", "b", "c")
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "failed.txt").
+		Return(utils.WrapString("b\nc\n", nil), nil).
+		Once()
+
+	var lock sync.Mutex
+	sources := make([]string, 0)
+	providers.MockS3API.
+		On("CopyObject", mock.MatchedBy(func(input *s3.CopyObjectInput) bool {
+			lock.Lock()
+			defer lock.Unlock()
+			sources = append(sources, aws.StringValue(input.CopySource))
+			return true
+		})).
+		Return(new(s3.CopyObjectOutput), nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectsCopy,
+		"--" + flags.SourceBucket, "SourceBucket",
+		"--" + flags.Bucket, "TargetBucket",
+		"--" + flags.KeysFile, "failed.txt",
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	sort.Strings(sources)
+	assert.Equal(t, []string{"SourceBucket/b", "SourceBucket/c"}, sources)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "Copied 2 of 2 object(s) to bucket 'TargetBucket' (20 B).")
+}
+
+func TestObjectsCopyStreamsBetweenRegions(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	mockSourceListing("photos/cat.png")
+
+	providers.MockS3API.
+		On("GetObjectTagging", mock.Anything).
+		Return(new(s3.GetObjectTaggingOutput).
+			SetTagSet([]*s3.Tag{{Key: aws.String("team"), Value: aws.String("blue")}}), nil).
+		Once()
+
+	var getCapture *s3.GetObjectInput
+	providers.MockS3API.
+		On("GetObject", mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+			getCapture = input
+			return true
+		})).
+		Return(new(s3.GetObjectOutput).
+			SetBody(ioutil.NopCloser(strings.NewReader("0123456789"))).
+			SetContentType("image/png").
+			SetMetadata(map[string]*string{"Camera": aws.String("film")}), nil).
+		Once()
+
+	var uploadCapture *s3manager.UploadInput
+	var uploaded []byte
+	providers.MockUploaderAPI.
+		On("Upload", mock.MatchedBy(func(input *s3manager.UploadInput) bool {
+			uploadCapture = input
+			uploaded, _ = ioutil.ReadAll(input.Body)
+			return true
+		})).
+		Return(new(s3manager.UploadOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectsCopy,
+		"--" + flags.SourceBucket, "SourceBucket",
+		"--" + flags.SourceRegion, "eu-de",
+		"--" + flags.Bucket, "TargetBucket",
+		"--region", "us-south"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	providers.MockS3API.AssertNotCalled(t, "CopyObject", mock.Anything)
+	assert.Equal(t, `"photos/cat.png"`, aws.StringValue(getCapture.IfMatch))
+	assert.Equal(t, "TargetBucket", aws.StringValue(uploadCapture.Bucket))
+	assert.Equal(t, "photos/cat.png", aws.StringValue(uploadCapture.Key))
+	assert.Equal(t, "image/png", aws.StringValue(uploadCapture.ContentType))
+	assert.Equal(t, "film", aws.StringValue(uploadCapture.Metadata["Camera"]))
+	assert.Equal(t, "team=blue", aws.StringValue(uploadCapture.Tagging))
+	assert.Equal(t, "0123456789", string(uploaded))
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "Copied 1 of 1 object(s) to bucket 'TargetBucket' (10 B).")
+}
+
+func TestObjectsCopyStreamsEncryptedObjectsWithUploaderOptions(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	sourceKey := strings.Repeat("s", 32)
+	targetKey := strings.Repeat("t", 32)
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	mockSourceListing("photos/cat.png")
+
+	providers.MockS3API.
+		On("GetObjectTagging", mock.Anything).
+		Return(new(s3.GetObjectTaggingOutput), nil).
+		Once()
+
+	var getCapture *s3.GetObjectInput
+	providers.MockS3API.
+		On("GetObject", mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+			getCapture = input
+			return true
+		})).
+		Return(new(s3.GetObjectOutput).
+			SetBody(ioutil.NopCloser(strings.NewReader("0123456789"))), nil).
+		Once()
+
+	var uploadCapture *s3manager.UploadInput
+	providers.MockUploaderAPI.
+		On("Upload", mock.MatchedBy(func(input *s3manager.UploadInput) bool {
+			uploadCapture = input
+			return true
+		})).
+		Return(new(s3manager.UploadOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectsCopy,
+		"--" + flags.SourceBucket, "SourceBucket",
+		"--" + flags.SourceRegion, "eu-de",
+		"--" + flags.Bucket, "TargetBucket",
+		"--" + flags.CopySourceSSECustomerKey, sourceKey,
+		"--" + flags.SSECustomerKey, targetKey,
+		"--" + flags.PartSize, "6291456",
+		"--" + flags.Concurrency, "3",
+		"--region", "us-south"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// the source is read with its key and the copy is encrypted with the other one
+	assert.Equal(t, sourceKey, aws.StringValue(getCapture.SSECustomerKey))
+	assert.Equal(t, "AES256", aws.StringValue(getCapture.SSECustomerAlgorithm))
+	assert.Equal(t, targetKey, aws.StringValue(uploadCapture.SSECustomerKey))
+	assert.Equal(t, "AES256", aws.StringValue(uploadCapture.SSECustomerAlgorithm))
+	// the uploader is configured with the options of the command
+	assert.Equal(t, int64(6<<20), providers.ReferenceUploader.PartSize)
+	assert.Equal(t, 3, providers.ReferenceUploader.Concurrency)
+}
+
+func TestObjectsCopyCopiesEachPageWhileListing(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	firstCopied := make(chan struct{})
+	var once sync.Once
+	providers.MockS3API.
+		On("CopyObject", mock.AnythingOfType("*s3.CopyObjectInput")).
+		Run(func(mock.Arguments) { once.Do(func() { close(firstCopied) }) }).
+		Return(new(s3.CopyObjectOutput), nil)
+
+	providers.MockS3API.
+		On("ListObjectsV2Pages", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListObjectsV2Output, last bool) bool)
+			pager(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+				{Key: aws.String("a"), Size: aws.Int64(10)},
+			}}, false)
+			// the next page is only listed once the object of the first one is copied
+			select {
+			case <-firstCopied:
+			case <-time.After(5 * time.Second):
+				t.Error("the first page was not copied before listing the next one")
+			}
+			pager(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+				{Key: aws.String("b"), Size: aws.Int64(10)},
+			}}, true)
+		}).
+		Return(nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.ObjectsCopy,
+		"--" + flags.SourceBucket, "SourceBucket",
+		"--" + flags.Bucket, "TargetBucket",
+		"--region", "REG"}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	providers.MockS3API.AssertNumberOfCalls(t, "CopyObject", 2)
+	assert.Contains(t, providers.FakeUI.Outputs(), "Copied 2 of 2 object(s) to bucket 'TargetBucket' (20 B).")
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/IBM/ibmcloud-cos-cli/config/fields"
 	"github.com/IBM/ibmcloud-cos-cli/config/flags"
 	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/render"
 	"github.com/IBM/ibmcloud-cos-cli/utils"
 	"github.com/urfave/cli"
 )
@@ -93,6 +94,12 @@ func PartsList(c *cli.Context) (err error) {
 		return
 	}
 
+	// Stream the entries of every page as it arrives
+	if isNDJSON(c) {
+		stream := render.NewNDJSONRender(cosContext.UI)
+		return finishStream(stream, client.ListPartsPages(pageIterInput, listPartsStream(paginationHelper, stream)))
+	}
+
 	// List Parts Op
 	output := new(s3.ListPartsOutput)
 	if err = client.ListPartsPages(pageIterInput, ListPartsItx(paginationHelper, output)); err != nil {
@@ -49,9 +49,11 @@ func PartUpload(c *cli.Context) (err error) {
 
 	// Optional parameters for UploadPart
 	options := map[string]string{
-		fields.ContentLength: flags.ContentLength,
-		fields.ContentMD5:    flags.ContentMD5,
-		fields.Body:          flags.Body,
+		fields.ContentLength:        flags.ContentLength,
+		fields.ContentMD5:           flags.ContentMD5,
+		fields.Body:                 flags.Body,
+		fields.SSECustomerAlgorithm: flags.SSECustomerAlgorithm,
+		fields.SSECustomerKey:       flags.SSECustomerKey,
 	}
 
 	// Check through user inputs for validation
@@ -70,6 +72,15 @@ func PartUpload(c *cli.Context) (err error) {
 		return
 	}
 
+	// Limit the bandwidth of the transfer
+	var limiter *bandwidthLimiter
+	if limiter, err = getBandwidthLimiter(c, cosContext); err != nil {
+		return
+	}
+	if limiter != nil && input.Body != nil {
+		input.Body = wrapProgressReader(input.Body, newTransferTracker(bodyLength(input.Body), limiter)).(io.ReadSeeker)
+	}
+
 	// UploadPart Op
 	var output *s3.UploadPartOutput
 	if output, err = client.UploadPart(input); err != nil {
@@ -0,0 +1,257 @@
+package functions
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/request"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/render"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// transferProgress tracks how far each part of a transfer went, reports it on the display
+// and throttles it to the maximum bandwidth.
+// Bytes read again, to sign a request or to retry it, are counted only once.
+type transferProgress struct {
+	progress   render.Progress
+	limiter    *bandwidthLimiter
+	lock       sync.Mutex
+	partSize   int64
+	totalBytes int64
+	covered    map[int64]int64
+	bytes      int64
+	parts      int64
+}
+
+// newTransferTracker tracks a transfer without reporting its progress,
+// the limiter is nil when the bandwidth is not limited
+func newTransferTracker(totalBytes int64, limiter *bandwidthLimiter) *transferProgress {
+	return &transferProgress{
+		limiter:    limiter,
+		partSize:   math.MaxInt64,
+		totalBytes: totalBytes,
+		covered:    make(map[int64]int64),
+	}
+}
+
+// newTransferProgress starts reporting the progress of the transfer of a single object
+// as a progress bar, or as JSON events when the output is JSON.
+// A size of zero stands for a size that is not known yet.
+func newTransferProgress(c *cli.Context, cosContext *utils.CosContext, key string,
+	totalBytes int64, limiter *bandwidthLimiter) *transferProgress {
+	tracker := newTransferTracker(totalBytes, limiter)
+	if display, ok := cosContext.GetDisplay(c.String(flags.Output),
+		c.Bool(flags.JSON)).(render.ProgressDisplay); ok {
+		tracker.progress = display.NewProgress(key, totalBytes, tracker.totalParts())
+	}
+	return tracker
+}
+
+// totalParts returns the number of parts of the transfer
+func (tracker *transferProgress) totalParts() int64 {
+	if tracker.totalBytes <= tracker.partSize {
+		return 1
+	}
+	return (tracker.totalBytes + tracker.partSize - 1) / tracker.partSize
+}
+
+// partLength returns the length of the part with the given index
+func (tracker *transferProgress) partLength(part int64) int64 {
+	if remaining := tracker.totalBytes - part*tracker.partSize; remaining < tracker.partSize {
+		return remaining
+	}
+	return tracker.partSize
+}
+
+// setSize sets the size of the object and the size of its parts
+func (tracker *transferProgress) setSize(totalBytes, partSize int64) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+	if partSize > 0 {
+		tracker.partSize = partSize
+	}
+	tracker.totalBytes = totalBytes
+	if tracker.progress != nil {
+		tracker.progress.SetTotal(tracker.totalBytes, tracker.totalParts())
+	}
+}
+
+// record accounts for n bytes transferred from the offset,
+// waiting for the bandwidth the bytes not transferred before take
+func (tracker *transferProgress) record(offset, n int64) {
+	tracker.limiter.wait(tracker.cover(offset, n))
+}
+
+// cover accounts for n bytes transferred from the offset and returns the number of bytes
+// not transferred before, without waiting for the bandwidth
+func (tracker *transferProgress) cover(offset, n int64) (added int64) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+	added = tracker.bytes
+	for n > 0 {
+		part := offset / tracker.partSize
+		start := part * tracker.partSize
+		end := offset + n
+		if part < math.MaxInt64/tracker.partSize && end > start+tracker.partSize {
+			end = start + tracker.partSize
+		}
+		if reached := end - start; reached > tracker.covered[part] {
+			tracker.bytes += reached - tracker.covered[part]
+			tracker.covered[part] = reached
+			if tracker.totalBytes > 0 && reached == tracker.partLength(part) {
+				tracker.parts++
+			}
+		}
+		n -= end - offset
+		offset = end
+	}
+	added = tracker.bytes - added
+	if tracker.progress != nil {
+		tracker.progress.Update(tracker.bytes, tracker.parts)
+	}
+	return
+}
+
+// finish stops the report
+func (tracker *transferProgress) finish() {
+	if tracker.progress != nil {
+		tracker.progress.Finish()
+	}
+}
+
+// uploaderOption is an Uploader option that aligns the tracked parts with the parts of the upload
+func (tracker *transferProgress) uploaderOption(u *s3manager.Uploader) {
+	partSize := u.PartSize
+	maxParts := int64(u.MaxUploadParts)
+	if maxParts <= 0 {
+		maxParts = s3manager.MaxUploadParts
+	}
+	// the Uploader grows the parts the same way for large files
+	if partSize > 0 && tracker.totalBytes/partSize >= maxParts {
+		partSize = tracker.totalBytes/maxParts + 1
+	}
+	tracker.setSize(tracker.totalBytes, partSize)
+}
+
+// downloaderOption is a Downloader option that aligns the tracked parts with the parts of the download,
+// the size of the object is read from the first response
+func (tracker *transferProgress) downloaderOption(d *s3manager.Downloader) {
+	partSize := d.PartSize
+	tracker.setSize(tracker.totalBytes, partSize)
+	d.RequestOptions = append(d.RequestOptions, func(r *request.Request) {
+		r.Handlers.Complete.PushBack(func(r *request.Request) {
+			if output, ok := r.Data.(*s3.GetObjectOutput); ok && r.Error == nil {
+				if total := totalFromContentRange(aws.StringValue(output.ContentRange)); total >= 0 {
+					tracker.setSize(total, partSize)
+				}
+			}
+		})
+	})
+}
+
+// totalFromContentRange returns the complete length of a Content-Range header, -1 when it is unknown
+func totalFromContentRange(contentRange string) int64 {
+	index := strings.LastIndex(contentRange, "/")
+	if index < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(contentRange[index+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
+
+// wrapProgressReader wraps the body of an upload to record the bytes read,
+// keeping the capabilities of the body the Uploader relies on
+func wrapProgressReader(body io.Reader, tracker *transferProgress) io.Reader {
+	reader := progressReader{Reader: body, tracker: tracker}
+	switch body.(type) {
+	case readerAtSeeker:
+		return &progressReaderAtSeeker{progressReadSeeker{reader}}
+	case io.ReadSeeker:
+		return &progressReadSeeker{reader}
+	}
+	return &reader
+}
+
+// readerAtSeeker is a body the Uploader reads concurrently
+type readerAtSeeker interface {
+	io.ReaderAt
+	io.ReadSeeker
+}
+
+// progressReader records the bytes read sequentially
+type progressReader struct {
+	io.Reader
+	tracker *transferProgress
+	offset  int64
+}
+
+func (r *progressReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	r.tracker.record(r.offset, int64(n))
+	r.offset += int64(n)
+	return
+}
+
+// progressReadSeeker follows the position of a seekable body
+type progressReadSeeker struct {
+	progressReader
+}
+
+func (r *progressReadSeeker) Seek(offset int64, whence int) (position int64, err error) {
+	if position, err = r.Reader.(io.Seeker).Seek(offset, whence); err == nil {
+		r.offset = position
+	}
+	return
+}
+
+// progressReaderAtSeeker records the bytes read at any offset
+type progressReaderAtSeeker struct {
+	progressReadSeeker
+}
+
+func (r *progressReaderAtSeeker) ReadAt(p []byte, offset int64) (n int, err error) {
+	n, err = r.Reader.(io.ReaderAt).ReadAt(p, offset)
+	r.tracker.record(offset, int64(n))
+	return
+}
+
+// progressWriter records the bytes written to the destination of a download
+type progressWriter struct {
+	utils.WriteCloser
+	tracker *transferProgress
+	offset  int64
+}
+
+func (w *progressWriter) Write(p []byte) (n int, err error) {
+	n, err = w.WriteCloser.Write(p)
+	w.tracker.record(w.offset, int64(n))
+	w.offset += int64(n)
+	return
+}
+
+func (w *progressWriter) WriteAt(p []byte, offset int64) (n int, err error) {
+	n, err = w.WriteCloser.WriteAt(p, offset)
+	w.tracker.record(offset, int64(n))
+	return
+}
+
+// bodyLength returns the length of a seekable body, zero when it is not known
+func bodyLength(body io.Reader) int64 {
+	if seeker, ok := body.(io.Seeker); ok {
+		if length, err := aws.SeekerLen(seeker); err == nil && length > 0 {
+			return length
+		}
+	}
+	return 0
+}
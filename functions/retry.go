@@ -0,0 +1,65 @@
+package functions
+
+import (
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// ApplyRetryFlags sets the retries of the requests of the command from the global flags,
+// which override the configuration. With --debug every retry is reported in the error output.
+// It runs once before the action of the command, the session is shared by its requests.
+func ApplyRetryFlags(c *cli.Context) error {
+	settings := []struct {
+		flag  string
+		apply func(*utils.Retryer, string) error
+	}{
+		{flags.MaxRetries, (*utils.Retryer).SetMaxRetries},
+		{flags.RetryBaseDelay, (*utils.Retryer).SetBaseDelay},
+		{flags.RetryMaxDelay, (*utils.Retryer).SetMaxDelay},
+		{flags.RetryStatusCodes, (*utils.Retryer).SetStatusCodes},
+	}
+	set := c.Bool(flags.Debug)
+	for _, setting := range settings {
+		set = set || c.IsSet(setting.flag)
+	}
+	if !set {
+		return nil
+	}
+	cosContext, err := GetCosContext(c)
+	if err != nil || cosContext.Session == nil {
+		return err
+	}
+
+	retryer, err := utils.NewRetryer(cosContext.Config)
+	if err != nil {
+		return err
+	}
+	for _, setting := range settings {
+		if !c.IsSet(setting.flag) {
+			continue
+		}
+		if err = setting.apply(retryer, c.String(setting.flag)); err != nil {
+			return &errors.CommandError{
+				CLIContext: c,
+				Cause:      errors.InvalidValue,
+				Flag:       setting.flag,
+				IError:     err,
+			}
+		}
+	}
+	if c.Bool(flags.Debug) {
+		retryer.Logger = aws.LoggerFunc(func(args ...interface{}) {
+			for _, arg := range args {
+				cosContext.UI.Info("%v", arg)
+			}
+		})
+	} else if cosContext.Session.Config != nil {
+		// the retries are still reported in the trace
+		retryer.Logger = cosContext.Session.Config.Logger
+	}
+	cosContext.Session = cosContext.Session.Copy(&aws.Config{Retryer: retryer})
+	return nil
+}
@@ -0,0 +1,163 @@
+//+build unit
+
+package functions_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/credentials"
+	"github.com/IBM/ibm-cos-sdk-go/aws/session"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibmcloud-cos-cli/config"
+	"github.com/IBM/ibmcloud-cos-cli/config/commands"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/cos"
+	"github.com/IBM/ibmcloud-cos-cli/di/providers"
+	"github.com/IBM/ibmcloud-cos-cli/di/providers/mocks"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+)
+
+func TestRetryerRetriesStatusCodesAndReportsThem(t *testing.T) {
+	// --- Arrange ---
+	// the bucket answers 409 Conflict twice, a status the SDK does not retry by itself
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	pluginConfig := new(mocks.PluginConfig)
+	pluginConfig.On("GetStringWithDefault", config.MaxRetries, "").Return("", nil)
+	pluginConfig.On("GetStringWithDefault", config.RetryBaseDelay, "").Return("1ms", nil)
+	pluginConfig.On("GetStringWithDefault", config.RetryMaxDelay, "").Return("2ms", nil)
+	pluginConfig.On("GetStringWithDefault", config.RetryStatusCodes, "").Return("409, 503", nil)
+
+	retryer, err := utils.NewRetryer(pluginConfig)
+	assert.NoError(t, err)
+	reported := make([]string, 0)
+	retryer.Logger = aws.LoggerFunc(func(args ...interface{}) {
+		reported = append(reported, fmt.Sprint(args...))
+	})
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+		Endpoint:         aws.String(server.URL),
+		Region:           aws.String("us-geo"),
+		S3ForcePathStyle: aws.Bool(true),
+		Retryer:          retryer,
+	}))
+
+	// --- Act ----
+	_, err = s3.New(sess).HeadBucket(&s3.HeadBucketInput{Bucket: aws.String("bucket")})
+
+	// --- Assert ----
+	assert.NoError(t, err)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, []int{409, 503}, retryer.StatusCodes)
+	assert.Equal(t, time.Millisecond, retryer.MinRetryDelay)
+	if assert.Len(t, reported, 2) {
+		assert.Contains(t, reported[0], "Retry 1/3 of HeadBucket")
+		assert.Contains(t, reported[0], "409")
+		assert.Contains(t, reported[1], "Retry 2/3 of HeadBucket")
+	}
+}
+
+func TestRetryFlagsRejectInvalidStatusCodes(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-",
+		commands.BucketHead,
+		"--" + flags.Bucket, "bucket",
+		"--" + flags.MaxRetries, "5",
+		"--" + flags.RetryStatusCodes, "503,SlowDown",
+		"--" + flags.Debug,
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, 1, *exitCode)
+	providers.MockS3API.AssertNotCalled(t, "HeadBucket", mock.Anything)
+	assert.Contains(t, providers.FakeUI.Errors(), "The value in flag '--retry-status-codes' is invalid")
+}
+
+func TestMaxRetriesSet(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.
+		MockPluginConfig.
+		On("Set", config.MaxRetries, "10").
+		Return(nil)
+
+	providers.
+		MockPluginConfig.
+		On("Set", config.LastUpdated, mock.AnythingOfType("string")).
+		Return(nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Config, commands.Set, flags.MaxRetries, "10"}
+
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode)
+	providers.MockPluginConfig.AssertExpectations(t)
+	assert.Contains(t, providers.FakeUI.Outputs(), "OK")
+}
+
+func TestRetryMaxDelaySetInvalidDelay(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Config, commands.Set, flags.RetryMaxDelay, "20"}
+
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, 1, *exitCode)
+	providers.MockPluginConfig.AssertNotCalled(t, "Set", config.RetryMaxDelay, mock.Anything)
+	assert.Contains(t, providers.FakeUI.Errors(), "invalid delay")
+}
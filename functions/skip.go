@@ -0,0 +1,201 @@
+package functions
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/render"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// uploadSkipper decides which uploads are skipped, every key that already exists with --skip-existing,
+// the objects with the same size and MD5 as the file with --if-changed
+type uploadSkipper struct {
+	ifChanged      bool
+	partSize       int64
+	maxUploadParts int
+}
+
+// getUploadSkipper returns the skipper of the uploads, nil when no upload is skipped.
+// The part size is the one the Uploader options give, to compute the ETag of a multipart upload.
+// The flags that cannot be combined with the comparison of the content are rejected.
+func getUploadSkipper(c *cli.Context, uploadOptions map[string]string, conflicting ...string) (*uploadSkipper, error) {
+	if !c.Bool(flags.SkipExisting) && !c.Bool(flags.IfChanged) {
+		return nil, nil
+	}
+	if c.Bool(flags.SkipExisting) && c.Bool(flags.IfChanged) {
+		return nil, &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.IfChanged,
+			IError:     fmt.Errorf("--%s cannot be used with --%s", flags.IfChanged, flags.SkipExisting),
+		}
+	}
+	skipper := &uploadSkipper{
+		ifChanged:      c.Bool(flags.IfChanged),
+		partSize:       s3manager.DefaultUploadPartSize,
+		maxUploadParts: s3manager.MaxUploadParts,
+	}
+	if uploadOptions != nil {
+		uploader := new(s3manager.Uploader)
+		if err := MapToSDKInput(c, uploader, map[string]string{}, uploadOptions); err != nil {
+			return nil, err
+		}
+		if uploader.PartSize > 0 {
+			skipper.partSize = uploader.PartSize
+		}
+		if uploader.MaxUploadParts > 0 {
+			skipper.maxUploadParts = uploader.MaxUploadParts
+		}
+	}
+	if !skipper.ifChanged {
+		return skipper, nil
+	}
+	for _, flag := range conflicting {
+		if c.IsSet(flag) {
+			return nil, &errors.CommandError{
+				CLIContext: c,
+				Cause:      errors.InvalidValue,
+				Flag:       flag,
+				IError:     fmt.Errorf("--%s cannot be used with --%s", flag, flags.IfChanged),
+			}
+		}
+	}
+	return skipper, nil
+}
+
+// skipObject tells whether the upload of the body to the key is skipped,
+// the destination is read with HEAD and a missing key is uploaded
+func (skipper *uploadSkipper) skipObject(client s3iface.S3API, input *s3.HeadObjectInput, body io.Reader) (bool, error) {
+	head, err := client.HeadObject(input)
+	if err != nil {
+		if failure, ok := err.(awserr.RequestFailure); ok && failure.StatusCode() == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return skipper.unchanged(body, aws.Int64Value(head.ContentLength), head.ETag)
+}
+
+// displaySkipped displays the summary of an upload skipped because the bucket already holds the file
+func displaySkipped(c *cli.Context, cosContext *utils.CosContext, input interface{}, bucket, key, path string,
+	body io.Reader) error {
+	item := &render.TransferItem{Action: render.ActionUpload, Key: key, Path: path, Skipped: true}
+	if seeker, ok := body.(io.Seeker); ok {
+		item.Size, _ = aws.SeekerLen(seeker)
+	}
+	output := newTransferSummary(render.ActionUpload, bucket, []*render.TransferItem{item})
+	return cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input, output, nil)
+}
+
+// unchanged tells whether the existing object of that size and ETag is kept,
+// the body is read from its beginning and rewound to compare it with the object
+func (skipper *uploadSkipper) unchanged(body io.Reader, size int64, etag *string) (bool, error) {
+	if !skipper.ifChanged {
+		return true, nil
+	}
+	// an object put without body is empty
+	if body == nil {
+		body = strings.NewReader("")
+	}
+	seeker, ok := body.(io.ReadSeeker)
+	if !ok {
+		return false, fmt.Errorf("--%s requires a file", flags.IfChanged)
+	}
+	length, err := aws.SeekerLen(seeker)
+	if err != nil || length != size {
+		return false, err
+	}
+	existing := strings.Trim(aws.StringValue(etag), `"`)
+	sum, err := skipper.contentETag(seeker, length, existing)
+	if _, seekErr := seeker.Seek(0, io.SeekStart); err == nil {
+		err = seekErr
+	}
+	return err == nil && strings.EqualFold(sum, existing), err
+}
+
+// contentETag computes the ETag of the content in the form of the existing one,
+// the MD5 of a single request upload, or the MD5 of the MD5 digests of the parts
+// followed by the number of parts for a multipart upload with the same part size as the Uploader
+func (skipper *uploadSkipper) contentETag(body io.Reader, size int64, etag string) (string, error) {
+	whole := md5.New()
+	if !strings.Contains(etag, "-") {
+		if _, err := io.Copy(whole, body); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(whole.Sum(nil)), nil
+	}
+	partSize := skipper.partSize
+	if size/partSize >= int64(skipper.maxUploadParts) {
+		partSize = size/int64(skipper.maxUploadParts) + 1
+	}
+	parts := 0
+	for {
+		part := md5.New()
+		n, err := io.CopyN(part, body, partSize)
+		if n > 0 || parts == 0 {
+			whole.Write(part.Sum(nil))
+			parts++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(whole.Sum(nil)) + "-" + strconv.Itoa(parts), nil
+}
+
+// skipItems marks the files of a directory upload that are skipped, comparing them with the objects under the prefix
+func (skipper *uploadSkipper) skipItems(cosContext *utils.CosContext, client s3iface.S3API, bucket, prefix string,
+	items []*render.TransferItem) error {
+	objects, err := listObjectsWithPrefix(client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*s3.Object, len(objects))
+	for _, object := range objects {
+		existing[aws.StringValue(object.Key)] = object
+	}
+	for _, item := range items {
+		object, found := existing[item.Key]
+		if !found {
+			continue
+		}
+		if !skipper.ifChanged {
+			item.Skipped = true
+			continue
+		}
+		if aws.Int64Value(object.Size) != item.Size {
+			continue
+		}
+		file, err := cosContext.ReadSeekerCloserOpen(item.Path)
+		if err != nil {
+			item.Error = err.Error()
+			continue
+		}
+		item.Skipped, err = skipper.unchanged(file, aws.Int64Value(object.Size), object.ETag)
+		file.Close()
+		if err != nil {
+			item.Error = err.Error()
+		}
+	}
+	return nil
+}
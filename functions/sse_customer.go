@@ -0,0 +1,71 @@
+package functions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibmcloud-cos-cli/config/fields"
+	"github.com/urfave/cli"
+)
+
+// customerKeySize is the size of an AES256 customer-provided key
+const customerKeySize = 32
+
+// customerKeyAlgorithms pairs the customer-provided key fields of an input with their algorithm field
+var customerKeyAlgorithms = map[string]string{
+	fields.SSECustomerKey:           fields.SSECustomerAlgorithm,
+	fields.CopySourceSSECustomerKey: fields.CopySourceSSECustomerAlgorithm,
+}
+
+// isCustomerKeyField tells whether a field of an input holds a customer-provided key
+func isCustomerKeyField(fieldName string) bool {
+	_, found := customerKeyAlgorithms[fieldName]
+	return found
+}
+
+// parseCustomerKey returns a customer-provided key given as it is, or read from the file of a file:// value.
+// The key is sent as it is read, the SDK encodes it and computes its MD5.
+func parseCustomerKey(cliContext *cli.Context, value string) (string, error) {
+	key := value
+	if strings.HasPrefix(value, filePrefix) {
+		cosContext, err := GetCosContext(cliContext)
+		if err != nil {
+			return "", err
+		}
+		file, err := cosContext.ReadSeekerCloserOpen(value[len(filePrefix):])
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		content, err := ioutil.ReadAll(file)
+		if err != nil {
+			return "", err
+		}
+		// a key written by an editor ends with a new line
+		key = string(content)
+		if len(key) != customerKeySize {
+			key = strings.TrimRight(key, "\r\n")
+		}
+	}
+	if len(key) != customerKeySize {
+		return "", fmt.Errorf("the customer-provided key must be %d bytes long", customerKeySize)
+	}
+	return key, nil
+}
+
+// defaultCustomerAlgorithms sets the algorithm of the customer-provided keys of an input to AES256
+// when it is not given
+func defaultCustomerAlgorithms(destinationRflx reflect.Value) {
+	for keyField, algorithmField := range customerKeyAlgorithms {
+		keyRflx := destinationRflx.FieldByName(keyField)
+		algorithmRflx := destinationRflx.FieldByName(algorithmField)
+		if !keyRflx.IsValid() || !algorithmRflx.IsValid() || keyRflx.IsNil() || !algorithmRflx.IsNil() {
+			continue
+		}
+		algorithmRflx.Set(reflect.ValueOf(aws.String(s3.ServerSideEncryptionAes256)))
+	}
+}
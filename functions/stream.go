@@ -0,0 +1,244 @@
+package functions
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config/fields"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// errCodeInvalidRange is returned by the service when the range cannot be satisfied
+const errCodeInvalidRange = "InvalidRange"
+
+// validateStdout rejects the flags that cannot be combined with a download to the standard output
+func validateStdout(c *cli.Context) error {
+	if c.Bool(flags.Resume) {
+		return &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.Resume,
+		}
+	}
+	return nil
+}
+
+// getObjectToStdout writes the object to the standard output with a single GET,
+// nothing else is written to the standard output so the object can be piped
+func getObjectToStdout(c *cli.Context, cosContext *utils.CosContext, input *s3.GetObjectInput) (err error) {
+	var client s3iface.S3API
+	if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+		return
+	}
+
+	var limiter *bandwidthLimiter
+	if limiter, err = getBandwidthLimiter(c, cosContext); err != nil {
+		return
+	}
+
+	var output *s3.GetObjectOutput
+	if output, err = client.GetObject(input); err != nil {
+		return
+	}
+	defer output.Body.Close()
+
+	tracker := newTransferTracker(aws.Int64Value(output.ContentLength), limiter)
+	_, err = io.Copy(&streamWriter{Writer: cosContext.Stdout(), tracker: tracker}, output.Body)
+	return
+}
+
+// downloadToStdout writes the object to the standard output,
+// fetching its parts concurrently like the Downloader but writing them in order
+func downloadToStdout(c *cli.Context, cosContext *utils.CosContext, input *s3.GetObjectInput) (err error) {
+	var downloader *s3manager.Downloader
+	if downloader, err = getDownloadParts(c); err != nil {
+		return
+	}
+
+	// A single range is fetched as it is
+	if input.Range != nil {
+		return getObjectToStdout(c, cosContext, input)
+	}
+
+	var client s3iface.S3API
+	if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+		return
+	}
+
+	var limiter *bandwidthLimiter
+	if limiter, err = getBandwidthLimiter(c, cosContext); err != nil {
+		return
+	}
+
+	download := &orderedDownload{
+		client:      client,
+		input:       input,
+		partSize:    downloader.PartSize,
+		concurrency: downloader.Concurrency,
+		tracker:     newTransferTracker(0, limiter),
+	}
+	return download.writeTo(cosContext.Stdout())
+}
+
+// getDownloadParts returns the part size and the concurrency of a download,
+// the Downloader defaults unless set by the user
+func getDownloadParts(c *cli.Context) (*s3manager.Downloader, error) {
+	downloader := &s3manager.Downloader{
+		PartSize:    s3manager.DefaultDownloadPartSize,
+		Concurrency: s3manager.DefaultDownloadConcurrency,
+	}
+	downloadOptions := map[string]string{
+		fields.PartSize:    flags.PartSize,
+		fields.Concurrency: flags.Concurrency,
+	}
+	errorHolder := new(error)
+	applyConfigDownloader(c, downloadOptions, errorHolder)(downloader)
+	if *errorHolder != nil {
+		return nil, *errorHolder
+	}
+
+	if downloader.PartSize <= 0 {
+		downloader.PartSize = s3manager.DefaultDownloadPartSize
+	}
+	return downloader, nil
+}
+
+// orderedDownload fetches the parts of an object with concurrent ranged GETs
+// and writes them sequentially, keeping at most concurrency parts in memory.
+// Every part after the first is guarded by If-Match against the ETag of the first one.
+type orderedDownload struct {
+	client      s3iface.S3API
+	input       *s3.GetObjectInput
+	partSize    int64
+	concurrency int
+	tracker     *transferProgress
+}
+
+// downloadedPart is the content of a part, or the error fetching it
+type downloadedPart struct {
+	content []byte
+	err     error
+}
+
+// getPart fetches the bytes of the object from start to end, both included,
+// the whole object when end is negative
+func (download *orderedDownload) getPart(start, end int64, ifMatch *string) (*s3.GetObjectOutput, []byte, error) {
+	input := *download.input
+	if end >= 0 {
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-%d", start, end))
+	}
+	if ifMatch != nil {
+		input.IfMatch = ifMatch
+	}
+	output, err := download.client.GetObject(&input)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer output.Body.Close()
+	content := bytes.NewBuffer(make([]byte, 0, aws.Int64Value(output.ContentLength)))
+	if _, err = content.ReadFrom(output.Body); err != nil {
+		return nil, nil, err
+	}
+	return output, content.Bytes(), nil
+}
+
+// writeTo downloads the object into the writer
+func (download *orderedDownload) writeTo(w io.Writer) error {
+	writer := &streamWriter{Writer: w, tracker: download.tracker}
+
+	// The first part gives the size and the ETag of the object
+	output, content, err := download.getPart(0, download.partSize-1, nil)
+	// An empty object has no range to satisfy
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == errCodeInvalidRange {
+		output, content, err = download.getPart(0, -1, nil)
+	}
+	if err != nil {
+		return err
+	}
+	total := totalFromContentRange(aws.StringValue(output.ContentRange))
+	if total < 0 {
+		total = int64(len(content))
+	}
+	download.tracker.setSize(total, download.partSize)
+	if _, err = writer.Write(content); err != nil {
+		return err
+	}
+
+	return download.writeParts(writer, download.partSize, total, output.ETag)
+}
+
+// writeParts writes the bytes of the object from start to end, excluded, fetching them part by part,
+// every part guarded by If-Match against the ETag
+func (download *orderedDownload) writeParts(writer io.Writer, start, end int64, etag *string) error {
+	parts := int((end - start + download.partSize - 1) / download.partSize)
+	if parts <= 0 {
+		return nil
+	}
+
+	// Each part is delivered on its own channel, the slots bound the parts in flight
+	results := make([]chan downloadedPart, parts)
+	for i := range results {
+		results[i] = make(chan downloadedPart, 1)
+	}
+	concurrency := download.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	slots := make(chan struct{}, concurrency)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for i := 0; i < parts; i++ {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			}
+			go func(i int) {
+				partStart := start + int64(i)*download.partSize
+				partEnd := partStart + download.partSize - 1
+				if partEnd >= end {
+					partEnd = end - 1
+				}
+				_, content, err := download.getPart(partStart, partEnd, etag)
+				results[i] <- downloadedPart{content: content, err: err}
+			}(i)
+		}
+	}()
+
+	for i := 0; i < parts; i++ {
+		part := <-results[i]
+		if part.err != nil {
+			return part.err
+		}
+		if _, err := writer.Write(part.content); err != nil {
+			return err
+		}
+		<-slots
+	}
+	return nil
+}
+
+// streamWriter records the bytes written sequentially to a stream
+type streamWriter struct {
+	io.Writer
+	tracker *transferProgress
+	offset  int64
+}
+
+func (w *streamWriter) Write(p []byte) (n int, err error) {
+	n, err = w.Writer.Write(p)
+	w.tracker.record(w.offset, int64(n))
+	w.offset += int64(n)
+	return
+}
@@ -0,0 +1,342 @@
+package functions
+
+import (
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config/fields"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/render"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// Sync directions
+const (
+	syncUpload   = "upload"
+	syncDownload = "download"
+)
+
+// Sync - synchronizes a local directory with the objects under a prefix in a bucket.
+// Only files or objects that are missing or changed, by size, modification time and ETag,
+// are transferred, using the S3 Manager Uploader or Downloader.
+// Parameter:
+//
+//	CLI Context Application
+//
+// Returns:
+//
+//	Error = zero or non-zero
+func Sync(c *cli.Context) (err error) {
+
+	// check the number of arguments
+	if c.NArg() != 1 {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidNArg,
+		}
+		return
+	}
+
+	// Validate the direction of the synchronization
+	direction := strings.ToLower(c.String(flags.Direction))
+	if direction == "" {
+		direction = syncUpload
+	}
+	if direction != syncUpload && direction != syncDownload {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.Direction,
+		}
+		return
+	}
+
+	// Load COS Context
+	var cosContext *utils.CosContext
+	if cosContext, err = GetCosContext(c); err != nil {
+		return
+	}
+
+	// Build ListObjectsV2Input
+	input := new(s3.ListObjectsV2Input)
+
+	// Required parameters for ListObjectsV2Input
+	mandatory := map[string]string{
+		fields.Bucket: flags.Bucket,
+	}
+
+	//
+	// Optional parameters for ListObjectsV2Input
+	options := map[string]string{
+		fields.Prefix: flags.Prefix,
+	}
+
+	//
+	// Check through user inputs for validation
+	if err = MapToSDKInput(c, input, mandatory, options); err != nil {
+		return
+	}
+
+	// The prefix is a directory, the keys of the files are under it
+	if prefix := aws.StringValue(input.Prefix); prefix != "" && !strings.HasSuffix(prefix, "/") {
+		input.Prefix = aws.String(prefix + "/")
+	}
+
+	localDir := c.Args().First()
+	bucket := aws.StringValue(input.Bucket)
+	prefix := aws.StringValue(input.Prefix)
+
+	// The source directory has to exist when uploading
+	if direction == syncUpload {
+		var info os.FileInfo
+		if info, err = cosContext.GetFileInfo(localDir); err != nil {
+			return
+		}
+		if !info.IsDir() {
+			err = &os.PathError{Op: "sync", Path: localDir, Err: syscall.ENOTDIR}
+			return
+		}
+	}
+
+	// The files share the bandwidth of the synchronization
+	var limiter *bandwidthLimiter
+	if limiter, err = getBandwidthLimiter(c, cosContext); err != nil {
+		return
+	}
+
+	// Setting client to do the call
+	var client s3iface.S3API
+	if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+		return
+	}
+
+	// List both sides
+	var objects []*s3.Object
+	if objects, err = listObjectsWithPrefix(client, input); err != nil {
+		return
+	}
+	var files map[string]*localFile
+	if direction == syncUpload {
+		files, err = listLocalFiles(cosContext, localDir, prefix)
+	} else {
+		files, err = listLocalFilesIfExists(cosContext, localDir, prefix)
+	}
+	if err != nil {
+		return
+	}
+
+	// Build the plan
+	var transfers, deletes []*render.TransferItem
+	if direction == syncUpload {
+		transfers, deletes = planSyncUpload(cosContext, files, objects)
+	} else {
+		transfers, deletes = planSyncDownload(cosContext, localDir, prefix, files, objects)
+	}
+	if !c.Bool(flags.Delete) {
+		deletes = nil
+	}
+
+	output := &render.SyncOutput{
+		Bucket:         bucket,
+		Prefix:         prefix,
+		LocalDirectory: localDir,
+		Direction:      direction,
+		DryRun:         c.Bool(flags.DryRun),
+		Operations:     append(transfers, deletes...),
+	}
+
+	// Execute the plan
+	if !output.DryRun {
+		if direction == syncUpload {
+			err = syncUploadFiles(c, cosContext, limiter, bucket, transfers)
+			if err == nil && len(deletes) > 0 {
+				err = deleteObjectKeys(client, &s3.DeleteObjectsInput{Bucket: aws.String(bucket)}, deletes)
+			}
+		} else {
+			err = syncDownloadObjects(c, cosContext, limiter, bucket, transfers)
+			for _, item := range deletes {
+				if errDelete := cosContext.Remove(item.Path); errDelete != nil {
+					item.Error = errDelete.Error()
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+		output.Failed = countFailed(output.Operations)
+	}
+
+	// Output the plan or the result
+	if err = cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input, output, nil); err != nil {
+		return
+	}
+
+	if output.Failed > 0 {
+		err = transferIncompleteError(output.Failed)
+	}
+
+	// Return
+	return
+}
+
+// listLocalFilesIfExists lists the destination directory of a download, which may not exist yet
+func listLocalFilesIfExists(cosContext *utils.CosContext, root, prefix string) (map[string]*localFile, error) {
+	if _, err := cosContext.GetFileInfo(root); os.IsNotExist(err) {
+		return map[string]*localFile{}, nil
+	}
+	return listLocalFiles(cosContext, root, prefix)
+}
+
+// planSyncUpload returns the files to upload, new or changed, and the objects that have no local file
+func planSyncUpload(cosContext *utils.CosContext, files map[string]*localFile,
+	objects []*s3.Object) (transfers, deletes []*render.TransferItem) {
+	remote := make(map[string]*s3.Object, len(objects))
+	for _, object := range objects {
+		remote[aws.StringValue(object.Key)] = object
+	}
+	for _, key := range sortedKeys(files) {
+		file := files[key]
+		if object, found := remote[key]; found {
+			if aws.Int64Value(object.Size) == file.Size &&
+				(!file.ModTime.After(aws.TimeValue(object.LastModified)) || sameContent(cosContext, file, object)) {
+				continue
+			}
+		}
+		transfers = append(transfers, &render.TransferItem{
+			Action: render.ActionUpload,
+			Key:    key,
+			Path:   file.Path,
+			Size:   file.Size,
+		})
+	}
+	for _, object := range objects {
+		key := aws.StringValue(object.Key)
+		// folder markers have no local file
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
+		if _, found := files[key]; !found {
+			deletes = append(deletes, &render.TransferItem{
+				Action: render.ActionDelete,
+				Key:    key,
+				Size:   aws.Int64Value(object.Size),
+			})
+		}
+	}
+	return
+}
+
+// planSyncDownload returns the objects to download, new or changed, and the local files that have no object
+func planSyncDownload(cosContext *utils.CosContext, root, prefix string, files map[string]*localFile,
+	objects []*s3.Object) (transfers, deletes []*render.TransferItem) {
+	remote := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		key := aws.StringValue(object.Key)
+		path := localPathForKey(root, prefix, key)
+		if path == "" {
+			continue
+		}
+		remote[key] = true
+		if file, found := files[key]; found {
+			if aws.Int64Value(object.Size) == file.Size &&
+				(!aws.TimeValue(object.LastModified).After(file.ModTime) || sameContent(cosContext, file, object)) {
+				continue
+			}
+		}
+		transfers = append(transfers, &render.TransferItem{
+			Action: render.ActionDownload,
+			Key:    key,
+			Path:   path,
+			Size:   aws.Int64Value(object.Size),
+		})
+	}
+	for _, key := range sortedKeys(files) {
+		if !remote[key] {
+			deletes = append(deletes, &render.TransferItem{
+				Action: render.ActionDelete,
+				Path:   files[key].Path,
+				Size:   files[key].Size,
+			})
+		}
+	}
+	return
+}
+
+// syncUploadFiles uploads the planned files with the S3 Manager Uploader
+func syncUploadFiles(c *cli.Context, cosContext *utils.CosContext, limiter *bandwidthLimiter, bucket string,
+	items []*render.TransferItem) (err error) {
+	if len(items) == 0 {
+		return
+	}
+
+	//
+	// S3 Manager Uploader Options
+	uploadOptions := map[string]string{
+		fields.PartSize:           flags.PartSize,
+		fields.Concurrency:        flags.Concurrency,
+		fields.LeavePartsOnErrors: flags.LeavePartsOnErrors,
+		fields.MaxUploadParts:     flags.MaxUploadParts,
+	}
+
+	// Error holding
+	errorHolder := new(error)
+
+	// Build a s3manager upload
+	var uploader utils.Uploader
+	if uploader, err = cosContext.GetUploader(c.String(flags.Region),
+		applyConfigUploader(c, uploadOptions, errorHolder)); err != nil {
+		return
+	}
+
+	// Uploader Error Checking
+	if *errorHolder != nil {
+		return *errorHolder
+	}
+
+	// The Content-Type of the files is detected
+	var detector *contentTypeDetector
+	if detector, err = newContentTypeDetector(cosContext); err != nil {
+		return
+	}
+
+	return uploadItems(uploader, cosContext, detector, limiter, &s3manager.UploadInput{Bucket: aws.String(bucket)},
+		items, 1)
+}
+
+// syncDownloadObjects downloads the planned objects with the S3 Manager Downloader
+func syncDownloadObjects(c *cli.Context, cosContext *utils.CosContext, limiter *bandwidthLimiter, bucket string,
+	items []*render.TransferItem) (err error) {
+	if len(items) == 0 {
+		return
+	}
+
+	// Options for Downloader
+	downloadOptions := map[string]string{
+		fields.PartSize:    flags.PartSize,
+		fields.Concurrency: flags.Concurrency,
+	}
+
+	// Error holder
+	errorHolder := new(error)
+
+	// Build a s3manager download
+	var downloader utils.Downloader
+	if downloader, err = cosContext.GetDownloader(c.String(flags.Region),
+		applyConfigDownloader(c, downloadOptions, errorHolder)); err != nil {
+		return
+	}
+
+	// Downloader Error Checking
+	if *errorHolder != nil {
+		return *errorHolder
+	}
+
+	return downloadItems(downloader, cosContext, limiter, &s3.GetObjectInput{Bucket: aws.String(bucket)}, items, 1)
+}
@@ -0,0 +1,434 @@
+//+build unit
+
+package functions_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config"
+	"github.com/IBM/ibmcloud-cos-cli/config/commands"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/cos"
+	"github.com/IBM/ibmcloud-cos-cli/di/providers"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli"
+)
+
+// mockLocalTree mocks the walk of a local directory with the given files and sizes
+func mockLocalTree(root string, files map[string]int64, modTime time.Time) {
+	providers.MockFileOperations.
+		On("Walk", root, mock.Anything).
+		Run(func(args mock.Arguments) {
+			walkFn := args.Get(1).(filepath.WalkFunc)
+			walkFn(root, utils.FakeFileInfo(root, 0, modTime, true), nil)
+			for name, size := range files {
+				path := filepath.Join(root, name)
+				walkFn(path, utils.FakeFileInfo(filepath.Base(path), size, modTime, false), nil)
+			}
+		}).
+		Return(nil).
+		Once()
+}
+
+// mockListObjectsV2Page mocks a single page listing with the given objects
+func mockListObjectsV2Page(objects []*s3.Object) {
+	providers.MockS3API.
+		On("ListObjectsV2Pages", mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+			return true
+		}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListObjectsV2Output, last bool) bool)
+			pager(&s3.ListObjectsV2Output{Contents: objects}, true)
+		}).
+		Return(nil).
+		Once()
+}
+
+func TestSyncUploadChangedFiles(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetPrefix := "backup/"
+	root := "localdir"
+	past := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("GetFileInfo", root).
+		Return(utils.FakeFileInfo(root, 0, now, true), nil).
+		Once()
+
+	mockLocalTree(root, map[string]int64{
+		"same.txt":      4,
+		"resized.txt":   10,
+		"sub/new.txt":   3,
+		"unchanged.txt": 5,
+	}, now)
+
+	mockListObjectsV2Page([]*s3.Object{
+		// same size, newer local file, ETag matching the local content
+		{Key: aws.String(targetPrefix + "same.txt"), Size: aws.Int64(4), LastModified: &past,
+			ETag: aws.String(`"098f6bcd4621d373cade4e832627b4f6"`)},
+		{Key: aws.String(targetPrefix + "resized.txt"), Size: aws.Int64(8), LastModified: &past},
+		// same size, remote newer than the local file
+		{Key: aws.String(targetPrefix + "unchanged.txt"), Size: aws.Int64(5), LastModified: aws.Time(now.Add(time.Hour))},
+		{Key: aws.String(targetPrefix + "extraneous.txt"), Size: aws.Int64(1), LastModified: &past},
+	})
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", filepath.Join(root, "same.txt")).
+		Return(utils.WrapString("test", nil), nil).
+		Once()
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", mock.Anything).
+		Return(utils.WrapString("content", nil), nil)
+
+	uploadedKeys := make([]string, 0)
+	providers.MockUploaderAPI.
+		On("UploadWithIterator", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			iterator := args.Get(1).(s3manager.BatchUploadIterator)
+			for iterator.Next() {
+				object := iterator.UploadObject()
+				assert.Equal(t, targetBucket, aws.StringValue(object.Object.Bucket))
+				uploadedKeys = append(uploadedKeys, aws.StringValue(object.Object.Key))
+				object.After()
+			}
+		}).
+		Return(nil).
+		Once()
+
+	var deleteCapture *s3.DeleteObjectsInput
+	providers.MockS3API.
+		On("DeleteObjects", mock.MatchedBy(func(input *s3.DeleteObjectsInput) bool {
+			deleteCapture = input
+			return true
+		})).
+		Return(new(s3.DeleteObjectsOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Sync, "--bucket", targetBucket,
+		"--" + flags.Prefix, targetPrefix,
+		"--" + flags.Delete,
+		"--" + flags.Region, "REG",
+		root,
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+
+	assert.Equal(t, []string{targetPrefix + "resized.txt", targetPrefix + "sub/new.txt"}, uploadedKeys)
+	assert.NotNil(t, deleteCapture)
+	assert.Equal(t, targetBucket, aws.StringValue(deleteCapture.Bucket))
+	assert.Len(t, deleteCapture.Delete.Objects, 1)
+	assert.Equal(t, targetPrefix+"extraneous.txt", aws.StringValue(deleteCapture.Delete.Objects[0].Key))
+
+	// capture all output //
+	output := providers.FakeUI.Outputs()
+	errors := providers.FakeUI.Errors()
+	// assert OK
+	assert.Contains(t, output, "OK")
+	assert.Contains(t, output, "3 operation(s) completed, 0 failed.")
+	// assert Not Fail
+	assert.NotContains(t, errors, "FAIL")
+}
+
+// symlinkInfo is the information of a symbolic link, as walked without following it
+type symlinkInfo struct {
+	os.FileInfo
+}
+
+func (symlinkInfo) Mode() os.FileMode { return os.ModeSymlink | 0777 }
+
+func TestSyncUploadFollowsLinksAndKeepsFolderMarkers(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	root := "localdir"
+	past := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	link := filepath.Join(root, "link.txt")
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("GetFileInfo", root).
+		Return(utils.FakeFileInfo(root, 0, now, true), nil).
+		Once()
+
+	// the link itself was just created, its target is the same as the object
+	providers.MockFileOperations.
+		On("Walk", root, mock.Anything).
+		Run(func(args mock.Arguments) {
+			walkFn := args.Get(1).(filepath.WalkFunc)
+			walkFn(root, utils.FakeFileInfo(root, 0, now, true), nil)
+			walkFn(link, symlinkInfo{utils.FakeFileInfo("link.txt", 42, now, false)}, nil)
+		}).
+		Return(nil).
+		Once()
+	providers.MockFileOperations.
+		On("GetFileInfo", link).
+		Return(utils.FakeFileInfo("target.txt", 5, past, false), nil).
+		Once()
+
+	mockListObjectsV2Page([]*s3.Object{
+		{Key: aws.String("link.txt"), Size: aws.Int64(5), LastModified: &now},
+		{Key: aws.String("folder/"), Size: aws.Int64(0), LastModified: &past},
+		{Key: aws.String("extraneous.txt"), Size: aws.Int64(1), LastModified: &past},
+	})
+
+	var deleteCapture *s3.DeleteObjectsInput
+	providers.MockS3API.
+		On("DeleteObjects", mock.MatchedBy(func(input *s3.DeleteObjectsInput) bool {
+			deleteCapture = input
+			return true
+		})).
+		Return(new(s3.DeleteObjectsOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Sync, "--bucket", targetBucket,
+		"--" + flags.Delete,
+		"--" + flags.Region, "REG",
+		root,
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// the target of the link is up to date
+	providers.MockUploaderAPI.AssertNotCalled(t, "UploadWithIterator", mock.Anything, mock.Anything)
+	// the folder marker is kept
+	if assert.NotNil(t, deleteCapture) && assert.Len(t, deleteCapture.Delete.Objects, 1) {
+		assert.Equal(t, "extraneous.txt", aws.StringValue(deleteCapture.Delete.Objects[0].Key))
+	}
+}
+
+func TestSyncUploadPrefixWithoutSlash(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	root := "localdir"
+	past := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("GetFileInfo", root).
+		Return(utils.FakeFileInfo(root, 0, now, true), nil).
+		Once()
+
+	mockLocalTree(root, map[string]int64{"a.jpg": 5}, past)
+
+	var listCapture *s3.ListObjectsV2Input
+	providers.MockS3API.
+		On("ListObjectsV2Pages", mock.MatchedBy(func(input *s3.ListObjectsV2Input) bool {
+			listCapture = input
+			return true
+		}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListObjectsV2Output, last bool) bool)
+			pager(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+				{Key: aws.String("photos/a.jpg"), Size: aws.Int64(5), LastModified: &now},
+				{Key: aws.String("photos/old.jpg"), Size: aws.Int64(1), LastModified: &past},
+			}}, true)
+		}).
+		Return(nil).
+		Once()
+
+	var deleteCapture *s3.DeleteObjectsInput
+	providers.MockS3API.
+		On("DeleteObjects", mock.MatchedBy(func(input *s3.DeleteObjectsInput) bool {
+			deleteCapture = input
+			return true
+		})).
+		Return(new(s3.DeleteObjectsOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Sync, "--bucket", targetBucket,
+		"--" + flags.Prefix, "photos",
+		"--" + flags.Delete,
+		"--" + flags.Region, "REG",
+		root,
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// the prefix is taken as a directory, the files are the objects under it
+	assert.Equal(t, "photos/", aws.StringValue(listCapture.Prefix))
+	providers.MockUploaderAPI.AssertNotCalled(t, "UploadWithIterator", mock.Anything, mock.Anything)
+	if assert.NotNil(t, deleteCapture) && assert.Len(t, deleteCapture.Delete.Objects, 1) {
+		assert.Equal(t, "photos/old.jpg", aws.StringValue(deleteCapture.Delete.Objects[0].Key))
+	}
+}
+
+func TestSyncDownloadDryRun(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	root := "localdir"
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("GetFileInfo", root).
+		Return(nil, os.ErrNotExist).
+		Once()
+
+	mockListObjectsV2Page([]*s3.Object{
+		{Key: aws.String("a/b.txt"), Size: aws.Int64(4), LastModified: &now},
+		{Key: aws.String("folder/"), Size: aws.Int64(0), LastModified: &now},
+		{Key: aws.String("../escape.txt"), Size: aws.Int64(4), LastModified: &now},
+	})
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Sync, "--bucket", targetBucket,
+		"--" + flags.Direction, "download",
+		"--" + flags.DryRun,
+		"--" + flags.Region, "REG",
+		"--" + flags.Output, "json",
+		root,
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	providers.MockDownloaderAPI.AssertNotCalled(t, "DownloadWithIterator", mock.Anything, mock.Anything)
+
+	// capture all output //
+	output := providers.FakeUI.Outputs()
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, output, `"DryRun": true`)
+	assert.Contains(t, output, `"Key": "a/b.txt"`)
+	assert.Contains(t, output, filepath.Join(root, "a", "b.txt"))
+	assert.NotContains(t, output, "folder/")
+	assert.NotContains(t, output, "escape.txt")
+	// assert Not Fail
+	assert.NotContains(t, errors, "FAIL")
+}
+
+func TestSyncDownloadFailureRemovesPartialFile(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	root := "localdir"
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	failedPath := filepath.Join(root, "failed.txt")
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("GetFileInfo", root).
+		Return(nil, os.ErrNotExist).
+		Once()
+
+	mockListObjectsV2Page([]*s3.Object{
+		{Key: aws.String("failed.txt"), Size: aws.Int64(4), LastModified: &now},
+		{Key: aws.String("ok.txt"), Size: aws.Int64(4), LastModified: &now},
+	})
+
+	providers.MockFileOperations.On("MkdirAll", root).Return(nil)
+	providers.MockFileOperations.
+		On("WriteCloserOpen", mock.Anything).
+		Return(utils.WriteToString(nil, nil), nil)
+	providers.MockFileOperations.On("Remove", failedPath).Return(nil).Once()
+
+	providers.MockDownloaderAPI.
+		On("DownloadWithIterator", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			iterator := args.Get(1).(s3manager.BatchDownloadIterator)
+			for iterator.Next() {
+				iterator.DownloadObject().After()
+			}
+		}).
+		Return(s3manager.NewBatchError("BatchedDownloadIncomplete", "some objects have failed to download.",
+			[]s3manager.Error{{OrigErr: os.ErrDeadlineExceeded, Bucket: aws.String(targetBucket),
+				Key: aws.String("failed.txt")}})).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Sync, "--bucket", targetBucket,
+		"--" + flags.Direction, "download",
+		"--" + flags.Region, "REG",
+		root,
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero
+	assert.Equal(t, 1, *exitCode)
+	providers.MockFileOperations.AssertCalled(t, "Remove", failedPath)
+	providers.MockFileOperations.AssertNumberOfCalls(t, "Remove", 1)
+
+	// capture all output //
+	output := providers.FakeUI.Outputs()
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, output, "1 operation(s) completed, 1 failed.")
+	assert.Contains(t, errors, "FAIL")
+}
@@ -0,0 +1,472 @@
+package functions
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/render"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+const (
+	// maxDeleteObjects is the maximum number of keys accepted by a single DeleteObjects call
+	maxDeleteObjects = 1000
+
+	// defaultJobs is the number of objects transferred in parallel when --jobs is not set
+	defaultJobs = 4
+)
+
+// localFile describes a regular file found while walking a local directory
+type localFile struct {
+	Path    string
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// transferIncompleteError is returned by multi-object commands once the summary has been
+// displayed and at least one of the objects failed
+func transferIncompleteError(failed int) error {
+	return awserr.New("TransferIncomplete",
+		"some objects have failed to transfer: "+strconv.Itoa(failed), nil)
+}
+
+// parseJobs returns the number of objects to transfer in parallel
+func parseJobs(c *cli.Context) (int, error) {
+	if !c.IsSet(flags.Jobs) {
+		return defaultJobs, nil
+	}
+	jobs, err := strconv.Atoi(c.String(flags.Jobs))
+	if err == nil && jobs < 1 {
+		err = strconv.ErrRange
+	}
+	if err != nil {
+		return 0, &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.Jobs,
+			IError:     err,
+		}
+	}
+	return jobs, nil
+}
+
+// listLocalFiles walks the root directory and returns every regular file in it, or linked from it,
+// keyed by the object key the file maps to under the given prefix
+func listLocalFiles(cosContext *utils.CosContext, root, prefix string) (map[string]*localFile, error) {
+	files := make(map[string]*localFile)
+	err := cosContext.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		// a symbolic link is uploaded with the content of its target, a link to a directory is not followed
+		if info.Mode()&os.ModeSymlink != 0 {
+			if target, statErr := cosContext.GetFileInfo(path); statErr == nil {
+				if target.IsDir() {
+					return nil
+				}
+				info = target
+			}
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		key := prefix + filepath.ToSlash(rel)
+		files[key] = &localFile{
+			Path:    path,
+			Key:     key,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}
+		return nil
+	})
+	return files, err
+}
+
+// listObjectsWithPrefix pages through ListObjectsV2 and returns every object under the input prefix
+func listObjectsWithPrefix(client s3iface.S3API, input *s3.ListObjectsV2Input) ([]*s3.Object, error) {
+	objects := make([]*s3.Object, 0)
+	err := client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		objects = append(objects, page.Contents...)
+		return true
+	})
+	return objects, err
+}
+
+// localPathForKey maps an object key under a prefix to a path under the root directory,
+// returns an empty string for keys that do not map to a file (folder markers)
+// or that would escape the root directory
+func localPathForKey(root, prefix, key string) string {
+	rel := strings.TrimLeft(strings.TrimPrefix(key, prefix), "/")
+	if rel == "" || strings.HasSuffix(rel, "/") {
+		return ""
+	}
+	rel = filepath.Clean(filepath.FromSlash(rel))
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return ""
+	}
+	return filepath.Join(root, rel)
+}
+
+// sortedKeys returns the keys of a map of local files in lexical order
+func sortedKeys(files map[string]*localFile) []string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fileMD5 computes the hex encoded MD5 digest of a local file
+func fileMD5(cosContext *utils.CosContext, location string) (string, error) {
+	file, err := cosContext.ReadSeekerCloserOpen(location)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := md5.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// sameContent reports whether a local file has the same content as an object,
+// comparing the file MD5 with the object ETag when the ETag is a plain MD5 digest.
+// Objects uploaded in multiple parts carry a composite ETag and are reported as different.
+func sameContent(cosContext *utils.CosContext, file *localFile, object *s3.Object) bool {
+	etag := strings.Trim(aws.StringValue(object.ETag), `"`)
+	if etag == "" || strings.Contains(etag, "-") {
+		return false
+	}
+	sum, err := fileMD5(cosContext, file.Path)
+	return err == nil && strings.EqualFold(sum, etag)
+}
+
+// uploadFilesIterator implements s3manager.BatchUploadIterator,
+// opening each file only when the uploader reaches it.
+// Files that cannot be opened are marked as failed and skipped.
+// The files are read within the bandwidth of the limiter, when not nil.
+type uploadFilesIterator struct {
+	cosContext *utils.CosContext
+	detector   *contentTypeDetector
+	limiter    *bandwidthLimiter
+	template   s3manager.UploadInput
+	items      []*render.TransferItem
+	index      int
+	current    s3manager.BatchUploadObject
+}
+
+func newUploadFilesIterator(cosContext *utils.CosContext, detector *contentTypeDetector, limiter *bandwidthLimiter,
+	template *s3manager.UploadInput, items []*render.TransferItem) *uploadFilesIterator {
+	return &uploadFilesIterator{
+		cosContext: cosContext,
+		detector:   detector,
+		limiter:    limiter,
+		template:   *template,
+		items:      items,
+	}
+}
+
+// Next opens the next file to upload
+func (iter *uploadFilesIterator) Next() bool {
+	for iter.index < len(iter.items) {
+		item := iter.items[iter.index]
+		iter.index++
+		if item.Skipped || item.Error != "" {
+			continue
+		}
+		body, err := iter.cosContext.ReadSeekerCloserOpen(item.Path)
+		if err != nil {
+			item.Error = err.Error()
+			continue
+		}
+		input := iter.template
+		input.Key = aws.String(item.Key)
+		input.Body = body
+		// Detect the Content-Type of every file when not given
+		if input.ContentType == nil {
+			if input.ContentType, input.Body, err = iter.detector.detect(item.Path, body); err != nil {
+				body.Close()
+				item.Error = err.Error()
+				continue
+			}
+		}
+		if iter.limiter != nil {
+			input.Body = wrapProgressReader(input.Body, newLimitedTracker(item.Size, iter.limiter))
+		}
+		iter.current = s3manager.BatchUploadObject{
+			Object: &input,
+			After:  body.Close,
+		}
+		return true
+	}
+	return false
+}
+
+// Err never fails the whole batch, open errors are recorded per file
+func (iter *uploadFilesIterator) Err() error {
+	return nil
+}
+
+// UploadObject returns the current upload
+func (iter *uploadFilesIterator) UploadObject() s3manager.BatchUploadObject {
+	return iter.current
+}
+
+// downloadFilesIterator implements s3manager.BatchDownloadIterator,
+// creating the parent directories and the destination file only when the downloader reaches it.
+// Files that cannot be created are marked as failed and skipped.
+// The files are written within the bandwidth of the limiter, when not nil.
+type downloadFilesIterator struct {
+	cosContext *utils.CosContext
+	limiter    *bandwidthLimiter
+	template   s3.GetObjectInput
+	items      []*render.TransferItem
+	index      int
+	current    s3manager.BatchDownloadObject
+	created    []*render.TransferItem
+}
+
+func newDownloadFilesIterator(cosContext *utils.CosContext, limiter *bandwidthLimiter, template *s3.GetObjectInput,
+	items []*render.TransferItem) *downloadFilesIterator {
+	return &downloadFilesIterator{
+		cosContext: cosContext,
+		limiter:    limiter,
+		template:   *template,
+		items:      items,
+	}
+}
+
+// Next creates the next file to download into
+func (iter *downloadFilesIterator) Next() bool {
+	for iter.index < len(iter.items) {
+		item := iter.items[iter.index]
+		iter.index++
+		if err := iter.cosContext.MkdirAll(filepath.Dir(item.Path)); err != nil {
+			item.Error = err.Error()
+			continue
+		}
+		file, err := iter.cosContext.WriteCloserOpen(item.Path)
+		if err != nil {
+			item.Error = err.Error()
+			continue
+		}
+		iter.created = append(iter.created, item)
+		input := iter.template
+		input.Key = aws.String(item.Key)
+		var writer io.WriterAt = file
+		if iter.limiter != nil {
+			writer = &progressWriter{WriteCloser: file, tracker: newLimitedTracker(item.Size, iter.limiter)}
+		}
+		iter.current = s3manager.BatchDownloadObject{
+			Object: &input,
+			Writer: writer,
+			After:  file.Close,
+		}
+		return true
+	}
+	return false
+}
+
+// Err never fails the whole batch, create errors are recorded per file
+func (iter *downloadFilesIterator) Err() error {
+	return nil
+}
+
+// DownloadObject returns the current download
+func (iter *downloadFilesIterator) DownloadObject() s3manager.BatchDownloadObject {
+	return iter.current
+}
+
+// applyBatchError records the per object errors of a batch error on the matching items,
+// any other error is returned as is
+func applyBatchError(err error, items []*render.TransferItem) error {
+	batchError, ok := err.(*s3manager.BatchError)
+	if !ok {
+		return err
+	}
+	byKey := make(map[string]*render.TransferItem, len(items))
+	for _, item := range items {
+		byKey[item.Key] = item
+	}
+	for _, objectError := range batchError.Errors {
+		if item, found := byKey[aws.StringValue(objectError.Key)]; found && item.Error == "" {
+			item.Error = objectError.OrigErr.Error()
+		}
+	}
+	return nil
+}
+
+// removeFailed removes the partial files left by the failed downloads of the iterator
+func (iter *downloadFilesIterator) removeFailed() {
+	for _, item := range iter.created {
+		if item.Error != "" {
+			iter.cosContext.Remove(item.Path)
+		}
+	}
+}
+
+// uploadItems uploads the items with the S3 Manager Uploader batch iterator,
+// spreading them over up to jobs iterators running in parallel, which share the bandwidth of the limiter.
+// Per file errors are recorded on the items.
+func uploadItems(uploader utils.Uploader, cosContext *utils.CosContext, detector *contentTypeDetector,
+	limiter *bandwidthLimiter, template *s3manager.UploadInput, items []*render.TransferItem, jobs int) error {
+	if jobs > len(items) {
+		jobs = len(items)
+	}
+	shards := make([][]*render.TransferItem, jobs)
+	for index, item := range items {
+		shards[index%jobs] = append(shards[index%jobs], item)
+	}
+
+	errs := make([]error, jobs)
+	var wg sync.WaitGroup
+	for index := range shards {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			iterator := newUploadFilesIterator(cosContext, detector, limiter, template, shards[index])
+			errs[index] = applyBatchError(uploader.UploadWithIterator(aws.BackgroundContext(), iterator),
+				shards[index])
+		}(index)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadItems downloads the items with the S3 Manager Downloader batch iterator,
+// spreading them over up to jobs iterators running in parallel, which share the bandwidth of the limiter.
+// Per object errors are recorded on the items and their partial files removed.
+func downloadItems(downloader utils.Downloader, cosContext *utils.CosContext, limiter *bandwidthLimiter,
+	template *s3.GetObjectInput, items []*render.TransferItem, jobs int) error {
+	if jobs > len(items) {
+		jobs = len(items)
+	}
+	shards := make([][]*render.TransferItem, jobs)
+	for index, item := range items {
+		shards[index%jobs] = append(shards[index%jobs], item)
+	}
+
+	errs := make([]error, jobs)
+	var wg sync.WaitGroup
+	for index := range shards {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			iterator := newDownloadFilesIterator(cosContext, limiter, template, shards[index])
+			errs[index] = applyBatchError(downloader.DownloadWithIterator(aws.BackgroundContext(), iterator),
+				shards[index])
+			// In case of error removes incomplete downloads
+			iterator.removeFailed()
+		}(index)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteObjectKeys deletes the keys of the items, or their versions when set, in batches
+// of up to 1000 keys and records the per key errors on the items.
+// The template holds the bucket and whether the governance retention is bypassed.
+func deleteObjectKeys(client s3iface.S3API, template *s3.DeleteObjectsInput, items []*render.TransferItem) error {
+	type objectVersion struct{ key, versionId string }
+	byKey := make(map[objectVersion]*render.TransferItem, len(items))
+	for start := 0; start < len(items); start += maxDeleteObjects {
+		end := start + maxDeleteObjects
+		if end > len(items) {
+			end = len(items)
+		}
+		identifiers := make([]*s3.ObjectIdentifier, 0, end-start)
+		for _, item := range items[start:end] {
+			byKey[objectVersion{item.Key, item.VersionId}] = item
+			identifier := &s3.ObjectIdentifier{Key: aws.String(item.Key)}
+			if item.VersionId != "" {
+				identifier.VersionId = aws.String(item.VersionId)
+			}
+			identifiers = append(identifiers, identifier)
+		}
+		output, err := client.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket:                    template.Bucket,
+			BypassGovernanceRetention: template.BypassGovernanceRetention,
+			Delete: &s3.Delete{
+				Objects: identifiers,
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		for _, deleteError := range output.Errors {
+			errorKey := objectVersion{aws.StringValue(deleteError.Key), aws.StringValue(deleteError.VersionId)}
+			if item, found := byKey[errorKey]; found {
+				item.Error = aws.StringValue(deleteError.Code) + ": " + aws.StringValue(deleteError.Message)
+			}
+		}
+	}
+	return nil
+}
+
+// newTransferSummary aggregates the items of a multi-object transfer
+func newTransferSummary(action, bucket string, items []*render.TransferItem) *render.TransferSummaryOutput {
+	output := &render.TransferSummaryOutput{
+		Bucket: bucket,
+		Action: action,
+		Items:  items,
+	}
+	for _, item := range items {
+		if item.Error != "" {
+			output.Failed++
+			continue
+		}
+		if item.Skipped {
+			output.Skipped++
+			continue
+		}
+		output.Succeeded++
+		output.TotalBytes += item.Size
+	}
+	return output
+}
+
+// countFailed returns the number of items that carry an error
+func countFailed(items []*render.TransferItem) (failed int) {
+	for _, item := range items {
+		if item.Error != "" {
+			failed++
+		}
+	}
+	return
+}
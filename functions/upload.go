@@ -5,9 +5,13 @@ import (
 
 	"github.com/IBM/ibmcloud-cos-cli/errors"
 
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
 	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
 	"github.com/IBM/ibmcloud-cos-cli/config/fields"
 	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/render"
 	"github.com/IBM/ibmcloud-cos-cli/utils"
 	"github.com/urfave/cli"
 )
@@ -30,6 +34,11 @@ func Upload(c *cli.Context) (err error) {
 		return
 	}
 
+	// Upload a whole directory tree
+	if c.Bool(flags.Recursive) {
+		return uploadDirectory(c)
+	}
+
 	// Build S3 Manager UploadInput
 	input := new(s3manager.UploadInput)
 
@@ -43,13 +52,42 @@ func Upload(c *cli.Context) (err error) {
 	//
 	// Optional parameters for S3 Manager UploadInput
 	options := map[string]string{
-		fields.CacheControl:       flags.CacheControl,
-		fields.ContentDisposition: flags.ContentDisposition,
-		fields.ContentEncoding:    flags.ContentEncoding,
-		fields.ContentLanguage:    flags.ContentLanguage,
-		fields.ContentMD5:         flags.ContentMD5,
-		fields.ContentType:        flags.ContentType,
-		fields.Metadata:           flags.Metadata,
+		fields.CacheControl:         flags.CacheControl,
+		fields.ContentDisposition:   flags.ContentDisposition,
+		fields.ContentEncoding:      flags.ContentEncoding,
+		fields.ContentLanguage:      flags.ContentLanguage,
+		fields.ContentMD5:           flags.ContentMD5,
+		fields.ContentType:          flags.ContentType,
+		fields.Metadata:             flags.Metadata,
+		fields.SSECustomerAlgorithm: flags.SSECustomerAlgorithm,
+		fields.SSECustomerKey:       flags.SSECustomerKey,
+	}
+
+	// The journal of a resumable upload is kept next to a file
+	if c.Bool(flags.Resume) && c.String(flags.File) == stdStream {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.Resume,
+		}
+		return
+	}
+
+	// A directory is archived on the fly instead of opening a file
+	var archive string
+	if archive, err = getArchive(c, flags.Resume, flags.ContentMD5); err != nil {
+		return
+	}
+	if archive != "" {
+		delete(mandatory, fields.Body)
+		if c.String(flags.File) == "" {
+			err = &errors.CommandError{
+				CLIContext: c,
+				Cause:      errors.MissingRequiredFlag,
+				Flag:       flags.File,
+			}
+			return
+		}
 	}
 
 	// Check through user inputs for validation
@@ -77,13 +115,100 @@ func Upload(c *cli.Context) (err error) {
 		return
 	}
 
+	// Skip the upload when the bucket already holds the file
+	var skipper *uploadSkipper
+	if skipper, err = getUploadSkipper(c, uploadOptions, flags.Archive, flags.Compress, flags.EncryptionKeyFile); err != nil {
+		return
+	}
+	if skipper != nil {
+		var client s3iface.S3API
+		if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+			return
+		}
+		var skip bool
+		if skip, err = skipper.skipObject(client, &s3.HeadObjectInput{
+			Bucket:               input.Bucket,
+			Key:                  input.Key,
+			SSECustomerAlgorithm: input.SSECustomerAlgorithm,
+			SSECustomerKey:       input.SSECustomerKey,
+		}, input.Body); err != nil || skip {
+			if skip {
+				err = displaySkipped(c, cosContext, input, aws.StringValue(input.Bucket), aws.StringValue(input.Key),
+					c.String(flags.File), input.Body)
+			}
+			return
+		}
+	}
+
+	// Stream the archive of the directory, its parts are uploaded while the directory is read
+	if archive != "" {
+		archiveReader := newArchiveReader(cosContext, c.String(flags.File), archive)
+		defer archiveReader.Close()
+		input.Body = archiveReader
+		if input.ContentType == nil {
+			input.ContentType = aws.String(archiveContentTypes[archive])
+		}
+	}
+
+	// Detect the Content-Type when not given
+	if input.ContentType == nil {
+		var detector *contentTypeDetector
+		if detector, err = newContentTypeDetector(cosContext); err != nil {
+			return
+		}
+		if input.ContentType, input.Body, err = detector.detect(c.String(flags.File), input.Body); err != nil {
+			return
+		}
+	}
+
+	// Compress the file on the fly when asked to,
+	// the Uploader then reads its parts as they are compressed
+	var compression string
+	if compression, err = getCompression(c, flags.Resume, flags.ContentEncoding, flags.ContentMD5); err != nil {
+		return
+	}
+	if compression != "" {
+		var compressed io.ReadCloser
+		if input.Metadata, compressed, err = compressBody(compression, input.Metadata, input.Body); err != nil {
+			return
+		}
+		defer compressed.Close()
+		input.Body = compressed
+		input.ContentEncoding = aws.String(compression)
+	}
+
+	// Encrypt the file on the client when asked to,
+	// the Uploader then reads its parts as they are encrypted
+	var masterKey []byte
+	if masterKey, err = getMasterKey(c, cosContext, flags.Resume, flags.ContentMD5); err != nil {
+		return
+	}
+	if masterKey != nil {
+		if input.Metadata, input.Body, err = encryptBody(masterKey, input.Metadata, input.Body); err != nil {
+			return
+		}
+	}
+
+	// Limit the bandwidth of the transfer
+	var limiter *bandwidthLimiter
+	if limiter, err = getBandwidthLimiter(c, cosContext); err != nil {
+		return
+	}
+
+	// Report the progress of the upload while the body is read
+	progress := newTransferProgress(c, cosContext, aws.StringValue(input.Key), bodyLength(input.Body), limiter)
+	defer progress.finish()
+	if !c.Bool(flags.Resume) {
+		input.Body = wrapProgressReader(input.Body, progress)
+	}
+
 	// Error holding
 	errorHolder := new(error)
 
 	// Build a s3manager upload
 	var uploader utils.Uploader
 	if uploader, err = cosContext.GetUploader(c.String(flags.Region),
-		applyConfigUploader(c, uploadOptions, errorHolder)); err != nil {
+		applyConfigUploader(c, uploadOptions, errorHolder), progress.uploaderOption); err != nil {
 		return
 	}
 
@@ -94,7 +219,12 @@ func Upload(c *cli.Context) (err error) {
 
 	// Upload Op
 	var output *s3manager.UploadOutput
-	if output, err = uploader.Upload(input); err != nil {
+	if c.Bool(flags.Resume) {
+		output, err = uploadResumable(c, cosContext, input, uploadOptions, progress)
+	} else {
+		output, err = uploader.Upload(input)
+	}
+	if err != nil {
 		return
 	}
 	// Success
@@ -105,6 +235,157 @@ func Upload(c *cli.Context) (err error) {
 	return
 }
 
+// uploadDirectory - walks the directory given by --file and uploads every file in it
+// with several S3 Manager Uploader batch iterators running in parallel, keys are the relative paths under --prefix.
+// Failures are collected per file and reported once every file has been processed.
+func uploadDirectory(c *cli.Context) (err error) {
+
+	// Build S3 Manager UploadInput shared by all the files
+	input := new(s3manager.UploadInput)
+
+	// Required parameters for S3 Manager UploadInput
+	mandatory := map[string]string{
+		fields.Bucket: flags.Bucket,
+	}
+
+	//
+	// Optional parameters for S3 Manager UploadInput
+	options := map[string]string{
+		fields.CacheControl:         flags.CacheControl,
+		fields.ContentDisposition:   flags.ContentDisposition,
+		fields.ContentEncoding:      flags.ContentEncoding,
+		fields.ContentLanguage:      flags.ContentLanguage,
+		fields.ContentType:          flags.ContentType,
+		fields.Metadata:             flags.Metadata,
+		fields.SSECustomerAlgorithm: flags.SSECustomerAlgorithm,
+		fields.SSECustomerKey:       flags.SSECustomerKey,
+	}
+
+	// Check through user inputs for validation
+	if err = MapToSDKInput(c, input, mandatory, options); err != nil {
+		return
+	}
+
+	// The directory to upload
+	root := c.String(flags.File)
+	if root == "" {
+		err = &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.MissingRequiredFlag,
+			Flag:       flags.File,
+		}
+		return
+	}
+
+	//
+	// S3 Manager Uploader Options
+	uploadOptions := map[string]string{
+		fields.PartSize:           flags.PartSize,
+		fields.Concurrency:        flags.Concurrency,
+		fields.LeavePartsOnErrors: flags.LeavePartsOnErrors,
+		fields.MaxUploadParts:     flags.MaxUploadParts,
+	}
+
+	// Load COS Context
+	var cosContext *utils.CosContext
+	if cosContext, err = GetCosContext(c); err != nil {
+		return
+	}
+
+	// Client side encryption and compression apply to a single file
+	if _, err = getMasterKey(c, cosContext, flags.Recursive); err != nil {
+		return
+	}
+	if _, err = getCompression(c, flags.Recursive); err != nil {
+		return
+	}
+	if _, err = getArchive(c, flags.Recursive); err != nil {
+		return
+	}
+
+	// Files the bucket already holds are skipped
+	var skipper *uploadSkipper
+	if skipper, err = getUploadSkipper(c, uploadOptions); err != nil {
+		return
+	}
+
+	// Number of files to upload in parallel
+	var jobs int
+	if jobs, err = parseJobs(c); err != nil {
+		return
+	}
+
+	// The files share the bandwidth of the upload
+	var limiter *bandwidthLimiter
+	if limiter, err = getBandwidthLimiter(c, cosContext); err != nil {
+		return
+	}
+
+	// List the files to upload
+	var files map[string]*localFile
+	if files, err = listLocalFiles(cosContext, root, c.String(flags.Prefix)); err != nil {
+		return
+	}
+	items := make([]*render.TransferItem, 0, len(files))
+	for _, key := range sortedKeys(files) {
+		items = append(items, &render.TransferItem{
+			Action: render.ActionUpload,
+			Key:    key,
+			Path:   files[key].Path,
+			Size:   files[key].Size,
+		})
+	}
+
+	if skipper != nil {
+		var client s3iface.S3API
+		if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+			return
+		}
+		if err = skipper.skipItems(cosContext, client, aws.StringValue(input.Bucket), c.String(flags.Prefix), items); err != nil {
+			return
+		}
+	}
+
+	// Error holding
+	errorHolder := new(error)
+
+	// Build a s3manager upload
+	var uploader utils.Uploader
+	if uploader, err = cosContext.GetUploader(c.String(flags.Region),
+		applyConfigUploader(c, uploadOptions, errorHolder)); err != nil {
+		return
+	}
+
+	// Uploader Error Checking
+	if *errorHolder != nil {
+		return *errorHolder
+	}
+
+	// The Content-Type of the files is detected when not given
+	var detector *contentTypeDetector
+	if detector, err = newContentTypeDetector(cosContext); err != nil {
+		return
+	}
+
+	// Batch Upload Op, per file errors are recorded in the items
+	if err = uploadItems(uploader, cosContext, detector, limiter, input, items, jobs); err != nil {
+		return
+	}
+
+	// Output the summary
+	output := newTransferSummary(render.ActionUpload, aws.StringValue(input.Bucket), items)
+	if err = cosContext.GetDisplay(c.String(flags.Output), c.Bool(flags.JSON)).Display(input, output, nil); err != nil {
+		return
+	}
+
+	if output.Failed > 0 {
+		err = transferIncompleteError(output.Failed)
+	}
+
+	// Return
+	return
+}
+
 // Build Uploader from the Config
 func applyConfigUploader(c *cli.Context, options map[string]string, err *error) func(u *s3manager.Uploader) {
 	return func(u *s3manager.Uploader) {
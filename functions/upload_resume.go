@@ -0,0 +1,376 @@
+package functions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3iface"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
+	"github.com/IBM/ibmcloud-cos-cli/config/flags"
+	"github.com/IBM/ibmcloud-cos-cli/errors"
+	"github.com/IBM/ibmcloud-cos-cli/utils"
+	"github.com/urfave/cli"
+)
+
+// uploadJournalSuffix is appended to the uploaded file name to build the journal location
+const uploadJournalSuffix = ".cos-upload"
+
+// uploadJournal is the local state of a resumable multipart upload
+type uploadJournal struct {
+	Bucket   string
+	Key      string
+	UploadId string
+	PartSize int64
+	FileSize int64
+	ModTime  time.Time
+	Parts    map[int64]string
+}
+
+// matches reports whether the journal was written for the same object and the same file content
+func (journal *uploadJournal) matches(bucket, key string, info os.FileInfo) bool {
+	return journal.UploadId != "" &&
+		journal.Bucket == bucket &&
+		journal.Key == key &&
+		journal.FileSize == info.Size() &&
+		journal.ModTime.Equal(info.ModTime())
+}
+
+// readUploadJournal loads the journal of a previous upload, returns nil if there is none
+func readUploadJournal(cosContext *utils.CosContext, location string) *uploadJournal {
+	file, err := cosContext.ReadSeekerCloserOpen(location)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	journal := new(uploadJournal)
+	if err = json.NewDecoder(file).Decode(journal); err != nil {
+		return nil
+	}
+	return journal
+}
+
+// writeUploadJournal saves the journal, replacing the previous content
+func writeUploadJournal(cosContext *utils.CosContext, location string, journal *uploadJournal) error {
+	file, err := cosContext.WriteCloserOpen(location)
+	if err != nil {
+		return err
+	}
+	if err = json.NewEncoder(file).Encode(journal); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// uploadResumable - uploads the file in --file as a multipart upload whose progress is recorded
+// in a journal next to the file. When the journal of an interrupted upload of the same file is found,
+// the parts already stored, as listed by ListParts, are skipped and the upload is completed.
+// The tracker counts the bytes of the parts as they are sent.
+func uploadResumable(c *cli.Context, cosContext *utils.CosContext, input *s3manager.UploadInput,
+	uploadOptions map[string]string, tracker *transferProgress) (output *s3manager.UploadOutput, err error) {
+
+	// Uploader configuration, only part size, concurrency and maximum number of parts apply
+	config := &s3manager.Uploader{
+		PartSize:       s3manager.DefaultUploadPartSize,
+		Concurrency:    s3manager.DefaultUploadConcurrency,
+		MaxUploadParts: s3manager.MaxUploadParts,
+	}
+	errorHolder := new(error)
+	applyConfigUploader(c, uploadOptions, errorHolder)(config)
+	if *errorHolder != nil {
+		return nil, *errorHolder
+	}
+
+	// The file is read from the body already opened
+	body, ok := input.Body.(io.ReadSeeker)
+	if !ok {
+		return nil, &errors.CommandError{
+			CLIContext: c,
+			Cause:      errors.InvalidValue,
+			Flag:       flags.Resume,
+			IError:     fmt.Errorf("--%s requires a file", flags.Resume),
+		}
+	}
+	location := c.String(flags.File)
+	var info os.FileInfo
+	if info, err = cosContext.GetFileInfo(location); err != nil {
+		return
+	}
+
+	// Setting client to do the calls
+	var client s3iface.S3API
+	if client, err = cosContext.GetClient(c.String(flags.Region)); err != nil {
+		return
+	}
+
+	bucket := aws.StringValue(input.Bucket)
+	key := aws.StringValue(input.Key)
+	journalLocation := location + uploadJournalSuffix
+
+	// Continue the previous upload or start a new one
+	journal := readUploadJournal(cosContext, journalLocation)
+	if journal != nil && !journal.matches(bucket, key, info) {
+		// the file or the destination changed, the parts already uploaded are useless
+		if journal.UploadId != "" && journal.Bucket != "" && journal.Key != "" {
+			client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(journal.Bucket),
+				Key:      aws.String(journal.Key),
+				UploadId: aws.String(journal.UploadId),
+			})
+		}
+		journal = nil
+	}
+
+	// The service is the source of truth about the parts already stored,
+	// an upload that was aborted or completed meanwhile is started again
+	if journal != nil {
+		if err = refreshUploadJournal(client, journal); err != nil {
+			if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != s3.ErrCodeNoSuchUpload {
+				return
+			}
+			journal, err = nil, nil
+		}
+	}
+
+	if journal == nil {
+		journal = &uploadJournal{
+			Bucket:   bucket,
+			Key:      key,
+			PartSize: resumablePartSize(config.PartSize, info.Size(), config.MaxUploadParts),
+			FileSize: info.Size(),
+			ModTime:  info.ModTime(),
+			Parts:    make(map[int64]string),
+		}
+		var created *s3.CreateMultipartUploadOutput
+		if created, err = client.CreateMultipartUpload(&s3.CreateMultipartUploadInput{
+			Bucket:             input.Bucket,
+			Key:                input.Key,
+			CacheControl:       input.CacheControl,
+			ContentDisposition: input.ContentDisposition,
+			ContentEncoding:    input.ContentEncoding,
+			ContentLanguage:    input.ContentLanguage,
+			ContentType:        input.ContentType,
+			Metadata:           input.Metadata,
+			// the parts are encrypted with the customer-provided key of the upload
+			SSECustomerAlgorithm: input.SSECustomerAlgorithm,
+			SSECustomerKey:       input.SSECustomerKey,
+		}); err != nil {
+			return
+		}
+		journal.UploadId = aws.StringValue(created.UploadId)
+		if err = writeUploadJournal(cosContext, journalLocation, journal); err != nil {
+			return
+		}
+	}
+
+	// The parts already stored count as transferred
+	tracker.setSize(journal.FileSize, journal.PartSize)
+	for partNumber := range journal.Parts {
+		tracker.cover((partNumber-1)*journal.PartSize, journal.partLength(partNumber))
+	}
+
+	// Upload the missing parts
+	if err = uploadMissingParts(cosContext, client, input, body, journal, journalLocation, config.Concurrency,
+		tracker); err != nil {
+		return
+	}
+
+	// Complete the upload
+	var completed *s3.CompleteMultipartUploadOutput
+	if completed, err = client.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          input.Bucket,
+		Key:             input.Key,
+		UploadId:        aws.String(journal.UploadId),
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: journal.completedParts()},
+	}); err != nil {
+		return
+	}
+
+	// The upload is over, the journal is not needed anymore
+	cosContext.Remove(journalLocation)
+
+	output = &s3manager.UploadOutput{
+		Location:  aws.StringValue(completed.Location),
+		VersionID: completed.VersionId,
+		UploadID:  journal.UploadId,
+	}
+	return
+}
+
+// resumablePartSize grows the part size when needed to fit the file in the maximum number of parts
+func resumablePartSize(partSize, fileSize int64, maxUploadParts int) int64 {
+	if partSize < s3manager.MinUploadPartSize {
+		partSize = s3manager.MinUploadPartSize
+	}
+	maxParts := int64(maxUploadParts)
+	if maxParts <= 0 || maxParts > s3manager.MaxUploadParts {
+		maxParts = s3manager.MaxUploadParts
+	}
+	if minimum := (fileSize + maxParts - 1) / maxParts; partSize < minimum {
+		partSize = minimum
+	}
+	return partSize
+}
+
+// partsCount returns the number of parts of the upload, an empty file still needs one part
+func (journal *uploadJournal) partsCount() int64 {
+	if journal.FileSize == 0 {
+		return 1
+	}
+	return (journal.FileSize + journal.PartSize - 1) / journal.PartSize
+}
+
+// partLength returns the length of the part with the given number
+func (journal *uploadJournal) partLength(partNumber int64) int64 {
+	offset := (partNumber - 1) * journal.PartSize
+	if remaining := journal.FileSize - offset; remaining < journal.PartSize {
+		return remaining
+	}
+	return journal.PartSize
+}
+
+// completedParts returns the parts sorted by part number as expected by CompleteMultipartUpload
+func (journal *uploadJournal) completedParts() []*s3.CompletedPart {
+	parts := make([]*s3.CompletedPart, 0, len(journal.Parts))
+	for partNumber, etag := range journal.Parts {
+		parts = append(parts, &s3.CompletedPart{
+			PartNumber: aws.Int64(partNumber),
+			ETag:       aws.String(etag),
+		})
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return aws.Int64Value(parts[i].PartNumber) < aws.Int64Value(parts[j].PartNumber)
+	})
+	return parts
+}
+
+// refreshUploadJournal replaces the parts of the journal with the complete parts listed by the service
+func refreshUploadJournal(client s3iface.S3API, journal *uploadJournal) error {
+	parts := make(map[int64]string)
+	err := client.ListPartsPages(&s3.ListPartsInput{
+		Bucket:   aws.String(journal.Bucket),
+		Key:      aws.String(journal.Key),
+		UploadId: aws.String(journal.UploadId),
+	}, func(page *s3.ListPartsOutput, _ bool) bool {
+		for _, part := range page.Parts {
+			partNumber := aws.Int64Value(part.PartNumber)
+			if partNumber <= journal.partsCount() && aws.Int64Value(part.Size) == journal.partLength(partNumber) {
+				parts[partNumber] = aws.StringValue(part.ETag)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	journal.Parts = parts
+	return nil
+}
+
+// uploadMissingParts uploads the parts not yet recorded in the journal with concurrency workers,
+// saving the journal after every part. The parts are encrypted with the customer-provided key of the input.
+func uploadMissingParts(cosContext *utils.CosContext, client s3iface.S3API, input *s3manager.UploadInput,
+	body io.ReadSeeker, journal *uploadJournal, journalLocation string, concurrency int,
+	tracker *transferProgress) error {
+	if concurrency < 1 {
+		concurrency = s3manager.DefaultUploadConcurrency
+	}
+
+	missing := make([]int64, 0)
+	for partNumber := int64(1); partNumber <= journal.partsCount(); partNumber++ {
+		if _, done := journal.Parts[partNumber]; !done {
+			missing = append(missing, partNumber)
+		}
+	}
+	partNumbers := make(chan int64)
+	go func() {
+		defer close(partNumbers)
+		for _, partNumber := range missing {
+			partNumbers <- partNumber
+		}
+	}()
+
+	var lock sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for partNumber := range partNumbers {
+				lock.Lock()
+				failed := firstErr != nil
+				var buffer []byte
+				var err error
+				if !failed {
+					buffer = make([]byte, journal.partLength(partNumber))
+					if _, err = body.Seek((partNumber-1)*journal.PartSize, io.SeekStart); err == nil {
+						_, err = io.ReadFull(body, buffer)
+					}
+					if err != nil {
+						firstErr = err
+					}
+				}
+				lock.Unlock()
+				if failed || err != nil {
+					continue
+				}
+
+				output, err := client.UploadPart(&s3.UploadPartInput{
+					Bucket:     aws.String(journal.Bucket),
+					Key:        aws.String(journal.Key),
+					UploadId:   aws.String(journal.UploadId),
+					PartNumber: aws.Int64(partNumber),
+					Body: &partBody{
+						reader:  bytes.NewReader(buffer),
+						tracker: tracker,
+						offset:  (partNumber - 1) * journal.PartSize,
+					},
+					// every part is encrypted with the key the upload was created with
+					SSECustomerAlgorithm: input.SSECustomerAlgorithm,
+					SSECustomerKey:       input.SSECustomerKey,
+				})
+
+				lock.Lock()
+				if err == nil {
+					journal.Parts[partNumber] = aws.StringValue(output.ETag)
+					err = writeUploadJournal(cosContext, journalLocation, journal)
+				}
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// partBody records the bytes of a part as they are sent, at their offset in the file.
+// The bytes read again to sign or to retry the request are counted once by the tracker.
+type partBody struct {
+	reader  *bytes.Reader
+	tracker *transferProgress
+	offset  int64
+}
+
+func (p *partBody) Read(b []byte) (n int, err error) {
+	position := p.reader.Size() - int64(p.reader.Len())
+	n, err = p.reader.Read(b)
+	p.tracker.record(p.offset+position, int64(n))
+	return
+}
+
+func (p *partBody) Seek(offset int64, whence int) (int64, error) {
+	return p.reader.Seek(offset, whence)
+}
@@ -3,11 +3,21 @@
 package functions_test
 
 import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
 
 	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
 
@@ -125,3 +135,728 @@ func TestUploadSunnyPath(t *testing.T) {
 	// assert Not Fail
 	assert.NotContains(t, errors, "FAIL")
 }
+
+func TestUploadRecursiveReportsPerFileResults(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetPrefix := "site/"
+	targetDir := "public"
+	targetContentType := "TargetContentType"
+	targetPartSize := int64(9999)
+	targetConcurrency := 9
+
+	referenceUploader := new(s3manager.Uploader)
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	mockLocalTree(targetDir, map[string]int64{
+		"index.html":     10,
+		"css/style.css":  20,
+		"img/broken.png": 30,
+		"img/logo.png":   40,
+	}, time.Now())
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", filepath.Join(targetDir, "img", "broken.png")).
+		Return(nil, os.ErrPermission).
+		Once()
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", mock.Anything).
+		Return(utils.WrapString("content", nil), nil)
+
+	uploadedKeys := make([]string, 0)
+	providers.MockUploaderAPI.
+		On("UploadWithIterator", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			iterator := args.Get(1).(s3manager.BatchUploadIterator)
+			for iterator.Next() {
+				object := iterator.UploadObject()
+				assert.Equal(t, targetBucket, aws.StringValue(object.Object.Bucket))
+				assert.Equal(t, targetContentType, aws.StringValue(object.Object.ContentType))
+				uploadedKeys = append(uploadedKeys, aws.StringValue(object.Object.Key))
+				object.After()
+			}
+		}).
+		Return(s3manager.NewBatchError("BatchedUploadIncomplete", "some objects have failed to upload.",
+			[]s3manager.Error{{OrigErr: os.ErrDeadlineExceeded, Bucket: aws.String(targetBucket),
+				Key: aws.String(targetPrefix + "img/logo.png")}})).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Upload, "--bucket", targetBucket,
+		"--" + flags.File, targetDir,
+		"--" + flags.Recursive,
+		"--" + flags.Prefix, targetPrefix,
+		"--" + flags.ContentType, targetContentType,
+		"--" + flags.PartSize, strconv.FormatInt(targetPartSize, 10),
+		"--" + flags.Concurrency, strconv.Itoa(targetConcurrency),
+		"--" + flags.Jobs, "1",
+		"--" + flags.Region, "REG",
+	}
+
+	// call  plugin
+	providers.ReferenceUploader = referenceUploader
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, []string{targetPrefix + "css/style.css", targetPrefix + "img/logo.png",
+		targetPrefix + "index.html"}, uploadedKeys)
+	assert.Equal(t, targetPartSize, referenceUploader.PartSize)
+	assert.Equal(t, targetConcurrency, referenceUploader.Concurrency)
+
+	// assert exit code is non-zero since two files failed
+	assert.Equal(t, 1, *exitCode)
+	// capture all output //
+	output := providers.FakeUI.Outputs()
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, output, "Uploaded 2 of 4 file(s) to bucket '"+targetBucket+"' (30 B).")
+	assert.Contains(t, output, targetPrefix+"img/broken.png")
+	assert.Contains(t, output, os.ErrPermission.Error())
+	assert.Contains(t, output, os.ErrDeadlineExceeded.Error())
+	assert.Contains(t, errors, "FAIL")
+}
+
+func TestUploadRecursiveUploadsFilesInParallel(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetDir := "public"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	mockLocalTree(targetDir, map[string]int64{
+		"a.txt": 10,
+		"b.txt": 10,
+		"c.txt": 10,
+		"d.txt": 10,
+		"e.txt": 10,
+	}, time.Now())
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", mock.Anything).
+		Return(func(string) utils.ReadSeekerCloser { return utils.WrapString("content", nil) }, nil)
+
+	// every iterator holds its own share of the files
+	var lock sync.Mutex
+	shards := make([][]string, 0)
+	providers.MockUploaderAPI.
+		On("UploadWithIterator", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			iterator := args.Get(1).(s3manager.BatchUploadIterator)
+			keys := make([]string, 0)
+			for iterator.Next() {
+				object := iterator.UploadObject()
+				keys = append(keys, aws.StringValue(object.Object.Key))
+				object.After()
+			}
+			lock.Lock()
+			shards = append(shards, keys)
+			lock.Unlock()
+		}).
+		Return(nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Upload, "--bucket", targetBucket,
+		"--" + flags.File, targetDir,
+		"--" + flags.Recursive,
+		"--" + flags.Jobs, "2",
+		"--" + flags.Region, "REG",
+	}
+
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode)
+	providers.MockUploaderAPI.AssertNumberOfCalls(t, "UploadWithIterator", 2)
+	uploaded := make([]string, 0)
+	for _, keys := range shards {
+		assert.NotEmpty(t, keys)
+		uploaded = append(uploaded, keys...)
+	}
+	assert.ElementsMatch(t, []string{"a.txt", "b.txt", "c.txt", "d.txt", "e.txt"}, uploaded)
+	assert.Contains(t, providers.FakeUI.Outputs(), "Uploaded 5 of 5 file(s)")
+}
+
+func TestUploadResumeHonorsMaxUploadParts(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetFile := "TargetFile"
+	fileSize := 3 * s3manager.MinUploadPartSize
+	journalFile := targetFile + ".cos-upload"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", targetFile).
+		Return(utils.WrapString(strings.Repeat("a", int(fileSize)), nil), nil).
+		Once()
+	providers.MockFileOperations.
+		On("GetFileInfo", targetFile).
+		Return(utils.FakeFileInfo(targetFile, fileSize, time.Now(), false), nil).
+		Once()
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", journalFile).
+		Return(nil, os.ErrNotExist).
+		Once()
+	var savedJournal string
+	providers.MockFileOperations.
+		On("WriteCloserOpen", journalFile).
+		Return(func(string) utils.WriteCloser { return utils.WriteToString(&savedJournal, nil) }, nil)
+	providers.MockFileOperations.On("Remove", journalFile).Return(nil).Once()
+
+	providers.MockS3API.
+		On("CreateMultipartUpload", mock.Anything).
+		Return(&s3.CreateMultipartUploadOutput{UploadId: aws.String("TargetUploadID")}, nil).
+		Once()
+
+	var lock sync.Mutex
+	partLengths := make(map[int64]int)
+	providers.MockS3API.
+		On("UploadPart", mock.MatchedBy(func(input *s3.UploadPartInput) bool {
+			// the body is read a second time as when the request is retried
+			ioutil.ReadAll(input.Body)
+			input.Body.Seek(0, io.SeekStart)
+			content, _ := ioutil.ReadAll(input.Body)
+			lock.Lock()
+			defer lock.Unlock()
+			partLengths[aws.Int64Value(input.PartNumber)] = len(content)
+			return true
+		})).
+		Return(&s3.UploadPartOutput{ETag: aws.String(`"etag"`)}, nil)
+
+	providers.MockS3API.
+		On("CompleteMultipartUpload", mock.Anything).
+		Return(new(s3.CompleteMultipartUploadOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Upload, "--bucket", "TargetBucket",
+		"--" + flags.Key, "TargetKey",
+		"--" + flags.File, targetFile,
+		"--" + flags.Resume,
+		"--" + flags.MaxUploadParts, "2",
+		"--" + flags.Region, "REG",
+		"--" + flags.Output, "json",
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// the parts grow to fit the file in two parts
+	assert.Equal(t, map[int64]int{1: int(fileSize / 2), 2: int(fileSize / 2)}, partLengths)
+	assert.Contains(t, savedJournal, `"PartSize":`+strconv.FormatInt(fileSize/2, 10))
+	// the parts read again are counted once
+	assert.Contains(t, providers.FakeUI.Errors(),
+		`{"Event":"complete","Key":"TargetKey","Bytes":`+strconv.FormatInt(fileSize, 10)+`,`)
+}
+
+func TestUploadRecursiveSharesMaxBandwidth(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetDir := "public"
+	// together one and a half second of transfer at 1KB/s, the first second is available at once
+	fileContent := string(make([]byte, 768))
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	mockLocalTree(targetDir, map[string]int64{
+		"a.bin": 768,
+		"b.bin": 768,
+	}, time.Now())
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", mock.Anything).
+		Return(func(string) utils.ReadSeekerCloser { return utils.WrapString(fileContent, nil) }, nil)
+
+	start := time.Now()
+	providers.MockUploaderAPI.
+		On("UploadWithIterator", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			iterator := args.Get(1).(s3manager.BatchUploadIterator)
+			for iterator.Next() {
+				object := iterator.UploadObject()
+				ioutil.ReadAll(object.Object.Body)
+				object.After()
+			}
+		}).
+		Return(nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Upload, "--bucket", "TargetBucket",
+		"--" + flags.File, targetDir,
+		"--" + flags.Recursive,
+		"--" + flags.Jobs, "2",
+		"--" + flags.MaxBandwidth, "1KB/s",
+		"--" + flags.Region, "REG",
+	}
+
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	assert.Equal(t, (*int)(nil), exitCode)
+	providers.MockUploaderAPI.AssertNumberOfCalls(t, "UploadWithIterator", 2)
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 400*time.Millisecond, "files read in %v", elapsed)
+}
+
+func TestUploadResumeSkipsCompletedParts(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetKey := "TargetKey"
+	targetFile := "TargetFile"
+	targetUploadID := "TargetUploadID"
+	partSize := s3manager.MinUploadPartSize
+	fileSize := partSize + 10
+	modTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	journalFile := targetFile + ".cos-upload"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", targetFile).
+		Return(utils.WrapString(strings.Repeat("a", int(partSize))+strings.Repeat("b", 10), nil), nil).
+		Once()
+	providers.MockFileOperations.
+		On("GetFileInfo", targetFile).
+		Return(utils.FakeFileInfo(targetFile, fileSize, modTime, false), nil).
+		Once()
+	journal := `{"Bucket":"` + targetBucket + `","Key":"` + targetKey + `","UploadId":"` + targetUploadID +
+		`","PartSize":` + strconv.FormatInt(partSize, 10) + `,"FileSize":` + strconv.FormatInt(fileSize, 10) +
+		`,"ModTime":"2021-01-01T00:00:00Z","Parts":{"1":"\"e1\""}}`
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", journalFile).
+		Return(utils.WrapString(journal, nil), nil).
+		Once()
+	var savedJournal string
+	providers.MockFileOperations.
+		On("WriteCloserOpen", journalFile).
+		Return(func(string) utils.WriteCloser { return utils.WriteToString(&savedJournal, nil) }, nil)
+	providers.MockFileOperations.On("Remove", journalFile).Return(nil).Once()
+
+	providers.MockS3API.
+		On("ListPartsPages", mock.MatchedBy(func(input *s3.ListPartsInput) bool {
+			return aws.StringValue(input.UploadId) == targetUploadID
+		}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			pager := args.Get(1).(func(page *s3.ListPartsOutput, last bool) bool)
+			pager(&s3.ListPartsOutput{Parts: []*s3.Part{
+				{PartNumber: aws.Int64(1), ETag: aws.String(`"e1"`), Size: aws.Int64(partSize)},
+			}}, true)
+		}).
+		Return(nil).
+		Once()
+
+	var partCapture *s3.UploadPartInput
+	providers.MockS3API.
+		On("UploadPart", mock.MatchedBy(func(input *s3.UploadPartInput) bool {
+			partCapture = input
+			return true
+		})).
+		Return(&s3.UploadPartOutput{ETag: aws.String(`"e2"`)}, nil).
+		Once()
+
+	var completeCapture *s3.CompleteMultipartUploadInput
+	providers.MockS3API.
+		On("CompleteMultipartUpload", mock.MatchedBy(func(input *s3.CompleteMultipartUploadInput) bool {
+			completeCapture = input
+			return true
+		})).
+		Return(new(s3.CompleteMultipartUploadOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Upload, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.File, targetFile,
+		"--" + flags.Resume,
+		"--" + flags.Region, "REG",
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	providers.MockS3API.AssertNotCalled(t, "CreateMultipartUpload", mock.Anything)
+	providers.MockUploaderAPI.AssertNotCalled(t, "Upload", mock.Anything)
+
+	assert.Equal(t, int64(2), aws.Int64Value(partCapture.PartNumber))
+	assert.Equal(t, targetUploadID, aws.StringValue(partCapture.UploadId))
+	remaining, _ := ioutil.ReadAll(partCapture.Body)
+	assert.Equal(t, strings.Repeat("b", 10), string(remaining))
+	assert.Contains(t, savedJournal, `"2":"\"e2\""`)
+
+	assert.Equal(t, targetUploadID, aws.StringValue(completeCapture.UploadId))
+	assert.Equal(t, []*s3.CompletedPart{
+		{PartNumber: aws.Int64(1), ETag: aws.String(`"e1"`)},
+		{PartNumber: aws.Int64(2), ETag: aws.String(`"e2"`)},
+	}, completeCapture.MultipartUpload.Parts)
+	providers.MockFileOperations.AssertCalled(t, "Remove", journalFile)
+
+	// capture all output //
+	output := providers.FakeUI.Outputs()
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, output, "OK")
+	assert.NotContains(t, errors, "FAIL")
+}
+
+func TestUploadResumeStartsJournal(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetKey := "TargetKey"
+	targetFile := "TargetFile"
+	targetUploadID := "TargetUploadID"
+	journalFile := targetFile + ".cos-upload"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", targetFile).
+		Return(utils.WrapString("content", nil), nil).
+		Once()
+	providers.MockFileOperations.
+		On("GetFileInfo", targetFile).
+		Return(utils.FakeFileInfo(targetFile, 7, time.Now(), false), nil).
+		Once()
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", journalFile).
+		Return(nil, os.ErrNotExist).
+		Once()
+	journalWrites := 0
+	providers.MockFileOperations.
+		On("WriteCloserOpen", journalFile).
+		Return(func(string) utils.WriteCloser {
+			journalWrites++
+			return utils.WriteToString(nil, nil)
+		}, nil)
+	providers.MockFileOperations.On("Remove", journalFile).Return(nil).Once()
+
+	providers.MockS3API.
+		On("CreateMultipartUpload", mock.MatchedBy(func(input *s3.CreateMultipartUploadInput) bool {
+			return aws.StringValue(input.Key) == targetKey
+		})).
+		Return(&s3.CreateMultipartUploadOutput{UploadId: aws.String(targetUploadID)}, nil).
+		Once()
+	providers.MockS3API.
+		On("UploadPart", mock.MatchedBy(func(input *s3.UploadPartInput) bool {
+			return aws.Int64Value(input.PartNumber) == 1
+		})).
+		Return(&s3.UploadPartOutput{ETag: aws.String(`"e1"`)}, nil).
+		Once()
+	providers.MockS3API.
+		On("CompleteMultipartUpload", mock.Anything).
+		Return(new(s3.CompleteMultipartUploadOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Upload, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.File, targetFile,
+		"--" + flags.Resume,
+		"--" + flags.Region, "REG",
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	providers.MockS3API.AssertNumberOfCalls(t, "UploadPart", 1)
+	// journal written once created and once per part
+	assert.Equal(t, 2, journalWrites)
+
+	// capture all output //
+	output := providers.FakeUI.Outputs()
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, output, "OK")
+	assert.NotContains(t, errors, "FAIL")
+}
+
+func TestUploadStreamsStandardInput(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetKey := "TargetKey"
+	stdinContent := "streamed from a pipe"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("Stdin").
+		Return(io.MultiReader(strings.NewReader(stdinContent)))
+
+	var bodyContent []byte
+	var seekable bool
+	providers.MockUploaderAPI.
+		On("Upload", mock.MatchedBy(func(input *s3manager.UploadInput) bool {
+			_, seekable = input.Body.(io.Seeker)
+			bodyContent, _ = ioutil.ReadAll(input.Body)
+			return true
+		})).
+		Return(new(s3manager.UploadOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Upload, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.File, "-",
+		"--" + flags.Region, "REG",
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// the standard input is streamed, not read in advance
+	assert.False(t, seekable)
+	assert.Equal(t, stdinContent, string(bodyContent))
+	providers.MockFileOperations.AssertNotCalled(t, "ReadSeekerCloserOpen", mock.Anything)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "OK")
+}
+
+func TestUploadStandardInputCannotResume(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Upload, "--bucket", "TargetBucket",
+		"--" + flags.Key, "TargetKey",
+		"--" + flags.File, "-",
+		"--" + flags.Resume,
+		"--" + flags.Region, "REG",
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is non-zero
+	assert.Equal(t, 1, *exitCode)
+	providers.MockUploaderAPI.AssertNotCalled(t, "Upload", mock.Anything)
+	errors := providers.FakeUI.Errors()
+	assert.Contains(t, errors, "FAIL")
+}
+
+func TestUploadContentTypeFromConfig(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+	// the mapping of the config replaces the empty default
+	providers.MockPluginConfig.On("GetStringWithDefault", config.ContentTypes, "").Unset()
+	providers.MockPluginConfig.
+		On("GetStringWithDefault", config.ContentTypes, "").
+		Return("md=text/markdown, .AVRO=application/avro", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "docs/README.md").
+		Return(utils.WrapString("# Title", nil), nil)
+
+	var contentType string
+	providers.MockUploaderAPI.
+		On("Upload", mock.MatchedBy(func(input *s3manager.UploadInput) bool {
+			contentType = aws.StringValue(input.ContentType)
+			return true
+		})).
+		Return(new(s3manager.UploadOutput), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Upload, "--bucket", "TargetBucket",
+		"--" + flags.Key, "README.md",
+		"--" + flags.File, "docs/README.md",
+		"--" + flags.Region, "REG",
+	}
+	// call  plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	assert.Equal(t, "text/markdown", contentType)
+}
+
+func TestUploadIfChangedSkipsSameMultipartObject(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetKey := "TargetKey"
+	partSize := s3manager.MinUploadPartSize
+	content := strings.Repeat("a", int(partSize)) + strings.Repeat("b", int(partSize)) + "c"
+
+	// the ETag of the object uploaded in three parts of the same size
+	composite := md5.New()
+	for _, part := range []string{content[:partSize], content[partSize : 2*partSize], content[2*partSize:]} {
+		sum := md5.Sum([]byte(part))
+		composite.Write(sum[:])
+	}
+	etag := fmt.Sprintf(`"%s-3"`, hex.EncodeToString(composite.Sum(nil)))
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockFileOperations.
+		On("ReadSeekerCloserOpen", "data.bin").
+		Return(utils.WrapString(content, nil), nil).
+		Once()
+
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			return aws.StringValue(input.Key) == targetKey
+		})).
+		Return(new(s3.HeadObjectOutput).
+			SetContentLength(int64(len(content))).
+			SetETag(etag), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Upload, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.File, "data.bin",
+		"--" + flags.PartSize, strconv.FormatInt(partSize, 10),
+		"--" + flags.IfChanged,
+		"--" + flags.Region, "REG",
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// nothing is sent again
+	providers.MockUploaderAPI.AssertNotCalled(t, "Upload", mock.Anything)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "Uploaded 0 of 1 file(s)")
+	assert.Contains(t, output, "Skipped 1 file(s) already in bucket")
+}
+
+func TestUploadArchiveSkipExistingSkipsExistingKey(t *testing.T) {
+	defer providers.MocksRESET()
+
+	// --- Arrange ---
+	// disable and capture OS EXIT
+	var exitCode *int
+	cli.OsExiter = func(ec int) {
+		exitCode = &ec
+	}
+
+	targetBucket := "TargetBucket"
+	targetKey := "backup.tar.gz"
+
+	providers.MockPluginConfig.On("GetString", config.ServiceEndpointURL).Return("", nil)
+
+	providers.MockS3API.
+		On("HeadObject", mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+			return aws.StringValue(input.Key) == targetKey
+		})).
+		Return(new(s3.HeadObjectOutput).SetContentLength(10), nil).
+		Once()
+
+	// --- Act ----
+	// set os args
+	os.Args = []string{"-", commands.Upload, "--bucket", targetBucket,
+		"--" + flags.Key, targetKey,
+		"--" + flags.File, "public",
+		"--" + flags.Archive, "tar.gz",
+		"--" + flags.SkipExisting,
+		"--" + flags.Region, "REG",
+	}
+	//call plugin
+	plugin.Start(new(cos.Plugin))
+
+	// --- Assert ----
+	// assert exit code is zero
+	assert.Equal(t, (*int)(nil), exitCode) // no exit trigger in the cli
+	// the directory is neither archived nor sent
+	providers.MockFileOperations.AssertNotCalled(t, "Walk", mock.Anything, mock.Anything)
+	providers.MockUploaderAPI.AssertNotCalled(t, "Upload", mock.Anything)
+	output := providers.FakeUI.Outputs()
+	assert.Contains(t, output, "Skipped 1 file(s) already in bucket")
+}
@@ -1,6 +1,7 @@
 package functions
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"io/ioutil"
@@ -77,7 +78,8 @@ func MapToSDKInput(cliContext *cli.Context, destination interface{},
 	// Check if the desired output format is valid (JSON or TEXT)
 	if cliContext.IsSet(flags.Output) {
 		format := cliContext.String(flags.Output)
-		if !strings.EqualFold(format, "json") && !strings.EqualFold(format, "text") {
+		if !strings.EqualFold(format, "json") && !strings.EqualFold(format, "text") &&
+			!(strings.EqualFold(format, outputNDJSON) && streamsOutput(cliContext)) {
 			err := awserr.New("Incorrect Usage", "Invalid output format. Use json or text with --output option.", nil)
 			return err
 		}
@@ -119,9 +121,30 @@ func MapToSDKInput(cliContext *cli.Context, destination interface{},
 		}
 	}
 
+	// a customer-provided key is sent with its algorithm
+	defaultCustomerAlgorithms(destinationRflx)
+
 	return nil
 }
 
+// stdStream is the file name standing for the standard input or the standard output
+const stdStream = "-"
+
+// stdinBody returns the standard input as the body of a request,
+// streamed when the body does not need to be seekable, the Uploader then uploads parts
+// as they are read, otherwise read into memory
+func stdinBody(cosContext *utils.CosContext, bodyType reflect.Type) (io.Reader, error) {
+	stdin := cosContext.Stdin()
+	if !bodyType.Implements(reflect.TypeOf((*io.Seeker)(nil)).Elem()) {
+		return stdin, nil
+	}
+	content, err := ioutil.ReadAll(stdin)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(content), nil
+}
+
 // populate field , grabs the value from the cli context and maps it to the S3 input
 func populateField(cliContext *cli.Context,
 	flagName string,
@@ -158,6 +181,17 @@ func populateField(cliContext *cli.Context,
 				// if an error occurred , propagate it and stop any further processing
 				return
 			}
+			// the standard input is used as body when the flag value is -
+			if cliContext.String(flagName) == stdStream {
+				var body io.Reader
+				if body, err = stdinBody(cosContext, fieldRflx.Type()); err != nil {
+					commandError.IError = err
+					err = commandError
+					return
+				}
+				fieldRflx.Set(reflect.ValueOf(body))
+				return
+			}
 			// allocate a io.ReadSeeker
 			var readSeeker io.ReadSeeker
 			// call the cos context wrapper of the os open operation
@@ -209,6 +243,11 @@ func populateField(cliContext *cli.Context,
 	// each type as its mapper
 	switch f := v.Interface().(type) {
 	case *string:
+		if isCustomerKeyField(fieldName) {
+			// if field is a customer-provided key, it may be read from a file
+			*f, err = parseCustomerKey(cliContext, cliContext.String(flagName))
+			break
+		}
 		// if field is type pointer to string, uses directly the value of the flag
 		*f = cliContext.String(flagName)
 	case *bool:
@@ -7,6 +7,7 @@ require (
 	github.com/IBM/ibm-cos-sdk-go v1.14.0
 	github.com/cloudfoundry/jibber_jabber v0.0.0-20151120183258-bcc4c8345a21
 	github.com/google/wire v0.7.0
+	github.com/klauspost/compress v1.18.0
 	github.com/nicksnyder/go-i18n v1.10.3
 	github.com/prataprc/goparsec v0.0.0-20211219142520-daac0e635e7e
 	github.com/stretchr/testify v1.11.1
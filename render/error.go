@@ -157,6 +157,10 @@ func getMessageByCodeError(errorIn errors.CodeError) string {
 		return T("The replication configuration was not found")
 	case "LifecycleConfigurationNotFoundError":
 		return T("The lifecycle configuration was not found")
+	case "TransferIncomplete":
+		return T("One or more objects could not be transferred. Review the failures listed above and try again.")
+	case "BucketEmptyIncomplete":
+		return T("One or more object versions or multipart uploads could not be removed, the bucket is not deleted. Review the failures listed above, object lock retention or legal hold may protect them.")
 	default:
 		return errorIn.Error()
 	}
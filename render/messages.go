@@ -11,6 +11,15 @@ func WarningDeleteBucket(input *s3.DeleteBucketInput) string {
 	return T("WARNING: This will permanently delete the bucket '{{.Bucket}}' from your account.", input)
 }
 
+// WarningEmptyBucket - bucket emptying message
+func WarningEmptyBucket(bucket string) string {
+	return T("WARNING: The bucket '{{.Bucket}}' is not empty. This will permanently delete every object, version and delete marker and abort every incomplete multipart upload in it, then delete the bucket.",
+		map[string]interface{}{"Bucket": bucket})
+}
+
+// MessageConfirmBucketName - bucket emptying confirmation message
+func MessageConfirmBucketName() string { return T("Type the name of the bucket to confirm:") }
+
 // WarningDeleteBucketWebsite - bucket website delete message
 func WarningDeleteBucketWebsite(input *s3.DeleteBucketWebsiteInput) string {
 	return T("WARNING: This will permanently delete any bucket website configuration from the bucket '{{.Bucket}}'.", input)
@@ -21,12 +30,36 @@ func WarningDeleteObject(input *s3.DeleteObjectInput) string {
 	return T("WARNING: This will permanently delete the object '{{.Key}}' from the bucket '{{.Bucket}}'.", input)
 }
 
+// WarningDeletePrefix - recursive prefix delete message
+func WarningDeletePrefix(bucket, prefix string) string {
+	return T("WARNING: This will permanently delete every object under the prefix '{{.Prefix}}' from the bucket '{{.Bucket}}'.",
+		map[string]interface{}{"Prefix": prefix, "Bucket": bucket})
+}
+
+// WarningDeleteMatching - wildcard key delete message
+func WarningDeleteMatching(bucket, pattern string, count int) string {
+	return T("WARNING: This will permanently delete {{.Count}} object(s) matching '{{.Pattern}}' from the bucket '{{.Bucket}}'.",
+		map[string]interface{}{"Count": count, "Pattern": pattern, "Bucket": bucket})
+}
+
+// WarningAbortUploads - stale multipart uploads cleanup message
+func WarningAbortUploads(bucket string, count int, age string) string {
+	return T("WARNING: This will abort {{.Count}} multipart upload(s) initiated more than {{.Age}} ago in the bucket '{{.Bucket}}' and delete their parts.",
+		map[string]interface{}{"Count": count, "Age": age, "Bucket": bucket})
+}
+
 // WarningGetObject - object get message
 func WarningGetObject(file, location string) string {
 	return T("WARNING: An object with the name '{{.file}}' already exists at '{{.dl}}'.",
 		map[string]interface{}{"file": file, "dl": location})
 }
 
+// WarningOverwriteFiles - recursive download overwrite message
+func WarningOverwriteFiles(count int, location string) string {
+	return T("WARNING: {{.count}} file(s) to download already exist under '{{.dl}}' and will be overwritten.",
+		map[string]interface{}{"count": count, "dl": location})
+}
+
 // MessageConfirmationContinue Confirmation message
 func MessageConfirmationContinue() string { return T("Are you sure you would like to continue?") }
 
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"encoding/json"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/bluemix/terminal"
+	"github.com/IBM/ibm-cos-sdk-go/service/s3"
+)
+
+// NDJSONRender streams the entries of a listing as newline delimited JSON,
+// every entry is written on its own line as soon as its page is received
+type NDJSONRender struct {
+	encoder *json.Encoder
+	resume  *ListingResume
+	err     error
+}
+
+// NewNDJSONRender returns a render writing to the terminal
+func NewNDJSONRender(terminal terminal.UI) *NDJSONRender {
+	tmp := new(NDJSONRender)
+	tmp.encoder = json.NewEncoder(terminal.Writer())
+	tmp.encoder.SetEscapeHTML(false)
+	return tmp
+}
+
+// ListingResume is the last line of a truncated listing,
+// the markers to give to the command to resume the listing from
+type ListingResume struct {
+	NextContinuationToken *string `json:",omitempty"`
+	NextMarker            *string `json:",omitempty"`
+	NextKeyMarker         *string `json:",omitempty"`
+	NextVersionIdMarker   *string `json:",omitempty"`
+	NextUploadIdMarker    *string `json:",omitempty"`
+	NextPartNumberMarker  *int64  `json:",omitempty"`
+}
+
+// DeleteMarkerEntry tells a delete marker apart from the versions of a listing
+type DeleteMarkerEntry struct {
+	*s3.DeleteMarkerEntry
+	DeleteMarker bool
+}
+
+// Entry writes an entry of the listing, nothing is written anymore once a write failed
+func (ndr *NDJSONRender) Entry(entry interface{}) bool {
+	if ndr.err == nil {
+		ndr.err = ndr.encoder.Encode(entry)
+	}
+	return ndr.err == nil
+}
+
+// Resume records the markers to resume the listing from after the last page received,
+// nil when the page is the last one of the listing
+func (ndr *NDJSONRender) Resume(resume *ListingResume) {
+	ndr.resume = resume
+}
+
+// Finish writes the markers to resume a truncated listing from, when recorded,
+// and returns the error of the writes
+func (ndr *NDJSONRender) Finish() error {
+	if ndr.resume != nil {
+		ndr.Entry(ndr.resume)
+	}
+	return ndr.err
+}
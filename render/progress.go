@@ -0,0 +1,160 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/IBM/ibmcloud-cos-cli/i18n"
+	"gopkg.in/cheggaaa/pb.v1"
+)
+
+// Progress events emitted as JSON lines
+const (
+	ProgressStart    = "start"
+	ProgressUpdate   = "progress"
+	ProgressComplete = "complete"
+)
+
+// progressEventInterval is the minimum time between two progress events
+const progressEventInterval = time.Second
+
+// Progress reports the advance of a single transfer
+type Progress interface {
+	// SetTotal sets the size of the transfer and its number of parts once they are known
+	SetTotal(totalBytes, totalParts int64)
+	// Update reports the bytes transferred and the parts completed so far
+	Update(bytes, parts int64)
+	// Finish stops the report
+	Finish()
+}
+
+// ProgressDisplay is implemented by the displays able to report the progress of transfers
+type ProgressDisplay interface {
+	NewProgress(key string, totalBytes, totalParts int64) Progress
+}
+
+// ProgressEvent is the progress of a transfer, emitted as a JSON line
+type ProgressEvent struct {
+	Event          string
+	Key            string
+	Bytes          int64
+	TotalBytes     int64
+	Parts          int64
+	TotalParts     int64
+	BytesPerSecond int64
+	ETASeconds     int64
+}
+
+// NewProgress starts a progress bar with the throughput and the remaining time of the transfer
+func (txtRender *TextRender) NewProgress(key string, totalBytes, totalParts int64) Progress {
+	bar := pb.New64(totalBytes).SetUnits(pb.U_BYTES)
+	bar.ShowSpeed = true
+	bar.ShowTimeLeft = true
+	progress := &barProgress{bar: bar}
+	progress.SetTotal(totalBytes, totalParts)
+	bar.Start()
+	return progress
+}
+
+// barProgress reports the progress of a transfer with a progress bar
+type barProgress struct {
+	bar        *pb.ProgressBar
+	lock       sync.Mutex
+	totalParts int64
+	finished   bool
+}
+
+func (progress *barProgress) SetTotal(totalBytes, totalParts int64) {
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+	progress.bar.SetTotal64(totalBytes)
+	progress.totalParts = totalParts
+}
+
+func (progress *barProgress) Update(bytes, parts int64) {
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+	progress.bar.Set64(bytes)
+	if progress.totalParts > 1 {
+		progress.bar.Postfix(fmt.Sprintf(" "+T("parts")+" %d/%d", parts, progress.totalParts))
+	}
+}
+
+func (progress *barProgress) Finish() {
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+	if !progress.finished {
+		progress.finished = true
+		progress.bar.Finish()
+	}
+}
+
+// NewProgress starts emitting progress events, one JSON document per line
+// on the error output so the result of the command remains a single JSON document
+func (jsr *JSONRender) NewProgress(key string, totalBytes, totalParts int64) Progress {
+	progress := &eventProgress{
+		render:  jsr,
+		started: time.Now(),
+		event: ProgressEvent{
+			Key:        key,
+			TotalBytes: totalBytes,
+			TotalParts: totalParts,
+		},
+	}
+	progress.emit(ProgressStart)
+	return progress
+}
+
+// eventProgress reports the progress of a transfer with JSON events
+type eventProgress struct {
+	render   *JSONRender
+	lock     sync.Mutex
+	started  time.Time
+	emitted  time.Time
+	event    ProgressEvent
+	finished bool
+}
+
+func (progress *eventProgress) SetTotal(totalBytes, totalParts int64) {
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+	progress.event.TotalBytes = totalBytes
+	progress.event.TotalParts = totalParts
+}
+
+func (progress *eventProgress) Update(bytes, parts int64) {
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+	progress.event.Bytes = bytes
+	progress.event.Parts = parts
+	if time.Since(progress.emitted) >= progressEventInterval {
+		progress.emit(ProgressUpdate)
+	}
+}
+
+func (progress *eventProgress) Finish() {
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+	if !progress.finished {
+		progress.finished = true
+		progress.emit(ProgressComplete)
+	}
+}
+
+// emit writes the current state of the transfer with its throughput and remaining time
+func (progress *eventProgress) emit(name string) {
+	progress.emitted = time.Now()
+	event := progress.event
+	event.Event = name
+	if elapsed := progress.emitted.Sub(progress.started).Seconds(); elapsed > 0 {
+		event.BytesPerSecond = int64(float64(event.Bytes) / elapsed)
+	}
+	if event.BytesPerSecond > 0 && event.TotalBytes > event.Bytes {
+		event.ETASeconds = (event.TotalBytes - event.Bytes) / event.BytesPerSecond
+	}
+	if line, err := json.Marshal(event); err == nil {
+		progress.render.terminal.Info(string(line))
+	}
+}
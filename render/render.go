@@ -40,3 +40,120 @@ type PublicPrivateDirectEPOutput struct {
 	Private map[string]*string `json:",omitempty"`
 	Direct  map[string]*string `json:",omitempty"`
 }
+
+// Transfer actions reported by multi-object commands
+const (
+	ActionUpload   = "upload"
+	ActionDownload = "download"
+	ActionDelete   = "delete"
+	ActionCopy     = "copy"
+	ActionAbort    = "abort"
+)
+
+// TransferItem describes a single object operation planned or performed by a multi-object command
+type TransferItem struct {
+	Action    string
+	Key       string `json:",omitempty"`
+	VersionId string `json:",omitempty"`
+	UploadId  string `json:",omitempty"`
+	Path      string `json:",omitempty"`
+	Size      int64
+	Error     string `json:",omitempty"`
+	Skipped   bool   `json:",omitempty"`
+}
+
+// TransferSummaryOutput type to wrap the result of a multi-object upload or download,
+// or the objects it would process on a dry run
+type TransferSummaryOutput struct {
+	Bucket     string
+	Action     string
+	DryRun     bool `json:",omitempty"`
+	Items      []*TransferItem
+	Succeeded  int
+	Failed     int
+	Skipped    int `json:",omitempty"`
+	TotalBytes int64
+}
+
+// ObjectMoveOutput type to wrap the result of a move
+type ObjectMoveOutput struct {
+	SourceBucket    string
+	SourceKey       string
+	SourceVersionId string `json:",omitempty"`
+	Bucket          string
+	Key             string
+	VersionId       string `json:",omitempty"`
+	ETag            string `json:",omitempty"`
+	Size            int64
+}
+
+// Status of a line of a batch manifest
+const (
+	BatchSucceeded = "succeeded"
+	BatchFailed    = "failed"
+)
+
+// BatchResult describes the outcome of a single line of a batch manifest
+type BatchResult struct {
+	Line      int
+	Operation string `json:",omitempty"`
+	Bucket    string `json:",omitempty"`
+	Key       string `json:",omitempty"`
+	Status    string
+	Error     string `json:",omitempty"`
+}
+
+// BatchOutput type to wrap the result of a batch
+type BatchOutput struct {
+	Manifest  string
+	Results   string
+	Total     int
+	Succeeded int
+	Failed    int
+	Failures  []*BatchResult `json:",omitempty"`
+}
+
+// BenchResult describes the measures of an operation of a benchmark for a part size and a concurrency
+type BenchResult struct {
+	Operation   string
+	PartSize    int64
+	Concurrency int
+	// Throughput in bytes per second
+	Throughput int64
+	// Latencies of the objects in seconds
+	LatencyP50 float64
+	LatencyP90 float64
+	LatencyP99 float64
+}
+
+// BenchOutput type to wrap the result of a benchmark
+type BenchOutput struct {
+	Bucket              string
+	Size                int64
+	Objects             int
+	Results             []*BenchResult
+	RecommendedUpload   *BenchResult `json:",omitempty"`
+	RecommendedDownload *BenchResult `json:",omitempty"`
+}
+
+// BucketEmptyOutput type to wrap the result of a forced bucket delete, with the versions
+// and the uploads which could not be removed
+type BucketEmptyOutput struct {
+	Bucket          string
+	DeletedVersions int
+	DeletedMarkers  int
+	AbortedUploads  int
+	Failed          []*TransferItem
+	BucketDeleted   bool
+}
+
+// SyncOutput type to wrap the plan or the result of a sync
+type SyncOutput struct {
+	Bucket         string
+	Prefix         string `json:",omitempty"`
+	LocalDirectory string
+	Direction      string
+	DryRun         bool
+	Operations     []*TransferItem
+	Failed         int
+}
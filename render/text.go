@@ -3,6 +3,7 @@ package render
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/IBM/ibm-cos-sdk-go/service/s3/s3manager"
 
@@ -139,6 +140,18 @@ func (txtRender *TextRender) Display(
 		return txtRender.printGetBucketLifecycleConfiguration(input, castedOutput)
 	case *RegionEndpointsOutput:
 		return txtRender.printEndpoints(castedOutput)
+	case *TransferSummaryOutput:
+		return txtRender.printTransferSummary(input, castedOutput)
+	case *SyncOutput:
+		return txtRender.printSync(input, castedOutput)
+	case *ObjectMoveOutput:
+		return txtRender.printObjectMove(input, castedOutput)
+	case *BatchOutput:
+		return txtRender.printBatch(input, castedOutput)
+	case *BenchOutput:
+		return txtRender.printBench(input, castedOutput)
+	case *BucketEmptyOutput:
+		return txtRender.printBucketEmpty(input, castedOutput)
 	default:
 		return
 	}
@@ -1167,3 +1180,251 @@ func printMapOutput(t *TextRender, m map[string]*string) {
 		}
 	}
 }
+
+func (txtRender *TextRender) printSync(_ interface{}, output *SyncOutput) (err error) {
+	if len(output.Operations) == 0 {
+		txtRender.Say(T("Local directory '{{.Directory}}' and bucket '{{.Bucket}}' are already in sync.",
+			map[string]interface{}{
+				"Directory": terminal.EntityNameColor(output.LocalDirectory),
+				bucket:      terminal.EntityNameColor(output.Bucket),
+			}))
+		return
+	}
+
+	table := txtRender.Table([]string{
+		T("Action"),
+		T("Key"),
+		T("Local Path"),
+		T("Size"),
+	})
+	for _, operation := range output.Operations {
+		table.Add(
+			operation.Action,
+			operation.Key,
+			operation.Path,
+			FormatFileSize(operation.Size),
+		)
+	}
+	table.Print()
+	txtRender.Say("")
+
+	if output.DryRun {
+		txtRender.Say(T("Dry run: {{.Count}} operation(s) would be performed.",
+			map[string]interface{}{
+				"Count": len(output.Operations),
+			}))
+		return
+	}
+
+	txtRender.Say(T("{{.Succeeded}} operation(s) completed, {{.Failed}} failed.",
+		map[string]interface{}{
+			"Succeeded": len(output.Operations) - output.Failed,
+			"Failed":    output.Failed,
+		}))
+	txtRender.printTransferFailures(output.Operations)
+	return
+}
+
+func (txtRender *TextRender) printTransferSummary(_ interface{}, output *TransferSummaryOutput) (err error) {
+	// a recursive delete keeps only the keys that failed
+	if output.Succeeded > 0 && len(output.Items) > output.Failed+output.Skipped {
+		headers := []string{T("Key"), T("Local Path"), T("Size")}
+		switch output.Action {
+		case ActionCopy:
+			headers = []string{T("Source Key"), T("Key"), T("Size")}
+		case ActionDelete:
+			headers = []string{T("Key"), T("Size")}
+		case ActionAbort:
+			headers = []string{T("Key"), T("Upload ID"), T("Size")}
+		}
+		table := txtRender.Table(headers)
+		for _, item := range output.Items {
+			if item.Error != "" || item.Skipped {
+				continue
+			}
+			switch output.Action {
+			case ActionDelete:
+				table.Add(item.Key, FormatFileSize(item.Size))
+			case ActionAbort:
+				table.Add(item.Key, item.UploadId, FormatFileSize(item.Size))
+			default:
+				table.Add(item.Key, item.Path, FormatFileSize(item.Size))
+			}
+		}
+		table.Print()
+		txtRender.Say("")
+	}
+
+	params := map[string]interface{}{
+		"Succeeded": output.Succeeded,
+		"Skipped":   output.Skipped,
+		"Total":     output.Succeeded + output.Failed + output.Skipped,
+		"Size":      FormatFileSize(output.TotalBytes),
+		bucket:      terminal.EntityNameColor(output.Bucket),
+	}
+	if output.DryRun {
+		if output.Action == ActionAbort {
+			txtRender.Say(T("Dry run: {{.Total}} multipart upload(s) matched in bucket '{{.Bucket}}' ({{.Size}}).", params))
+			return
+		}
+		txtRender.Say(T("Dry run: {{.Total}} object(s) matched in bucket '{{.Bucket}}' ({{.Size}}).", params))
+		return
+	}
+	switch output.Action {
+	case ActionAbort:
+		txtRender.Say(T("Aborted {{.Succeeded}} of {{.Total}} multipart upload(s) in bucket '{{.Bucket}}', reclaiming {{.Size}}.", params))
+	case ActionDelete:
+		txtRender.Say(T("Deleted {{.Succeeded}} of {{.Total}} object(s) from bucket '{{.Bucket}}' ({{.Size}}).", params))
+	case ActionDownload:
+		txtRender.Say(T("Downloaded {{.Succeeded}} of {{.Total}} object(s) from bucket '{{.Bucket}}' ({{.Size}}).", params))
+	case ActionCopy:
+		txtRender.Say(T("Copied {{.Succeeded}} of {{.Total}} object(s) to bucket '{{.Bucket}}' ({{.Size}}).", params))
+	default:
+		txtRender.Say(T("Uploaded {{.Succeeded}} of {{.Total}} file(s) to bucket '{{.Bucket}}' ({{.Size}}).", params))
+	}
+	if output.Skipped > 0 {
+		txtRender.Say(T("Skipped {{.Skipped}} file(s) already in bucket '{{.Bucket}}'.", params))
+	}
+	txtRender.printTransferFailures(output.Items)
+	return
+}
+
+func (txtRender *TextRender) printTransferFailures(items []*TransferItem) {
+	var table terminal.Table
+	for _, item := range items {
+		if item.Error == "" {
+			continue
+		}
+		if table == nil {
+			txtRender.Say("")
+			table = txtRender.Table([]string{
+				T("Failed"),
+				T("Error"),
+			})
+		}
+		name := item.Key
+		if name == "" {
+			name = item.Path
+		}
+		table.Add(name, item.Error)
+	}
+	if table != nil {
+		table.Print()
+	}
+}
+
+func (txtRender *TextRender) printObjectMove(_ interface{}, output *ObjectMoveOutput) (err error) {
+	txtRender.Say(T("Successfully moved '{{.SourceKey}}' from bucket '{{.SourceBucket}}' to '{{.Key}}' in bucket '{{.Bucket}}'.",
+		map[string]interface{}{
+			"SourceKey":    terminal.EntityNameColor(output.SourceKey),
+			"SourceBucket": terminal.EntityNameColor(output.SourceBucket),
+			"Key":          terminal.EntityNameColor(output.Key),
+			bucket:         terminal.EntityNameColor(output.Bucket),
+		}))
+	if output.VersionId != "" {
+		txtRender.Say(T("Version ID: ") + terminal.EntityNameColor(output.VersionId))
+	}
+	txtRender.Say(FormatFileSize(output.Size) + T(" moved."))
+	return
+}
+
+func (txtRender *TextRender) printBatch(_ interface{}, output *BatchOutput) (err error) {
+	txtRender.Say(T("Ran {{.Succeeded}} of {{.Total}} operation(s) from '{{.Manifest}}', results in '{{.Results}}'.",
+		map[string]interface{}{
+			"Succeeded": output.Succeeded,
+			"Total":     output.Total,
+			"Manifest":  terminal.EntityNameColor(output.Manifest),
+			"Results":   terminal.EntityNameColor(output.Results),
+		}))
+	if len(output.Failures) > 0 {
+		txtRender.Say("")
+		table := txtRender.Table([]string{
+			T("Line"),
+			T("Operation"),
+			T("Error"),
+		})
+		for _, failure := range output.Failures {
+			table.Add(strconv.Itoa(failure.Line), failure.Operation, failure.Error)
+		}
+		table.Print()
+	}
+	return
+}
+
+func (txtRender *TextRender) printBench(_ interface{}, output *BenchOutput) (err error) {
+	txtRender.Say(T("Transferred {{.Objects}} object(s) of {{.Size}} for every setting in bucket '{{.Bucket}}'.",
+		map[string]interface{}{
+			"Objects": output.Objects,
+			"Size":    FormatFileSize(output.Size),
+			bucket:    terminal.EntityNameColor(output.Bucket),
+		}))
+	txtRender.Say("")
+	table := txtRender.Table([]string{
+		T("Operation"),
+		T("Part Size"),
+		T("Concurrency"),
+		T("Throughput"),
+		T("Latency p50"),
+		T("Latency p90"),
+		T("Latency p99"),
+	})
+	latency := func(seconds float64) string {
+		return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+	}
+	for _, result := range output.Results {
+		table.Add(result.Operation, FormatFileSize(result.PartSize), strconv.Itoa(result.Concurrency),
+			FormatFileSize(result.Throughput)+"/s", latency(result.LatencyP50), latency(result.LatencyP90),
+			latency(result.LatencyP99))
+	}
+	table.Print()
+	for _, recommended := range []*BenchResult{output.RecommendedUpload, output.RecommendedDownload} {
+		if recommended == nil {
+			continue
+		}
+		txtRender.Say("")
+		txtRender.Say(T("Recommended for {{.Operation}}: --part-size {{.PartSize}} --concurrency {{.Concurrency}} ({{.Throughput}}/s)",
+			map[string]interface{}{
+				"Operation":   recommended.Operation,
+				"PartSize":    recommended.PartSize,
+				"Concurrency": recommended.Concurrency,
+				"Throughput":  FormatFileSize(recommended.Throughput),
+			}))
+	}
+	return
+}
+
+func (txtRender *TextRender) printBucketEmpty(_ interface{}, output *BucketEmptyOutput) (err error) {
+	params := map[string]interface{}{
+		"Versions": output.DeletedVersions,
+		"Markers":  output.DeletedMarkers,
+		"Uploads":  output.AbortedUploads,
+		"Failed":   len(output.Failed),
+		bucket:     terminal.EntityNameColor(output.Bucket),
+	}
+	txtRender.Say(T("Deleted {{.Versions}} object version(s) and {{.Markers}} delete marker(s), aborted {{.Uploads}} multipart upload(s) in bucket '{{.Bucket}}'.",
+		params))
+	if len(output.Failed) > 0 {
+		txtRender.Say("")
+		table := txtRender.Table([]string{
+			T("Key"),
+			T("Version ID / Upload ID"),
+			T("Error"),
+		})
+		for _, item := range output.Failed {
+			id := item.VersionId
+			if item.Action == ActionAbort {
+				id = item.UploadId
+			}
+			table.Add(item.Key, id, item.Error)
+		}
+		table.Print()
+		txtRender.Say("")
+		txtRender.Say(T("{{.Failed}} item(s) could not be removed, the bucket '{{.Bucket}}' is not deleted.", params))
+		return
+	}
+	if output.BucketDeleted {
+		txtRender.Say(T("Successfully deleted bucket '{{.Bucket}}'. The bucket name '{{.Bucket}}' will be available for reuse after 15 minutes.",
+			params))
+	}
+	return
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/bluemix/terminal"
@@ -158,14 +159,26 @@ func (c *CosContext) GetServiceEndpoint() (string, error) {
 // FileOperations interface to support
 // ReadSeekerCloserOpen,
 // WriteCloserOpen,
-// GetFileInfo, and
-// Remove
+// WriteCloserOpenExisting,
+// GetFileInfo,
+// Remove,
+// Walk,
+// MkdirAll,
+// Chmod,
+// Stdin, and
+// Stdout
 type FileOperations interface {
 	ReadSeekerCloserOpen(location string) (ReadSeekerCloser, error)
 	WriteCloserOpen(location string) (WriteCloser, error)
+	WriteCloserOpenExisting(location string) (WriteCloser, error)
 	GetFileInfo(location string) (os.FileInfo, error)
 	Remove(location string) error
 	GetTotalBytes(location string) (int64, error)
+	Walk(root string, walkFn filepath.WalkFunc) error
+	MkdirAll(location string) error
+	Chmod(location string, mode os.FileMode) error
+	Stdin() io.Reader
+	Stdout() io.Writer
 }
 
 // ReadSeekerCloser a FileOperations interface
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"github.com/IBM/ibm-cos-sdk-go/aws/request"
+)
+
+// Identity Encoding Handler
+var IdentityEncodingHandler = request.NamedHandler{
+	Name: "plugin.IdentityEncodingHandler",
+	Fn:   RequestIdentityEncoding,
+}
+
+// RequestIdentityEncoding - Send the object downloads asking for
+// the content as it is stored, the HTTP transport would otherwise
+// transparently decompress the objects stored with a gzip Content-Encoding.
+// Those objects are only decompressed when asked to with --decompress.
+// Set once signed, the header is left out of the signature
+func RequestIdentityEncoding(r *request.Request) {
+	if r.Operation.Name != "GetObject" {
+		return
+	}
+	if r.HTTPRequest.Header.Get("Accept-Encoding") == "" {
+		r.HTTPRequest.Header.Set("Accept-Encoding", "identity")
+	}
+}
@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/plugin"
+	"github.com/IBM/ibm-cos-sdk-go/aws"
+	"github.com/IBM/ibm-cos-sdk-go/aws/awserr"
+	"github.com/IBM/ibm-cos-sdk-go/aws/client"
+	"github.com/IBM/ibm-cos-sdk-go/aws/request"
+	"github.com/IBM/ibmcloud-cos-cli/config"
+)
+
+// Retryer retries the failed requests with an exponential backoff as the SDK default retryer does,
+// and also the responses with one of the retryable status codes
+type Retryer struct {
+	client.DefaultRetryer
+	// StatusCodes are the HTTP status codes retried in addition to the throttling and server errors
+	StatusCodes []int
+	// Logger reports every retry when set
+	Logger aws.Logger
+}
+
+// NewRetryer returns the retryer of the settings stored in the plugin configuration,
+// the SDK defaults are used for the settings not stored
+func NewRetryer(pc plugin.PluginConfig) (*Retryer, error) {
+	retryer := &Retryer{DefaultRetryer: client.DefaultRetryer{NumMaxRetries: client.DefaultRetryerMaxNumRetries}}
+	settings := []struct {
+		key   string
+		apply func(string) error
+	}{
+		{config.MaxRetries, retryer.SetMaxRetries},
+		{config.RetryBaseDelay, retryer.SetBaseDelay},
+		{config.RetryMaxDelay, retryer.SetMaxDelay},
+		{config.RetryStatusCodes, retryer.SetStatusCodes},
+	}
+	for _, setting := range settings {
+		value, err := pc.GetStringWithDefault(setting.key, "")
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			continue
+		}
+		if err = setting.apply(value); err != nil {
+			return nil, fmt.Errorf("%s: %v", setting.key, err)
+		}
+	}
+	return retryer, nil
+}
+
+// SetMaxRetries sets the maximum number of retries of a request, zero disables the retries
+func (r *Retryer) SetMaxRetries(value string) error {
+	retries, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || retries < 0 {
+		return errors.New("invalid number of retries: " + value)
+	}
+	r.NumMaxRetries = retries
+	return nil
+}
+
+// SetBaseDelay sets the delay before the first retry, doubled at every retry
+func (r *Retryer) SetBaseDelay(value string) error {
+	delay, err := ParseRetryDelay(value)
+	if err != nil {
+		return err
+	}
+	r.MinRetryDelay, r.MinThrottleDelay = delay, delay
+	return nil
+}
+
+// SetMaxDelay sets the maximum delay between two retries
+func (r *Retryer) SetMaxDelay(value string) error {
+	delay, err := ParseRetryDelay(value)
+	if err != nil {
+		return err
+	}
+	r.MaxRetryDelay, r.MaxThrottleDelay = delay, delay
+	return nil
+}
+
+// SetStatusCodes sets the HTTP status codes retried in addition to the ones of the SDK
+func (r *Retryer) SetStatusCodes(value string) error {
+	codes, err := ParseRetryStatusCodes(value)
+	if err != nil {
+		return err
+	}
+	r.StatusCodes = codes
+	return nil
+}
+
+// ParseRetryDelay parses a positive duration like 100ms or 20s
+func ParseRetryDelay(value string) (time.Duration, error) {
+	delay, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || delay <= 0 {
+		return 0, errors.New("invalid delay: " + value)
+	}
+	return delay, nil
+}
+
+// ParseRetryStatusCodes parses a comma separated list of HTTP status codes like 500,503
+func ParseRetryStatusCodes(value string) ([]int, error) {
+	codes := make([]int, 0)
+	for _, field := range strings.Split(value, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || code < 100 || code > 599 {
+			return nil, errors.New("invalid status code: " + field)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// ShouldRetry retries the responses with one of the status codes, and the errors retried by the SDK
+func (r *Retryer) ShouldRetry(req *request.Request) bool {
+	if r.NumMaxRetries > 0 && req.HTTPResponse != nil {
+		for _, code := range r.StatusCodes {
+			if req.HTTPResponse.StatusCode == code {
+				return true
+			}
+		}
+	}
+	return r.DefaultRetryer.ShouldRetry(req)
+}
+
+// RetryRules returns the delay before the next retry, and reports the retry to the logger
+func (r *Retryer) RetryRules(req *request.Request) time.Duration {
+	delay := r.DefaultRetryer.RetryRules(req)
+	if r.Logger != nil {
+		r.Logger.Log(fmt.Sprintf("Retry %d/%d of %s in %s after %s",
+			req.RetryCount+1, r.NumMaxRetries, req.Operation.Name, delay, retryReason(req)))
+	}
+	return delay
+}
+
+// retryReason describes the failure of a request, its status and error code
+func retryReason(req *request.Request) string {
+	reason := ""
+	if req.HTTPResponse != nil {
+		reason = strconv.Itoa(req.HTTPResponse.StatusCode) + " "
+	}
+	if failure, ok := req.Error.(awserr.Error); ok {
+		reason += failure.Code()
+		if failure.OrigErr() != nil {
+			reason += ": " + failure.OrigErr().Error()
+		}
+	} else if req.Error != nil {
+		reason += req.Error.Error()
+	}
+	return strings.TrimSpace(reason)
+}
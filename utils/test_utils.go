@@ -2,6 +2,8 @@ package utils
 
 import (
 	"bytes"
+	"os"
+	"time"
 
 	"github.com/IBM/ibm-cos-sdk-go/aws"
 )
@@ -56,3 +58,31 @@ func WriteToString(output *string, closeStatus *bool) WriteCloser {
 		closeStatus: closeStatus,
 	}
 }
+
+type fakeFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+func (fi *fakeFileInfo) Name() string { return fi.name }
+func (fi *fakeFileInfo) Size() int64  { return fi.size }
+func (fi *fakeFileInfo) Mode() os.FileMode {
+	if fi.isDir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+func (fi *fakeFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *fakeFileInfo) IsDir() bool        { return fi.isDir }
+func (fi *fakeFileInfo) Sys() interface{}   { return nil }
+
+func FakeFileInfo(name string, size int64, modTime time.Time, isDir bool) os.FileInfo {
+	return &fakeFileInfo{
+		name:    name,
+		size:    size,
+		modTime: modTime,
+		isDir:   isDir,
+	}
+}
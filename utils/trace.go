@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/IBM-Cloud/ibm-cloud-cli-sdk/bluemix/trace"
+)
+
+// RedactedValue replaces the secrets written to the trace
+const RedactedValue = "[PRIVATE DATA HIDDEN]"
+
+// secretHeaders matches the request headers carrying a customer-provided key
+var secretHeaders = regexp.MustCompile(`(?im)^(x-amz-(?:copy-source-)?server-side-encryption-customer-key):[^\r\n]*`)
+
+// RedactSecrets hides the customer-provided keys in a dump of requests
+func RedactSecrets(text string) string {
+	return secretHeaders.ReplaceAllString(text, "$1: "+RedactedValue)
+}
+
+// redactingLogger is a trace printer hiding the customer-provided keys
+type redactingLogger struct {
+	trace.Printer
+}
+
+// NewRedactingLogger wraps a trace printer so the customer-provided keys sent are not written to the trace
+func NewRedactingLogger(printer trace.Printer) trace.Printer {
+	return &redactingLogger{Printer: printer}
+}
+
+func (logger *redactingLogger) Print(v ...interface{}) {
+	logger.Printer.Print(RedactSecrets(fmt.Sprint(v...)))
+}
+
+func (logger *redactingLogger) Printf(format string, v ...interface{}) {
+	logger.Printer.Print(RedactSecrets(fmt.Sprintf(format, v...)))
+}
+
+func (logger *redactingLogger) Println(v ...interface{}) {
+	logger.Printer.Print(RedactSecrets(fmt.Sprintln(v...)))
+}